@@ -1,22 +1,32 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/geropl/git-mcp-go/pkg"
 	"github.com/geropl/git-mcp-go/pkg/gitops"
 	"github.com/geropl/git-mcp-go/pkg/gitops/gogit"
+	"github.com/geropl/git-mcp-go/pkg/gitops/memory"
 	"github.com/geropl/git-mcp-go/pkg/gitops/shell"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repoPaths   []string
-	verbose     bool
-	mode        string
-	writeAccess bool
+	repoPaths        []string
+	verbose          bool
+	mode             string
+	writeAccess      bool
+	cloneDirs        []string
+	cloneURLPatterns []string
+	cloneAllowLocal  bool
+	timeout          time.Duration
+	toolTimeouts     []string
+	commitAuthor     string
 )
 
 // serveCmd represents the serve command
@@ -42,6 +52,11 @@ You can specify multiple repositories using the -r/--repository flag (can be rep
 				fmt.Println("Using shell implementation")
 			}
 			gitOps = shell.NewShellGitOperations()
+		case "memory":
+			if verbose {
+				fmt.Println("Using in-memory implementation")
+			}
+			gitOps = memory.NewMemoryGitOperations()
 		default:
 			if verbose {
 				fmt.Println("Using shell implementation")
@@ -70,8 +85,37 @@ You can specify multiple repositories using the -r/--repository flag (can be rep
 			}
 		}
 
+		// In-memory repositories start out empty, nothing is read from disk
+		if memoryOps, ok := gitOps.(*memory.MemoryGitOperations); ok {
+			for _, path := range allRepoPaths {
+				absPath, err := filepath.Abs(path)
+				if err == nil {
+					_, err = memoryOps.InitRepo(context.Background(), absPath)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to create repository %s in memory: %v\n", path, err)
+					os.Exit(1)
+				}
+			}
+		}
+
 		// Create and configure the Git MCP server
 		gitServer := pkg.NewGitServer(allRepoPaths, gitOps, writeAccess)
+		gitServer.SetCloneRestrictions(cloneDirs, cloneURLPatterns, cloneAllowLocal)
+
+		timeouts, err := parseToolTimeouts(toolTimeouts)
+		if err == nil {
+			err = gitServer.SetTimeouts(timeout, timeouts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid timeout: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := gitServer.SetCommitAuthor(commitAuthor); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid commit author: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Register all Git tools
 		gitServer.RegisterTools()
@@ -93,7 +137,36 @@ func init() {
 	// Add flags to the server command
 	serveCmd.Flags().StringSliceVarP(&repoPaths, "repository", "r", []string{}, 
 		"Git repository paths (can be specified multiple times, comma-separated, or as positional arguments)")
-	serveCmd.Flags().StringVar(&mode, "mode", "shell", "Git operation mode: 'shell' or 'go-git'")
+	serveCmd.Flags().StringVar(&mode, "mode", "shell", "Git operation mode: 'shell', 'go-git' or 'memory'")
 	serveCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 	serveCmd.Flags().BoolVar(&writeAccess, "write-access", false, "Enable write access for remote operations (push)")
+	serveCmd.Flags().StringSliceVar(&cloneDirs, "clone-dir", []string{},
+		"Directories that repositories may be cloned into (enables git_clone; can be specified multiple times or comma-separated)")
+	serveCmd.Flags().StringSliceVar(&cloneURLPatterns, "clone-url-pattern", []string{},
+		"URL patterns that repositories may be cloned from, '*' matches anything (default: any remote URL)")
+	serveCmd.Flags().BoolVar(&cloneAllowLocal, "clone-allow-local", false,
+		"Allow git_clone to clone from local paths and file:// URLs, which gives access to any repository on the machine")
+	serveCmd.Flags().DurationVar(&timeout, "timeout", 0,
+		"Time limit of a tool call, after which the git operation is canceled, e.g. 5m (default: no limit)")
+	serveCmd.Flags().StringSliceVar(&toolTimeouts, "tool-timeout", []string{},
+		"Time limit of a specific tool as tool=duration, overriding --timeout (e.g. git_clone=30m; can be specified multiple times or comma-separated)")
+	serveCmd.Flags().StringVar(&commitAuthor, "commit-author", "",
+		"Identity of commits made by the server as 'Name <email>' (default: user.name and user.email from git config)")
+}
+
+// parseToolTimeouts parses the tool=duration values of the --tool-timeout flag
+func parseToolTimeouts(values []string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, value := range values {
+		name, duration, found := strings.Cut(value, "=")
+		if !found {
+			return nil, fmt.Errorf("expected tool=duration, got %q", value)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %s: %w", name, err)
+		}
+		timeouts[strings.TrimSpace(name)] = d
+	}
+	return timeouts, nil
 }
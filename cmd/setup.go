@@ -28,7 +28,7 @@ func init() {
 	setupCmd.Flags().StringVar(&mode, "mode", "shell", "Git operation mode: 'shell' or 'go-git'")
 	setupCmd.Flags().BoolVar(&writeAccess, "write-access", false, "Enable write access for remote operations (push)")
 	setupCmd.Flags().StringVar(&tool, "tool", "cline", "The AI assistant tool(s) to set up for (comma-separated, e.g., cline,roo-code)")
-	setupCmd.Flags().StringVar(&autoApprove, "auto-approve", "", "Comma-separated list of tools to auto-approve, or 'allow-read-only' to auto-approve all read-only tools, or 'allow-local-only' to auto-approve all local-only tools")
+	setupCmd.Flags().StringVar(&autoApprove, "auto-approve", "", "Comma-separated list of tools to auto-approve, or 'allow-read-only' to auto-approve all read-only tools, or 'allow-local-only' to auto-approve all local-only tools, or 'allow-network-read' to additionally auto-approve fetching from remotes")
 }
 
 // setupCmd represents the setup command
@@ -231,6 +231,14 @@ func setupTool(toolName string, binaryPath string, repoPaths []string, writeAcce
 			for k := range pkg.GetLocalOnlyToolNames() {
 				autoApproveTools = append(autoApproveTools, k)
 			}
+		} else if autoApprove == "allow-network-read" {
+			// Get the list of local-only tools plus the tools that fetch from remotes
+			for k := range pkg.GetLocalOnlyToolNames() {
+				autoApproveTools = append(autoApproveTools, k)
+			}
+			for k := range pkg.GetNetworkReadToolNames() {
+				autoApproveTools = append(autoApproveTools, k)
+			}
 		} else {
 			// Split comma-separated list
 			for _, tool := range strings.Split(autoApprove, ",") {
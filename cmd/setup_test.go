@@ -61,7 +61,7 @@ func TestSetupCommand(t *testing.T) {
 							"mcpServers": {
 								"git": {
 									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
-									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show"],
+									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show", "git_stash_list", "git_stash_show", "git_tag_list", "git_branch_list", "git_blame", "git_read_file", "git_ls_tree", "git_ls_files", "git_grep"],
 									"disabled": false
 								}
 							}
@@ -86,7 +86,7 @@ func TestSetupCommand(t *testing.T) {
 							"mcpServers": {
 								"git": {
 									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
-									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show"],
+									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show", "git_stash_list", "git_stash_show", "git_tag_list", "git_branch_list", "git_blame", "git_read_file", "git_ls_tree", "git_ls_files", "git_grep"],
 									"disabled": false
 								}
 							}
@@ -111,7 +111,7 @@ func TestSetupCommand(t *testing.T) {
 							"mcpServers": {
 								"git": {
 									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
-									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show"],
+									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show", "git_stash_list", "git_stash_show", "git_tag_list", "git_branch_list", "git_blame", "git_read_file", "git_ls_tree", "git_ls_files", "git_grep"],
 									"disabled": false
 								}
 							}
@@ -124,7 +124,32 @@ func TestSetupCommand(t *testing.T) {
 							"mcpServers": {
 								"git": {
 									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
-									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show"],
+									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show", "git_stash_list", "git_stash_show", "git_tag_list", "git_branch_list", "git_blame", "git_read_file", "git_ls_tree", "git_ls_files", "git_grep"],
+									"disabled": false
+								}
+							}
+						}`,
+					},
+				},
+				exitCode: 0,
+			},
+		},
+		{
+			name:        "Allow Network Read",
+			toolParam:   "cline",
+			repoPath:    "/mock/repo",
+			writeAccess: true,
+			autoApprove: "allow-network-read",
+			expect: expectations{
+				files: map[string]fileExpectation{
+					"cline": {
+						path:      "home/.vscode-server/data/User/globalStorage/saoudrizwan.claude-dev/settings/cline_mcp_settings.json",
+						mustExist: true,
+						content: `{
+							"mcpServers": {
+								"git": {
+									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
+									"autoApprove": ["git_add", "git_am", "git_apply", "git_blame", "git_branch_delete", "git_branch_list", "git_branch_rename", "git_branch_set_upstream", "git_checkout", "git_cherry_pick", "git_commit", "git_create_branch", "git_diff", "git_diff_staged", "git_diff_unstaged", "git_fetch", "git_grep", "git_init", "git_log", "git_ls_files", "git_ls_tree", "git_merge", "git_merge_abort", "git_pull", "git_read_file", "git_rebase", "git_rebase_abort", "git_rebase_continue", "git_rebase_skip", "git_reset", "git_restore", "git_revert", "git_show", "git_stage_hunks", "git_stash_apply", "git_stash_drop", "git_stash_list", "git_stash_pop", "git_stash_push", "git_stash_show", "git_status", "git_tag_create", "git_tag_delete", "git_tag_list"],
 									"disabled": false
 								}
 							}
@@ -149,7 +174,7 @@ func TestSetupCommand(t *testing.T) {
 							"mcpServers": {
 								"git": {
 									"args": ["serve", "--repository=/mock/repo", "--write-access=true"],
-									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show"],
+									"autoApprove": ["git_status", "git_diff_unstaged", "git_diff_staged", "git_diff", "git_log", "git_show", "git_stash_list", "git_stash_show", "git_tag_list", "git_branch_list", "git_blame", "git_read_file", "git_ls_tree", "git_ls_files", "git_grep"],
 									"disabled": false
 								}
 							}
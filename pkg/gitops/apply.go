@@ -0,0 +1,248 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PatchedFile is a file changed by a patch
+type PatchedFile struct {
+	Path    string `json:"path"`               // path after the change, or before it for deleted files
+	OldPath string `json:"old_path,omitempty"` // path before a rename or copy
+}
+
+// ParsePatchFiles returns the files changed by a unified diff, with or without
+// the headers of git, or by the patches in a mailbox. Like `git apply`, the
+// first directory of the paths of patches without git headers is stripped.
+func ParsePatchFiles(patch string) ([]PatchedFile, error) {
+	var files []PatchedFile
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	inGitHeader := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			oldPath, newPath, err := parseDiffGitLine(line)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, PatchedFile{Path: newPath, OldPath: oldPath})
+			inGitHeader = true
+		case inGitHeader && (strings.HasPrefix(line, "rename from ") || strings.HasPrefix(line, "copy from ")):
+			_, from, _ := strings.Cut(line, " from ")
+			files[len(files)-1].OldPath = unquoteDiffPath(from)
+		case inGitHeader && (strings.HasPrefix(line, "rename to ") || strings.HasPrefix(line, "copy to ")):
+			_, to, _ := strings.Cut(line, " to ")
+			files[len(files)-1].Path = unquoteDiffPath(to)
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			// The paths of git patches are taken from the "diff --git" line
+			if !inGitHeader {
+				oldPath, newPath := patchHeaderPath(line[4:]), patchHeaderPath(lines[i+1][4:])
+				if newPath == "" {
+					newPath = oldPath
+				}
+				files = append(files, PatchedFile{Path: newPath, OldPath: oldPath})
+			}
+			inGitHeader = false
+			i++
+		case strings.HasPrefix(line, "@@ "):
+			inGitHeader = false
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("unexpected hunk header: %q", line)
+			}
+			// Skip the lines of the hunk, which may look like headers
+			_, oldLines := parseHunkRange(match[1], match[2])
+			_, newLines := parseHunkRange(match[3], match[4])
+			for (oldLines > 0 || newLines > 0) && i+1 < len(lines) {
+				i++
+				switch {
+				case strings.HasPrefix(lines[i], "-"):
+					oldLines--
+				case strings.HasPrefix(lines[i], "+"):
+					newLines--
+				case strings.HasPrefix(lines[i], "\\"):
+				default:
+					oldLines--
+					newLines--
+				}
+			}
+		}
+	}
+
+	for i := range files {
+		// The old path is only reported where it differs from the new one
+		if files[i].OldPath == files[i].Path {
+			files[i].OldPath = ""
+		}
+	}
+	return files, nil
+}
+
+// patchHeaderPath returns the path of a "---" or "+++" line without git headers,
+// empty for /dev/null
+func patchHeaderPath(name string) string {
+	name, _, _ = strings.Cut(name, "\t")
+	name = unquoteDiffPath(strings.TrimSpace(name))
+	if name == "/dev/null" {
+		return ""
+	}
+	if _, stripped, found := strings.Cut(name, "/"); found {
+		return stripped
+	}
+	return name
+}
+
+// ApplyOptions controls how RunApply applies a patch
+type ApplyOptions struct {
+	Check    bool // only check whether the patch applies, like `git apply --check`
+	ThreeWay bool // fall back to a three-way merge, which may leave conflicts
+	Cached   bool // apply the patch to the index only
+}
+
+// ApplyArgs returns the arguments of `git apply` reading the patch from stdin
+func ApplyArgs(opts ApplyOptions) []string {
+	args := []string{"apply", "--verbose"}
+	if opts.Check {
+		args = append(args, "--check")
+	}
+	if opts.ThreeWay {
+		args = append(args, "--3way")
+	}
+	if opts.Cached {
+		args = append(args, "--cached")
+	}
+	return args
+}
+
+// ApplyStatus tells whether the changes to a file apply
+type ApplyStatus string
+
+const (
+	// ApplyClean means the changes to the file apply cleanly
+	ApplyClean ApplyStatus = "clean"
+	// ApplyConflict means a three-way merge of the changes left conflicts
+	ApplyConflict ApplyStatus = "conflict"
+	// ApplyRejected means the changes to the file don't apply
+	ApplyRejected ApplyStatus = "rejected"
+)
+
+// ApplyFile is the result of applying the changes to a file
+type ApplyFile struct {
+	PatchedFile
+	Status ApplyStatus `json:"status"`
+	Error  string      `json:"error,omitempty"` // why the changes were rejected
+}
+
+// ApplyResult is the result of applying a patch. A patch is only applied if the
+// changes to all files apply, possibly with conflicts.
+type ApplyResult struct {
+	Applied bool        `json:"applied"` // whether the patch was applied, never when checking
+	Files   []ApplyFile `json:"files"`
+	Output  string      `json:"output"` // output of git apply
+}
+
+// RunApply applies a patch with `git apply` and reports the result for every
+// file. An error is only returned if the patch couldn't be read or git failed
+// for a reason other than changes that don't apply.
+func RunApply(ctx context.Context, repoPath string, patch string, opts ApplyOptions) (*ApplyResult, error) {
+	files, err := ParsePatchFiles(patch)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no changes found in patch")
+	}
+
+	output, cmdErr := RunGitCommandWithInput(ctx, repoPath, patch, ApplyArgs(opts)...)
+	if cmdErr != nil {
+		output = cmdErr.Error()
+	}
+
+	result := &ApplyResult{Files: make([]ApplyFile, len(files)), Output: output}
+	for i, file := range files {
+		result.Files[i] = ApplyFile{PatchedFile: file, Status: ApplyClean}
+	}
+	rejected, conflicts := 0, 0
+	for _, line := range strings.Split(output, "\n") {
+		if name, found := strings.CutPrefix(line, "Applied patch to '"); found && strings.HasSuffix(name, "' with conflicts.") {
+			name = strings.TrimSuffix(name, "' with conflicts.")
+			for i := range result.Files {
+				if file := &result.Files[i]; file.Path == name || file.OldPath == name {
+					file.Status = ApplyConflict
+					conflicts++
+				}
+			}
+			continue
+		}
+
+		// Errors about a file start with its name, the last one tells why it was rejected
+		message, found := strings.CutPrefix(line, "error: ")
+		if !found {
+			continue
+		}
+		for i := range result.Files {
+			file := &result.Files[i]
+			for _, name := range []string{file.Path, file.OldPath} {
+				if reason, found := strings.CutPrefix(message, name+": "); name != "" && found {
+					if file.Status != ApplyRejected {
+						rejected++
+					}
+					file.Status = ApplyRejected
+					file.Error = reason
+				}
+			}
+		}
+	}
+
+	if cmdErr != nil && rejected == 0 && conflicts == 0 {
+		return nil, fmt.Errorf("failed to apply patch: %w", cmdErr)
+	}
+	result.Applied = !opts.Check && rejected == 0
+	return result, nil
+}
+
+// AmOptions controls how RunAm applies the patches of a mailbox
+type AmOptions struct {
+	ThreeWay bool // fall back to a three-way merge if a patch doesn't apply
+	// Committer replaces user.name and user.email from git config, like
+	// CommitOptions.Committer
+	Committer *Identity
+}
+
+// AmArgs returns the arguments of `git am` reading a mailbox from stdin
+func AmArgs(opts AmOptions) []string {
+	var args []string
+	if opts.Committer != nil {
+		args = append(args, "-c", "user.name="+opts.Committer.Name, "-c", "user.email="+opts.Committer.Email)
+	}
+	args = append(args, "am")
+	if opts.ThreeWay {
+		args = append(args, "--3way")
+	}
+	return args
+}
+
+// RunAm commits the patches of a mailbox, like the ones created by
+// `git format-patch`, with `git am`. If a patch doesn't apply, the whole series
+// is aborted, so that the repository is left as it was.
+func RunAm(ctx context.Context, repoPath string, mailbox string, opts AmOptions) (string, error) {
+	// Aborting would end a session that was started before
+	state, err := ReadRebaseState(ctx, repoPath)
+	if err != nil {
+		return "", err
+	}
+	if state.InProgress {
+		return "", fmt.Errorf("a rebase or git am is in progress")
+	}
+
+	output, err := RunGitCommandWithInput(ctx, repoPath, mailbox, AmArgs(opts)...)
+	if err != nil {
+		if _, abortErr := RunGitCommand(ctx, repoPath, "am", "--abort"); abortErr != nil {
+			return "", fmt.Errorf("failed to apply patches: %w\nfailed to abort: %v", err, abortErr)
+		}
+		return "", fmt.Errorf("failed to apply patches, nothing was applied: %w", err)
+	}
+	return output, nil
+}
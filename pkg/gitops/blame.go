@@ -0,0 +1,178 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// BlameLine describes the commit that last changed a single line of a file
+type BlameLine struct {
+	LineNumber         int       `json:"line_number"`          // line number in the blamed revision, starting at 1
+	OriginalLineNumber int       `json:"original_line_number"` // line number in the commit that introduced the line, 0 if unknown
+	Commit             string    `json:"commit"`
+	Author             string    `json:"author"`
+	AuthorEmail        string    `json:"author_email"`
+	Date               time.Time `json:"date"`    // author date of the commit
+	Summary            string    `json:"summary"` // subject of the commit
+	Content            string    `json:"content"`
+}
+
+// BlameArgs builds the arguments for `git blame --porcelain`, parsed by ParseBlamePorcelain.
+// A startLine or endLine of 0 leaves that end of the range open. The file is
+// blamed at HEAD if no revision is given, never in the working tree.
+func BlameArgs(filePath string, revision string, startLine int, endLine int) ([]string, error) {
+	if err := ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+
+	args := []string{"blame", "--porcelain"}
+	if startLine > 0 || endLine > 0 {
+		start := startLine
+		if start == 0 {
+			start = 1
+		}
+		lineRange := strconv.Itoa(start) + ","
+		if endLine > 0 {
+			lineRange += strconv.Itoa(endLine)
+		}
+		args = append(args, "-L", lineRange)
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+	return append(args, revision, "--", filePath), nil
+}
+
+// MapUnchangedLines maps the numbers of the lines of newContent that are
+// unchanged since oldContent to their numbers in oldContent, both starting at 1
+func MapUnchangedLines(oldContent string, newContent string) map[int]int {
+	lines := make(map[int]int)
+	oldLine, newLine := 1, 1
+	for _, d := range diff.Do(oldContent, newContent) {
+		count := len(splitLines(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			for i := 0; i < count; i++ {
+				lines[newLine+i] = oldLine + i
+			}
+			oldLine += count
+			newLine += count
+		case diffmatchpatch.DiffDelete:
+			oldLine += count
+		case diffmatchpatch.DiffInsert:
+			newLine += count
+		}
+	}
+	return lines
+}
+
+// ValidateLineRange checks a blame line range. A startLine or endLine of 0 leaves
+// that end of the range open.
+func ValidateLineRange(startLine int, endLine int) error {
+	if startLine < 0 || endLine < 0 {
+		return fmt.Errorf("line numbers must be positive")
+	}
+	if endLine > 0 && startLine > endLine {
+		return fmt.Errorf("start line %d is after end line %d", startLine, endLine)
+	}
+	return nil
+}
+
+// ParseBlamePorcelain parses the output of `git blame --porcelain`
+func ParseBlamePorcelain(output string) ([]BlameLine, error) {
+	var lines []BlameLine
+	// Commit details are only printed the first time a commit appears
+	commits := make(map[string]*BlameLine)
+
+	var current *BlameLine
+	for _, line := range strings.Split(output, "\n") {
+		if current == nil {
+			if line == "" {
+				continue
+			}
+
+			// Header line: <commit> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected blame header line: %q", line)
+			}
+			originalLine, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected blame header line: %q", line)
+			}
+			finalLine, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected blame header line: %q", line)
+			}
+
+			current = &BlameLine{
+				LineNumber:         finalLine,
+				OriginalLineNumber: originalLine,
+				Commit:             fields[0],
+			}
+			if commit, ok := commits[current.Commit]; ok {
+				current.Author = commit.Author
+				current.AuthorEmail = commit.AuthorEmail
+				current.Date = commit.Date
+				current.Summary = commit.Summary
+			}
+			continue
+		}
+
+		// The line content is prefixed with a tab and ends the entry
+		if strings.HasPrefix(line, "\t") {
+			current.Content = line[1:]
+			if _, ok := commits[current.Commit]; !ok {
+				commit := *current
+				commits[current.Commit] = &commit
+			}
+			lines = append(lines, *current)
+			current = nil
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.AuthorEmail = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				current.Date = time.Unix(seconds, 0).In(current.Date.Location())
+			}
+		case "author-tz":
+			current.Date = current.Date.In(parseTimezone(value))
+		case "summary":
+			current.Summary = value
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("unexpected end of blame output")
+	}
+	return lines, nil
+}
+
+// parseTimezone parses a git timezone offset such as "+0200"
+func parseTimezone(tz string) *time.Location {
+	if len(tz) != 5 {
+		return time.UTC
+	}
+	hours, err1 := strconv.Atoi(tz[1:3])
+	minutes, err2 := strconv.Atoi(tz[3:5])
+	if err1 != nil || err2 != nil {
+		return time.UTC
+	}
+	offset := hours*3600 + minutes*60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone(tz, offset)
+}
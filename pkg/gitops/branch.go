@@ -0,0 +1,90 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchListFormat is the format used to list branches with `git for-each-ref`.
+// Fields are separated by NUL bytes and parsed by ParseBranchList.
+const BranchListFormat = "--format=%(refname)%00%(refname:short)%00%(HEAD)%00%(objectname)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(committerdate:iso-strict)%00%(contents:subject)"
+
+// BranchInfo describes a local or remote-tracking branch
+type BranchInfo struct {
+	Name           string    `json:"name"`    // short name, e.g. "main" or "origin/main"
+	Remote         bool      `json:"remote"`  // whether this is a remote-tracking branch
+	Current        bool      `json:"current"` // whether HEAD points to this branch
+	Commit         string    `json:"commit"`
+	Upstream       string    `json:"upstream,omitempty"` // short name of the upstream branch, if configured
+	UpstreamGone   bool      `json:"upstream_gone"`      // whether the configured upstream no longer exists
+	Ahead          int       `json:"ahead"`              // commits on the branch that are not on its upstream
+	Behind         int       `json:"behind"`             // commits on the upstream that are not on the branch
+	LastCommitDate time.Time `json:"last_commit_date"`
+	Subject        string    `json:"subject"` // subject of the last commit
+}
+
+// ParseBranchList parses the output of `git for-each-ref` run with BranchListFormat.
+// Symbolic remote HEAD references such as "origin/HEAD" are skipped.
+func ParseBranchList(output string) ([]BranchInfo, error) {
+	var branches []BranchInfo
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("unexpected branch list line: %q", line)
+		}
+
+		refName := fields[0]
+		if strings.HasPrefix(refName, "refs/remotes/") && strings.HasSuffix(refName, "/HEAD") {
+			continue
+		}
+
+		branch := BranchInfo{
+			Name:     fields[1],
+			Remote:   strings.HasPrefix(refName, "refs/remotes/"),
+			Current:  fields[2] == "*",
+			Commit:   fields[3],
+			Upstream: fields[4],
+			Subject:  fields[7],
+		}
+		branch.Ahead, branch.Behind, branch.UpstreamGone = parseUpstreamTrack(fields[5])
+		if date, err := time.Parse(time.RFC3339, fields[6]); err == nil {
+			branch.LastCommitDate = date
+		}
+
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// parseUpstreamTrack parses "%(upstream:track,nobracket)" output such as
+// "ahead 1, behind 2", "behind 3" or "gone"
+func parseUpstreamTrack(track string) (int, int, bool) {
+	if track == "gone" {
+		return 0, 0, true
+	}
+
+	var ahead, behind int
+	for _, part := range strings.Split(track, ",") {
+		kind, count, found := strings.Cut(strings.TrimSpace(part), " ")
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		switch kind {
+		case "ahead":
+			ahead = n
+		case "behind":
+			behind = n
+		}
+	}
+	return ahead, behind, false
+}
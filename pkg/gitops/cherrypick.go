@@ -0,0 +1,43 @@
+package gitops
+
+// CherryPickArgs builds the git cherry-pick arguments for applying revisions
+func CherryPickArgs(revisions []string, recordOrigin bool, noCommit bool) ([]string, error) {
+	if err := validateRevisions(revisions); err != nil {
+		return nil, err
+	}
+
+	args := []string{"cherry-pick"}
+	if recordOrigin {
+		args = append(args, "-x")
+	}
+	if noCommit {
+		args = append(args, "--no-commit")
+	}
+	return append(args, revisions...), nil
+}
+
+// RevertArgs builds the git revert arguments for reverting revisions
+func RevertArgs(revisions []string, noCommit bool) ([]string, error) {
+	if err := validateRevisions(revisions); err != nil {
+		return nil, err
+	}
+
+	args := []string{"revert"}
+	if noCommit {
+		args = append(args, "--no-commit")
+	} else {
+		// Never open an editor for the revert commit message
+		args = append(args, "--no-edit")
+	}
+	return append(args, revisions...), nil
+}
+
+// validateRevisions checks that none of the revisions can be mistaken for an option
+func validateRevisions(revisions []string) error {
+	for _, revision := range revisions {
+		if err := ValidateArgument("revision", revision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package gitops
+
+import "strconv"
+
+// CloneArgs builds the git clone arguments for cloning url into targetPath
+func CloneArgs(url string, targetPath string, branch string, depth int, filter string) []string {
+	args := []string{"clone"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	if filter != "" {
+		args = append(args, "--filter="+filter)
+	}
+	// Separate the positional arguments so a URL can't be mistaken for an option
+	return append(args, "--", url, targetPath)
+}
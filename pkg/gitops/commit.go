@@ -0,0 +1,123 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Identity is the name and email of a commit author or committer
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// ParseIdentity parses an identity in the "Name <email>" form used by git
+func ParseIdentity(s string) (Identity, error) {
+	s = strings.TrimSpace(s)
+	open := strings.LastIndex(s, "<")
+	if open < 0 || !strings.HasSuffix(s, ">") {
+		return Identity{}, fmt.Errorf("%q is not of the form \"Name <email>\"", s)
+	}
+
+	identity := Identity{
+		Name:  strings.TrimSpace(s[:open]),
+		Email: s[open+1 : len(s)-1],
+	}
+	if identity.Name == "" || identity.Email == "" || strings.ContainsAny(identity.Email, "<>") {
+		return Identity{}, fmt.Errorf("%q is not of the form \"Name <email>\"", s)
+	}
+	return identity, nil
+}
+
+// String formats the identity as "Name <email>"
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// CommitOptions controls how CommitChanges records a commit
+type CommitOptions struct {
+	// Author overrides the author of the commit, which is otherwise resolved from
+	// git config like the committer
+	Author *Identity
+	// Committer replaces user.name and user.email from git config, so it is also
+	// the author unless Author is set
+	Committer *Identity
+	// Date overrides the author date, which is otherwise now, or the author date
+	// of the amended commit
+	Date time.Time
+	// Sign signs the commit with user.signingkey even if commit.gpgsign isn't set
+	Sign bool
+	// Amend replaces the current commit, keeping its author unless Author is set.
+	// An empty message keeps its message.
+	Amend bool
+	// AllowEmpty allows a commit with the same tree as its parent
+	AllowEmpty bool
+	// All stages the changes of tracked files before committing, like `git commit -a`
+	All bool
+	// Trailers are appended to the message, each in the "Token: value" form
+	// returned by ParseTrailer
+	Trailers []string
+}
+
+// trailerPattern matches a trailer line, capturing its token and value
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*?)\s*$`)
+
+// ParseTrailer parses a trailer such as "Signed-off-by: Name <email>", and
+// returns it in the "Token: value" form that git writes
+func ParseTrailer(s string) (string, error) {
+	match := trailerPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil || match[2] == "" {
+		return "", fmt.Errorf("%q is not of the form \"Token: value\"", s)
+	}
+	return match[1] + ": " + match[2], nil
+}
+
+// AddTrailers appends trailers returned by ParseTrailer to a commit message like
+// `git commit --trailer`: they are added to the trailer block ending the message,
+// or after a blank line if there is none. A trailer that is the same as the one
+// before it isn't added.
+func AddTrailers(message string, trailers []string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+
+	lines := strings.Split(strings.TrimRight(message, " \t\n"), "\n")
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	// The subject is never a trailer block
+	if start == 0 || !isTrailerBlock(lines[start:]) {
+		lines = append(lines, "")
+	}
+
+	for _, trailer := range trailers {
+		if last := lines[len(lines)-1]; sameTrailer(last, trailer) {
+			continue
+		}
+		lines = append(lines, trailer)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// isTrailerBlock checks that a paragraph only consists of trailers and their
+// continuation lines
+func isTrailerBlock(lines []string) bool {
+	for i, line := range lines {
+		if i > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			continue
+		}
+		if !trailerPattern.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameTrailer compares two trailer lines, ignoring the case of their tokens
+func sameTrailer(a string, b string) bool {
+	matchA, matchB := trailerPattern.FindStringSubmatch(a), trailerPattern.FindStringSubmatch(b)
+	return matchA != nil && matchB != nil && strings.EqualFold(matchA[1], matchB[1]) && matchA[2] == matchB[2]
+}
@@ -0,0 +1,140 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictHunk represents a single conflicted region of a file, delimited by
+// conflict markers in the working tree
+type ConflictHunk struct {
+	StartLine int    `json:"start_line"` // 1-based line of the "<<<<<<<" marker
+	EndLine   int    `json:"end_line"`   // 1-based line of the ">>>>>>>" marker
+	Ours      string `json:"ours"`
+	Base      string `json:"base,omitempty"` // only present with merge.conflictStyle=diff3 or zdiff3
+	Theirs    string `json:"theirs"`
+}
+
+// ConflictFile represents a file with unresolved conflicts
+type ConflictFile struct {
+	Path  string         `json:"path"`
+	Hunks []ConflictHunk `json:"hunks,omitempty"`
+}
+
+// ConflictError is returned by operations that stopped because changes could
+// not be applied cleanly. The repository is left in the conflicted state.
+type ConflictError struct {
+	Operation string
+	Conflicts []ConflictFile
+	Output    string
+}
+
+func (e *ConflictError) Error() string {
+	paths := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		paths = append(paths, conflict.Path)
+	}
+	return fmt.Sprintf("%s stopped with conflicts in: %s", e.Operation, strings.Join(paths, ", "))
+}
+
+// ListConflictedFiles returns the paths of all unmerged files in the index
+func ListConflictedFiles(ctx context.Context, repoPath string) ([]string, error) {
+	output, err := RunGitCommand(ctx, repoPath, "diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// CollectConflicts returns all unmerged files together with the conflict hunks
+// found in their working tree contents
+func CollectConflicts(ctx context.Context, repoPath string) ([]ConflictFile, error) {
+	paths, err := ListConflictedFiles(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]ConflictFile, 0, len(paths))
+	for _, path := range paths {
+		conflict := ConflictFile{Path: path}
+
+		// The file may be missing from the worktree, e.g. for modify/delete conflicts
+		content, err := os.ReadFile(filepath.Join(repoPath, path))
+		if err == nil {
+			conflict.Hunks = ParseConflictHunks(string(content))
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read conflicted file %s: %w", path, err)
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// NewConflictError checks the repository for unmerged files after a failed
+// operation. It returns a *ConflictError if there are any, otherwise nil.
+func NewConflictError(ctx context.Context, repoPath string, operation string, output string) error {
+	conflicts, err := CollectConflicts(ctx, repoPath)
+	if err != nil || len(conflicts) == 0 {
+		return nil
+	}
+	return &ConflictError{
+		Operation: operation,
+		Conflicts: conflicts,
+		Output:    output,
+	}
+}
+
+// ParseConflictHunks extracts the conflict hunks from file content containing
+// conflict markers
+func ParseConflictHunks(content string) []ConflictHunk {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	var hunks []ConflictHunk
+	var current ConflictHunk
+	var ours, base, theirs []string
+	state := outside
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNumber := i + 1
+		switch {
+		case state == outside && strings.HasPrefix(line, "<<<<<<<"):
+			current = ConflictHunk{StartLine: lineNumber}
+			ours, base, theirs = nil, nil, nil
+			state = inOurs
+		case state == inOurs && strings.HasPrefix(line, "|||||||"):
+			state = inBase
+		case (state == inOurs || state == inBase) && strings.HasPrefix(line, "======="):
+			state = inTheirs
+		case state == inTheirs && strings.HasPrefix(line, ">>>>>>>"):
+			current.EndLine = lineNumber
+			current.Ours = strings.Join(ours, "\n")
+			current.Base = strings.Join(base, "\n")
+			current.Theirs = strings.Join(theirs, "\n")
+			hunks = append(hunks, current)
+			state = outside
+		case state == inOurs:
+			ours = append(ours, line)
+		case state == inBase:
+			base = append(base, line)
+		case state == inTheirs:
+			theirs = append(theirs, line)
+		}
+	}
+	return hunks
+}
@@ -0,0 +1,537 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/stretchr/testify/require"
+)
+
+var branchTests = []test{
+	{"CreateBranch", testCreateBranch},
+	{"CheckoutBranch", testCheckoutBranch},
+	{"CheckoutBranch/RemoteBranch", testCheckoutRemoteBranch},
+	{"ListBranches", testListBranches},
+	{"DeleteBranch", testDeleteBranch},
+	{"RenameBranch", testRenameBranch},
+	{"SetUpstream", testSetUpstream},
+	{"MergeBranch", testMergeBranch},
+	{"MergeBranch/Conflict", testMergeConflict},
+	{"Rebase", testRebase},
+	{"Rebase/Conflict", testRebaseConflict},
+	{"CherryPick", testCherryPick},
+	{"CherryPick/Conflict", testCherryPickConflict},
+	{"Revert", testRevert},
+	{"Revert/Conflict", testRevertConflict},
+	{"ApplyMailbox", testApplyMailbox},
+}
+
+func testCreateBranch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("a.txt", "one\n", "Add a")
+	second := f.commit("a.txt", "two\n", "Change a")
+	f.git("tag", "-a", "v1", "-m", "Release 1", first)
+	f.git("branch", "old", first)
+
+	// Any revision can be the base of a branch
+	for name, base := range map[string]string{
+		"from-head":   "",
+		"from-branch": "old",
+		"from-tag":    "v1",
+		"from-hash":   first,
+		"from-parent": "HEAD~1",
+	} {
+		_, err := ops.CreateBranch(context.Background(), f.dir, name, base)
+		require.NoError(t, err, name)
+		expected := first
+		if base == "" {
+			expected = second
+		}
+		require.Equal(t, expected, f.revParse("refs/heads/"+name), name)
+	}
+	require.Equal(t, "main", f.currentBranch())
+
+	_, err := ops.CreateBranch(context.Background(), f.dir, "old", "")
+	require.Error(t, err)
+	require.Equal(t, first, f.revParse("refs/heads/old"))
+	_, err = ops.CreateBranch(context.Background(), f.dir, "new", "missing")
+	require.Error(t, err)
+	_, err = ops.CreateBranch(context.Background(), f.dir, "invalid..name", "")
+	require.Error(t, err)
+}
+
+func testCheckoutBranch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.git("branch", "old", first)
+	f.git("tag", "v1", first)
+	f.write("a.txt", "uncommitted\n")
+	f.write("untracked.txt", "new\n")
+
+	// Local changes are carried over if they don't conflict
+	_, err := ops.CheckoutBranch(context.Background(), f.dir, "old")
+	require.NoError(t, err)
+	require.Equal(t, "old", f.currentBranch())
+	require.NoFileExists(t, f.path("b.txt"))
+	require.Equal(t, " M a.txt\n?? untracked.txt\n", f.status())
+
+	// Other revisions detach HEAD
+	_, err = ops.CheckoutBranch(context.Background(), f.dir, "main")
+	require.NoError(t, err)
+	_, err = ops.CheckoutBranch(context.Background(), f.dir, "v1")
+	require.NoError(t, err)
+	require.Empty(t, f.currentBranch())
+	require.Equal(t, first, f.revParse("HEAD"))
+
+	_, err = ops.CheckoutBranch(context.Background(), f.dir, "missing")
+	require.Error(t, err)
+}
+
+func testCheckoutRemoteBranch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	other := f.clone()
+	other.git("checkout", "-q", "-b", "feature")
+	feature := other.commit("feature.txt", "feature\n", "Add feature")
+	other.git("push", "-q", "origin", "feature")
+	f.git("fetch", "-q")
+
+	// A branch that only exists on the remote is created from its remote-tracking branch
+	_, err := ops.CheckoutBranch(context.Background(), f.dir, "feature")
+	require.NoError(t, err)
+	require.Equal(t, "feature", f.currentBranch())
+	require.Equal(t, feature, f.revParse("HEAD"))
+	require.Equal(t, "origin\n", f.git("config", "branch.feature.remote"))
+	require.Equal(t, "refs/heads/feature\n", f.git("config", "branch.feature.merge"))
+}
+
+func testListBranches(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	other := f.clone()
+	other.commit("b.txt", "bee\n", "Add b")
+	other.git("push", "-q", "origin", "main")
+	f.git("fetch", "-q")
+	f.commit("c.txt", "sea\n", "Add c")
+	f.git("branch", "feature", "HEAD~1")
+	f.git("branch", "--set-upstream-to=main", "feature")
+	f.git("branch", "gone")
+	f.git("config", "branch.gone.remote", "origin")
+	f.git("config", "branch.gone.merge", "refs/heads/gone")
+
+	branches, err := ops.ListBranches(context.Background(), f.dir, true)
+	require.NoError(t, err)
+	expected, err := gitops.ParseBranchList(f.git("for-each-ref", gitops.BranchListFormat, "refs/heads", "refs/remotes"))
+	require.NoError(t, err)
+	require.Equal(t, normalizeBranches(expected), normalizeBranches(branches))
+
+	main := branches[2]
+	require.Equal(t, "main", main.Name)
+	require.True(t, main.Current)
+	require.Equal(t, "origin/main", main.Upstream)
+	require.Equal(t, 1, main.Ahead)
+	require.Equal(t, 1, main.Behind)
+	require.Equal(t, "Add c", main.Subject)
+
+	branches, err = ops.ListBranches(context.Background(), f.dir, false)
+	require.NoError(t, err)
+	require.Len(t, branches, 3)
+}
+
+func testDeleteBranch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("branch", "merged")
+	f.git("checkout", "-q", "-b", "unmerged")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.git("checkout", "-q", "main")
+
+	_, err := ops.DeleteBranch(context.Background(), f.dir, "merged", false)
+	require.NoError(t, err)
+	require.False(t, f.hasRef(f.dir, "refs/heads/merged"))
+
+	_, err = ops.DeleteBranch(context.Background(), f.dir, "unmerged", false)
+	require.Error(t, err)
+	require.True(t, f.hasRef(f.dir, "refs/heads/unmerged"))
+	_, err = ops.DeleteBranch(context.Background(), f.dir, "unmerged", true)
+	require.NoError(t, err)
+	require.False(t, f.hasRef(f.dir, "refs/heads/unmerged"))
+
+	_, err = ops.DeleteBranch(context.Background(), f.dir, "main", true)
+	require.Error(t, err)
+	_, err = ops.DeleteBranch(context.Background(), f.dir, "missing", true)
+	require.Error(t, err)
+
+	// Names must not be passed to git as options
+	_, err = ops.DeleteBranch(context.Background(), f.dir, "-D", false)
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+func testRenameBranch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	head := f.revParse("HEAD")
+	f.git("branch", "other")
+
+	_, err := ops.RenameBranch(context.Background(), f.dir, "main", "trunk")
+	require.NoError(t, err)
+	require.Equal(t, "trunk", f.currentBranch())
+	require.Equal(t, head, f.revParse("refs/heads/trunk"))
+	require.False(t, f.hasRef(f.dir, "refs/heads/main"))
+	require.Equal(t, "refs/heads/main\n", f.git("config", "branch.trunk.merge"))
+
+	_, err = ops.RenameBranch(context.Background(), f.dir, "trunk", "other")
+	require.Error(t, err)
+	_, err = ops.RenameBranch(context.Background(), f.dir, "missing", "new")
+	require.Error(t, err)
+
+	// Names must not be passed to git as options, or escape refs/heads
+	_, err = ops.RenameBranch(context.Background(), f.dir, "-M", "other")
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.Equal(t, head, f.revParse("refs/heads/other"))
+	_, err = ops.RenameBranch(context.Background(), f.dir, "trunk", "-M")
+	require.ErrorContains(t, err, "must not start with '-'")
+	_, err = ops.RenameBranch(context.Background(), f.dir, "trunk", "../../evil")
+	require.Error(t, err)
+	require.NoFileExists(t, f.path(".git/evil"))
+	require.Equal(t, "trunk", f.currentBranch())
+}
+
+func testSetUpstream(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	f.git("branch", "feature")
+	f.git("config", "--unset", "branch.main.remote")
+	f.git("config", "--unset", "branch.main.merge")
+
+	_, err := ops.SetUpstream(context.Background(), f.dir, "", "origin/main")
+	require.NoError(t, err)
+	require.Equal(t, "origin\n", f.git("config", "branch.main.remote"))
+	require.Equal(t, "refs/heads/main\n", f.git("config", "branch.main.merge"))
+
+	_, err = ops.SetUpstream(context.Background(), f.dir, "feature", "main")
+	require.NoError(t, err)
+	require.Equal(t, ".\n", f.git("config", "branch.feature.remote"))
+	require.Equal(t, "refs/heads/main\n", f.git("config", "branch.feature.merge"))
+
+	_, err = ops.SetUpstream(context.Background(), f.dir, "feature", "origin/missing")
+	require.Error(t, err)
+	_, err = ops.SetUpstream(context.Background(), f.dir, "missing", "main")
+	require.Error(t, err)
+
+	// Names must not be passed to git as options, or escape refs/heads
+	_, err = ops.SetUpstream(context.Background(), f.dir, "-D", "main")
+	require.ErrorContains(t, err, "must not start with '-'")
+	_, err = ops.SetUpstream(context.Background(), f.dir, "feature", "-main")
+	require.ErrorContains(t, err, "must not start with '-'")
+	_, err = ops.SetUpstream(context.Background(), f.dir, "feature", "../../HEAD")
+	require.Error(t, err)
+	require.Equal(t, "refs/heads/main\n", f.git("config", "branch.feature.merge"))
+}
+
+func testMergeBranch(t *testing.T, ops gitops.GitOperations) {
+	for _, mode := range []gitops.MergeMode{gitops.MergeModeDefault, gitops.MergeModeFastForwardOnly, gitops.MergeModeNoFastForward, gitops.MergeModeSquash} {
+		t.Run(string(mode), func(t *testing.T) {
+			f := newFixture(t)
+			base := f.commit("a.txt", "one\n", "Add a")
+			f.git("checkout", "-q", "-b", "feature")
+			feature := f.commit("b.txt", "bee\n", "Add b")
+			f.git("checkout", "-q", "main")
+
+			_, err := ops.MergeBranch(context.Background(), f.dir, "feature", mode, "Merge feature")
+			require.NoError(t, err)
+			require.Equal(t, "bee\n", f.read("b.txt"))
+
+			switch mode {
+			case gitops.MergeModeDefault, gitops.MergeModeFastForwardOnly:
+				require.Equal(t, feature, f.revParse("HEAD"))
+				require.Empty(t, f.status())
+			case gitops.MergeModeNoFastForward:
+				require.Equal(t, base, f.revParse("HEAD^1"))
+				require.Equal(t, feature, f.revParse("HEAD^2"))
+				require.Equal(t, "Merge feature", f.message("HEAD"))
+			case gitops.MergeModeSquash:
+				require.Equal(t, base, f.revParse("HEAD"))
+				require.Equal(t, "A  b.txt\n", f.status())
+			}
+		})
+	}
+
+	t.Run("NotFastForward", func(t *testing.T) {
+		f := newFixture(t)
+		f.commit("a.txt", "one\n", "Add a")
+		f.git("checkout", "-q", "-b", "feature")
+		f.commit("b.txt", "bee\n", "Add b")
+		f.git("checkout", "-q", "main")
+		head := f.commit("c.txt", "sea\n", "Add c")
+
+		_, err := ops.MergeBranch(context.Background(), f.dir, "feature", gitops.MergeModeFastForwardOnly, "")
+		require.Error(t, err)
+		require.Equal(t, head, f.revParse("HEAD"))
+	})
+
+	t.Run("OptionLikeBranch", func(t *testing.T) {
+		f := newFixture(t)
+		f.commit("a.txt", "one\n", "Add a")
+
+		for _, mode := range []gitops.MergeMode{gitops.MergeModeDefault, gitops.MergeModeFastForwardOnly} {
+			_, err := ops.MergeBranch(context.Background(), f.dir, "--abort", mode, "")
+			require.ErrorContains(t, err, "must not start with '-'")
+		}
+	})
+}
+
+func testMergeConflict(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\ntwo\nthree\n", "Add a")
+	f.git("checkout", "-q", "-b", "feature")
+	feature := f.commit("a.txt", "one\nfeature\nthree\n", "Change a on feature")
+	f.git("checkout", "-q", "main")
+	head := f.commit("a.txt", "one\nmain\nthree\n", "Change a on main")
+
+	_, err := ops.MergeBranch(context.Background(), f.dir, "feature", gitops.MergeModeDefault, "")
+	requireConflict(t, err, "a.txt", "main", "feature")
+	require.Equal(t, "UU a.txt\n", f.status())
+
+	_, err = ops.MergeAbort(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.Empty(t, f.status())
+	require.Equal(t, head, f.revParse("HEAD"))
+
+	_, err = ops.MergeAbort(context.Background(), f.dir)
+	require.Error(t, err)
+
+	// Committing the resolved conflicts concludes the merge
+	_, err = ops.MergeBranch(context.Background(), f.dir, "feature", gitops.MergeModeDefault, "")
+	requireConflict(t, err, "a.txt", "main", "feature")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Amend", gitops.CommitOptions{Amend: true})
+	require.Error(t, err)
+	f.write("a.txt", "one\nboth\nthree\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Merge feature", gitops.CommitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, head+" "+feature, strings.TrimSpace(f.git("log", "-1", "--format=%P")))
+	require.Empty(t, f.status())
+	require.NoFileExists(t, f.path(".git/MERGE_HEAD"))
+	require.NoFileExists(t, f.path(".git/MERGE_MSG"))
+}
+
+func testRebase(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("b.txt", "bee\nfixed\n")
+	f.git("add", "b.txt")
+	f.git("commit", "-q", "-m", "fixup! Add b")
+	f.git("checkout", "-q", "main")
+	upstream := f.commit("c.txt", "sea\n", "Add c")
+	f.git("checkout", "-q", "feature")
+
+	state, err := ops.Rebase(context.Background(), f.dir, "main", "", true)
+	require.NoError(t, err)
+	require.False(t, state.InProgress)
+	require.Equal(t, upstream, f.revParse("HEAD~1"))
+	require.Equal(t, "Add b", f.message("HEAD"))
+	require.Equal(t, "bee\nfixed\n", f.read("b.txt"))
+	require.Equal(t, "feature", f.currentBranch())
+
+	// The upstream must not be passed to git as an option that runs a command
+	_, err = ops.Rebase(context.Background(), f.dir, "--exec=touch executed", "", false)
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("executed"))
+}
+
+func testRebaseConflict(t *testing.T, ops gitops.GitOperations) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("a.txt", "feature\n", "Change a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.commit("a.txt", "feature again\n", "Change a again")
+	f.git("checkout", "-q", "main")
+	upstream := f.commit("a.txt", "main\n", "Change a on main")
+	f.git("checkout", "-q", "feature")
+	original := f.revParse("HEAD")
+
+	state, err := ops.Rebase(ctx, f.dir, "main", "", false)
+	require.NoError(t, err)
+	require.True(t, state.InProgress)
+	require.Equal(t, "refs/heads/feature", state.HeadName)
+	require.Equal(t, upstream, state.Onto)
+	require.Equal(t, "Change a", state.CurrentSubject)
+	require.Equal(t, []string{"a.txt"}, state.ConflictedFiles)
+
+	// Resolve the first conflict
+	f.write("a.txt", "resolved\n")
+	f.git("add", "a.txt")
+	state, err = ops.RebaseContinue(ctx, f.dir)
+	require.NoError(t, err)
+	require.True(t, state.InProgress)
+	require.Equal(t, "Change a again", state.CurrentSubject)
+
+	state, err = ops.RebaseSkip(ctx, f.dir)
+	require.NoError(t, err)
+	require.False(t, state.InProgress)
+	require.Equal(t, []string{"Add b", "Change a", "Change a on main", "Add a"}, strings.Split(strings.TrimSpace(f.git("log", "--format=%s")), "\n"))
+
+	// Abort restores the branch
+	f.git("reset", "-q", "--hard", original)
+	_, err = ops.Rebase(ctx, f.dir, "main", "", false)
+	require.NoError(t, err)
+	_, err = ops.RebaseAbort(ctx, f.dir)
+	require.NoError(t, err)
+	require.Equal(t, original, f.revParse("HEAD"))
+	require.Equal(t, "feature", f.currentBranch())
+
+	_, err = ops.RebaseAbort(ctx, f.dir)
+	require.Error(t, err)
+}
+
+func testCherryPick(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("checkout", "-q", "-b", "feature")
+	first := f.commit("b.txt", "bee\n", "Add b")
+	second := f.commit("c.txt", "sea\n", "Add c")
+	f.git("checkout", "-q", "main")
+	f.commit("a.txt", "main\n", "Change a on main")
+
+	_, err := ops.CherryPick(context.Background(), f.dir, []string{first, second}, true, false)
+	require.NoError(t, err)
+	require.Equal(t, "Add c\n\n(cherry picked from commit "+second+")", f.message("HEAD"))
+	require.Equal(t, "Add b", strings.TrimSpace(f.git("log", "-1", "--format=%s", "HEAD~1")))
+
+	head := f.revParse("HEAD")
+	f.git("rm", "-q", "b.txt")
+	f.git("commit", "-q", "-m", "Remove b")
+	_, err = ops.CherryPick(context.Background(), f.dir, []string{first}, false, true)
+	require.NoError(t, err)
+	require.Equal(t, "A  b.txt\n", f.status())
+	f.git("reset", "-q", "--hard", head)
+
+	// Revisions must not be passed to git as options
+	_, err = ops.CherryPick(context.Background(), f.dir, []string{head, "--quit"}, false, false)
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+func testCherryPickConflict(t *testing.T, ops gitops.GitOperations) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("checkout", "-q", "-b", "feature")
+	f.write("a.txt", "feature\n")
+	f.git("add", "a.txt")
+	f.git("commit", "-q", "--author=Other <other@example.com>", "-m", "Change a")
+	conflicting := f.revParse("HEAD")
+	f.git("checkout", "-q", "main")
+	head := f.commit("a.txt", "main\n", "Change a on main")
+
+	_, err := ops.CherryPick(ctx, f.dir, []string{conflicting}, false, false)
+	requireConflict(t, err, "a.txt", "main", "feature")
+
+	// Committing the resolved conflicts concludes the cherry-pick, keeping the
+	// author of the picked commit
+	f.write("a.txt", "both\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(ctx, f.dir, "Change a", gitops.CommitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, head, strings.TrimSpace(f.git("log", "-1", "--format=%P")))
+	require.Equal(t, "Other <other@example.com>, Test User", strings.TrimSpace(f.git("log", "-1", "--format=%an <%ae>, %cn")))
+	require.Empty(t, f.status())
+	require.NoFileExists(t, f.path(".git/CHERRY_PICK_HEAD"))
+	require.NoFileExists(t, f.path(".git/MERGE_MSG"))
+}
+
+func testRevert(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	change := f.commit("a.txt", "two\n", "Change a")
+	f.commit("b.txt", "bee\n", "Add b")
+
+	_, err := ops.Revert(context.Background(), f.dir, []string{change}, false)
+	require.NoError(t, err)
+	require.Equal(t, "one\n", f.read("a.txt"))
+	require.Contains(t, f.message("HEAD"), "This reverts commit "+change)
+	require.Empty(t, f.status())
+
+	_, err = ops.Revert(context.Background(), f.dir, []string{"HEAD"}, true)
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\n", f.status())
+
+	// Revisions must not be passed to git as options
+	_, err = ops.Revert(context.Background(), f.dir, []string{"--quit"}, false)
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.Equal(t, "M  a.txt\n", f.status())
+}
+
+func testRevertConflict(t *testing.T, ops gitops.GitOperations) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	change := f.commit("a.txt", "two\n", "Change a")
+	head := f.commit("a.txt", "three\n", "Change a again")
+
+	_, err := ops.Revert(ctx, f.dir, []string{change}, false)
+	requireConflict(t, err, "a.txt", "three", "one")
+
+	// Committing the resolved conflicts concludes the revert
+	f.write("a.txt", "one\nthree\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(ctx, f.dir, "Revert change of a", gitops.CommitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, head, strings.TrimSpace(f.git("log", "-1", "--format=%P")))
+	require.Empty(t, f.status())
+	require.NoFileExists(t, f.path(".git/REVERT_HEAD"))
+	require.NoFileExists(t, f.path(".git/MERGE_MSG"))
+}
+
+func testApplyMailbox(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	base := f.commit("a.txt", "one\n", "Add a")
+	f.commit("a.txt", "two\n", "Change a\n\nWith a body")
+	f.commit("b.txt", "bee\n", "Add b")
+	mailbox := f.git("format-patch", "--stdout", base+"..HEAD")
+	f.git("reset", "-q", "--hard", base)
+
+	_, err := ops.ApplyMailbox(context.Background(), f.dir, mailbox, gitops.AmOptions{Committer: &gitops.Identity{Name: "Other", Email: "other@example.com"}})
+	require.NoError(t, err)
+	require.Equal(t, "Change a\n\nWith a body", f.message("HEAD~1"))
+	require.Equal(t, "Add b", f.message("HEAD"))
+	require.Equal(t, "Test User, Other", strings.TrimSpace(f.git("log", "-1", "--format=%an, %cn")))
+
+	// A series is applied completely or not at all
+	f.git("reset", "-q", "--hard", base)
+	f.commit("b.txt", "buzz\n", "Add another b")
+	head := f.revParse("HEAD")
+	_, err = ops.ApplyMailbox(context.Background(), f.dir, mailbox, gitops.AmOptions{})
+	require.Error(t, err)
+	require.Equal(t, head, f.revParse("HEAD"))
+	require.Empty(t, f.status())
+	state, err := gitops.ReadRebaseState(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.False(t, state.InProgress)
+}
+
+// requireConflict checks that err is a conflict in a single file between ours and theirs
+func requireConflict(t *testing.T, err error, path string, ours string, theirs string) {
+	t.Helper()
+	var conflictErr *gitops.ConflictError
+	require.True(t, errors.As(err, &conflictErr), "expected a conflict, got %v", err)
+	require.Len(t, conflictErr.Conflicts, 1)
+	require.Equal(t, path, conflictErr.Conflicts[0].Path)
+	require.Len(t, conflictErr.Conflicts[0].Hunks, 1)
+	require.Equal(t, ours, conflictErr.Conflicts[0].Hunks[0].Ours)
+	require.Equal(t, theirs, conflictErr.Conflicts[0].Hunks[0].Theirs)
+}
+
+// normalizeBranches converts the dates of branches to UTC, so that they can be compared
+func normalizeBranches(branches []gitops.BranchInfo) []gitops.BranchInfo {
+	normalized := make([]gitops.BranchInfo, 0, len(branches))
+	for _, branch := range branches {
+		branch.LastCommitDate = branch.LastCommitDate.UTC()
+		normalized = append(normalized, branch)
+	}
+	return normalized
+}
@@ -0,0 +1,39 @@
+// Package conformance checks that an implementation of gitops.GitOperations
+// behaves like git. Every implementation runs the same suite against fixture
+// repositories created with the git CLI, so that the backends of the server
+// can be exchanged without changing the results of the tools.
+package conformance
+
+import (
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+)
+
+// test is a conformance test of a GitOperations implementation
+type test struct {
+	name string
+	run  func(t *testing.T, ops gitops.GitOperations)
+}
+
+// tests lists the conformance tests, grouped by the method they cover
+var tests = concat(worktreeTests, historyTests, branchTests, remoteTests)
+
+// Run runs the conformance suite as subtests of t. newOps returns the
+// implementation under test, it is called once per test.
+func Run(t *testing.T, newOps func() gitops.GitOperations) {
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newOps())
+		})
+	}
+}
+
+// concat joins lists of tests
+func concat(lists ...[]test) []test {
+	var all []test
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
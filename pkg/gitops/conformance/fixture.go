@@ -0,0 +1,177 @@
+package conformance
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureEpoch is the date of the first commit of a fixture. Later commits are
+// one minute apart, so commit dates never tie and histories are ordered the
+// same way by every implementation.
+var fixtureEpoch = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fixture is a repository with a branch "main" and a bare repository
+// configured as its remote "origin"
+type fixture struct {
+	t      *testing.T
+	dir    string
+	remote string
+	ticks  int
+}
+
+// newFixture creates an empty repository without remote
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{t: t, dir: t.TempDir()}
+	f.git("init", "-q", "-b", "main")
+	f.configure(f.dir)
+	return f
+}
+
+// newFixtureWithRemote creates a repository with an initial commit of a.txt
+// that is pushed to the remote "origin" and tracked by main
+func newFixtureWithRemote(t *testing.T) *fixture {
+	t.Helper()
+	f := newFixture(t)
+	f.remote = t.TempDir()
+	f.run(f.remote, "init", "-q", "--bare", "-b", "main")
+	f.git("remote", "add", "origin", f.remote)
+	f.commit("a.txt", "one\ntwo\nthree\n", "Initial commit")
+	f.git("push", "-q", "-u", "origin", "main")
+	return f
+}
+
+// configure sets an identity and disables settings of the user that change the
+// behavior of git
+func (f *fixture) configure(dir string) {
+	f.run(dir, "config", "user.name", "Test User")
+	f.run(dir, "config", "user.email", "test@example.com")
+	f.run(dir, "config", "commit.gpgsign", "false")
+	f.run(dir, "config", "tag.gpgsign", "false")
+	f.run(dir, "config", "pull.rebase", "false")
+}
+
+// clone creates a second clone of the remote, to push changes that the
+// fixture doesn't have yet
+func (f *fixture) clone() *fixture {
+	f.t.Helper()
+	other := &fixture{t: f.t, dir: f.t.TempDir(), remote: f.remote, ticks: f.ticks + 1000}
+	f.run(other.dir, "clone", "-q", f.remote, ".")
+	other.configure(other.dir)
+	return other
+}
+
+// git runs a git command in the repository and returns its output
+func (f *fixture) git(args ...string) string {
+	f.t.Helper()
+	return f.run(f.dir, args...)
+}
+
+// run runs a git command in dir with the next commit date and returns its output
+func (f *fixture) run(dir string, args ...string) string {
+	f.t.Helper()
+	f.ticks++
+	date := fixtureEpoch.Add(time.Duration(f.ticks) * time.Minute).Format(time.RFC3339)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date, "GIT_CONFIG_NOSYSTEM=1")
+	output, err := cmd.CombinedOutput()
+	require.NoError(f.t, err, "git %s: %s", strings.Join(args, " "), output)
+	return string(output)
+}
+
+// path returns the absolute path of a file in the working tree
+func (f *fixture) path(name string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(name))
+}
+
+// write writes a file in the working tree, creating its directory
+func (f *fixture) write(name string, content string) {
+	f.t.Helper()
+	require.NoError(f.t, os.MkdirAll(filepath.Dir(f.path(name)), 0755))
+	require.NoError(f.t, os.WriteFile(f.path(name), []byte(content), 0644))
+}
+
+// read returns the content of a file in the working tree
+func (f *fixture) read(name string) string {
+	f.t.Helper()
+	content, err := os.ReadFile(f.path(name))
+	require.NoError(f.t, err)
+	return string(content)
+}
+
+// commit writes a file, stages it and commits it, returning the commit hash
+func (f *fixture) commit(name string, content string, message string) string {
+	f.t.Helper()
+	f.write(name, content)
+	f.git("add", name)
+	f.git("commit", "-q", "-m", message)
+	return f.revParse("HEAD")
+}
+
+// message returns the message of a commit without trailing newlines
+func (f *fixture) message(revision string) string {
+	f.t.Helper()
+	return strings.TrimRight(f.git("log", "-1", "--format=%B", revision), "\n")
+}
+
+// revParse resolves a revision to an object hash
+func (f *fixture) revParse(revision string) string {
+	f.t.Helper()
+	return strings.TrimSpace(f.git("rev-parse", "--verify", revision))
+}
+
+// remoteRevParse resolves a revision in the remote repository
+func (f *fixture) remoteRevParse(revision string) string {
+	f.t.Helper()
+	return strings.TrimSpace(f.run(f.remote, "rev-parse", "--verify", revision))
+}
+
+// hasRef reports whether a reference exists
+func (f *fixture) hasRef(dir string, ref string) bool {
+	f.t.Helper()
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", ref)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
+// status returns the short status of the working tree
+func (f *fixture) status() string {
+	f.t.Helper()
+	return f.git("status", "--porcelain=v1", "--untracked-files=all")
+}
+
+// currentBranch returns the checked out branch, or "" if HEAD is detached
+func (f *fixture) currentBranch() string {
+	f.t.Helper()
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = f.dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// mergeHistory creates a branch "side" that is merged into main, with commits
+// on both branches after they diverged. Returns the hash of the merge commit.
+func (f *fixture) mergeHistory() string {
+	f.t.Helper()
+	f.commit("a.txt", "one\ntwo\nthree\n", "Add a")
+	f.git("checkout", "-q", "-b", "side")
+	f.commit("side.txt", "side\n", "Add side")
+	f.commit("side.txt", "side\nmore\n", "Extend side")
+	f.git("checkout", "-q", "main")
+	f.commit("a.txt", "one\n2\nthree\n", "Change a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.git("merge", "-q", "--no-ff", "-m", "Merge side", "side")
+	f.commit("b.txt", "bee\nbuzz\n", "Extend b")
+	return f.revParse("HEAD~1")
+}
@@ -0,0 +1,396 @@
+package conformance
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/stretchr/testify/require"
+)
+
+var historyTests = []test{
+	{"GetLog", testGetLog},
+	{"GetLog/Filters", testGetLogFilters},
+	{"Blame", testBlame},
+	{"ReadFileAtRevision", testReadFileAtRevision},
+	{"ListTree", testListTree},
+	{"ListFiles", testListFiles},
+	{"Grep", testGrep},
+	{"ListTags", testListTags},
+	{"CreateTag", testCreateTag},
+	{"DeleteTag", testDeleteTag},
+}
+
+func testGetLog(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.mergeHistory()
+
+	commits, err := ops.GetLog(context.Background(), f.dir, gitops.LogOptions{})
+	require.NoError(t, err)
+	expected, err := gitops.ParseLog(f.git(gitops.LogArgs(gitops.LogOptions{})...))
+	require.NoError(t, err)
+	require.Equal(t, normalizeCommits(expected), normalizeCommits(commits))
+
+	require.Equal(t, "Merge side", commits[1].Message)
+	require.Len(t, commits[1].Parents, 2)
+}
+
+func testGetLogFilters(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.mergeHistory()
+	f.git("commit", "-q", "--allow-empty", "--author", "Other Author <other@example.com>", "-m", "Empty\n\nFixes a bug")
+
+	for _, opts := range []gitops.LogOptions{
+		{MaxCount: 3},
+		{MaxCount: 2, Skip: 2},
+		{Revision: "side"},
+		{Revision: "side..main"},
+		{Revision: "main~3..main~1"},
+		{Revision: "side...main"},
+		{Author: "Other"},
+		{Committer: "test@"},
+		{Grep: "^Add"},
+		{Grep: "bug"},
+		{Paths: []string{"b.txt"}},
+		{Paths: []string{"side.txt"}},
+		{Paths: []string{"*.txt"}, MaxCount: 4},
+		{Paths: []string{"?.txt", "[!b]ide.txt"}},
+		{Merges: gitops.LogMergesOnly},
+		{Merges: gitops.LogMergesExclude},
+		{Since: "2024-01-01T12:20:00Z"},
+		{Until: "2024-01-01T12:20:00Z"},
+		{PickaxeString: "buzz"},
+	} {
+		commits, err := ops.GetLog(context.Background(), f.dir, opts)
+		require.NoError(t, err, "%+v", opts)
+		expected, err := gitops.ParseLog(f.git(gitops.LogArgs(opts)...))
+		require.NoError(t, err)
+		require.Equal(t, commitHashes(expected), commitHashes(commits), "%+v", opts)
+	}
+
+	_, err := ops.GetLog(context.Background(), f.dir, gitops.LogOptions{Revision: "missing"})
+	require.Error(t, err)
+
+	// A revision must not be passed to git as an option that writes files
+	_, err = ops.GetLog(context.Background(), f.dir, gitops.LogOptions{Revision: "--output=written.txt", PickaxeString: "buzz"})
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("written.txt"))
+}
+
+func testBlame(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("a.txt", "one\ntwo\nthree\n", "Add a")
+	second := f.commit("a.txt", "one\n2\nthree\nfour\n", "Change a")
+	f.write("a.txt", "uncommitted\n")
+
+	lines, err := ops.Blame(context.Background(), f.dir, "a.txt", "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+	for i, expected := range []struct {
+		commit  string
+		summary string
+		content string
+	}{
+		{first, "Add a", "one"},
+		{second, "Change a", "2"},
+		{first, "Add a", "three"},
+		{second, "Change a", "four"},
+	} {
+		require.Equal(t, i+1, lines[i].LineNumber)
+		require.Equal(t, i+1, lines[i].OriginalLineNumber)
+		require.Equal(t, expected.commit, lines[i].Commit)
+		require.Equal(t, expected.summary, lines[i].Summary)
+		require.Equal(t, expected.content, lines[i].Content)
+		require.Equal(t, "Test User", lines[i].Author)
+		require.Equal(t, "test@example.com", lines[i].AuthorEmail)
+	}
+
+	lines, err = ops.Blame(context.Background(), f.dir, "a.txt", first, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, 2, lines[0].LineNumber)
+	require.Equal(t, "two", lines[0].Content)
+
+	// Lines keep their numbers in the commits that introduced them when lines are added before them
+	third := f.commit("a.txt", "zero\none\n2\nthree\nfour\n", "Prepend zero")
+	lines, err = ops.Blame(context.Background(), f.dir, "a.txt", "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	require.Equal(t, third, lines[0].Commit)
+	for i, expected := range []int{1, 1, 2, 3, 4} {
+		require.Equal(t, i+1, lines[i].LineNumber)
+		require.Equal(t, expected, lines[i].OriginalLineNumber, "line %d", i+1)
+	}
+
+	_, err = ops.Blame(context.Background(), f.dir, "a.txt", "", 10, 0)
+	require.Error(t, err)
+	_, err = ops.Blame(context.Background(), f.dir, "missing.txt", "", 0, 0)
+	require.Error(t, err)
+
+	// A revision must not be passed to git as an option that reads other files
+	_, err = ops.Blame(context.Background(), f.dir, "a.txt", "--contents=/etc/hostname", 0, 0)
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+func testReadFileAtRevision(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("dir/a.txt", "one\ntwo\nthree\n", "Add a")
+	f.commit("dir/a.txt", "changed\n", "Change a")
+	f.commit("binary.dat", "\x00\x01\x02", "Add binary")
+
+	file, err := ops.ReadFileAtRevision(context.Background(), f.dir, first, "dir/a.txt", 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, &gitops.FileContent{
+		Path:       "dir/a.txt",
+		Revision:   first,
+		Hash:       f.revParse(first + ":dir/a.txt"),
+		Size:       14,
+		Content:    "two\nthree\n",
+		StartLine:  2,
+		EndLine:    3,
+		TotalLines: 3,
+	}, file)
+
+	file, err = ops.ReadFileAtRevision(context.Background(), f.dir, "", "binary.dat", 0, 0)
+	require.NoError(t, err)
+	require.True(t, file.Binary)
+	require.Equal(t, int64(3), file.Size)
+	require.Equal(t, f.revParse("HEAD"), file.Revision)
+
+	_, err = ops.ReadFileAtRevision(context.Background(), f.dir, first, "binary.dat", 0, 0)
+	require.Error(t, err)
+	_, err = ops.ReadFileAtRevision(context.Background(), f.dir, "HEAD", "dir", 0, 0)
+	require.Error(t, err)
+
+	// The revision must not be passed to git as an option
+	_, err = ops.ReadFileAtRevision(context.Background(), f.dir, "--output=written.txt", "dir/a.txt", 0, 0)
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("written.txt"))
+}
+
+func testListTree(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("dir/b.go", "package b\n")
+	f.write("dir/sub/c.go", "package c\n")
+	f.git("add", ".")
+	// A submodule is recorded as a commit in the tree
+	f.git("update-index", "--add", "--cacheinfo", "160000,"+f.revParse("HEAD")+",module")
+	f.git("commit", "-q", "-m", "Add files")
+
+	for _, tc := range []struct {
+		pathPrefix string
+		recursive  bool
+		pattern    string
+	}{
+		{"", false, ""},
+		{"", true, ""},
+		{"dir", false, ""},
+		{"dir/", true, ""},
+		{"", true, "*.go"},
+		{"a.txt", false, ""},
+	} {
+		entries, err := ops.ListTree(context.Background(), f.dir, "", tc.pathPrefix, tc.recursive, tc.pattern)
+		require.NoError(t, err, "%+v", tc)
+
+		expected, err := gitops.ParseLsTree(f.git(gitops.LsTreeArgs("", tc.pathPrefix, tc.recursive)...))
+		require.NoError(t, err)
+		expected, err = gitops.FilterTreeEntries(expected, tc.pattern)
+		require.NoError(t, err)
+		require.Equal(t, expected, entries, "%+v", tc)
+	}
+
+	_, err := ops.ListTree(context.Background(), f.dir, "missing", "", false, "")
+	require.Error(t, err)
+
+	// The revision must not be passed to git as an option
+	_, err = ops.ListTree(context.Background(), f.dir, "--format=%(objectname)", "", false, "")
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+func testListFiles(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.write(".gitignore", "build/\n*.log\n")
+	f.write("src/main.go", "package main\n")
+	f.git("add", ".")
+	f.git("commit", "-q", "-m", "Add files")
+	f.write("src/new.go", "package main\n")
+	f.write("notes.txt", "notes\n")
+	f.write("build/out.bin", "binary\n")
+	f.write("debug.log", "log\n")
+
+	files, err := ops.ListFiles(context.Background(), f.dir, "", false, false)
+	require.NoError(t, err)
+	require.Equal(t, []gitops.IndexFile{
+		{Path: ".gitignore", Status: gitops.IndexFileTracked},
+		{Path: "src/main.go", Status: gitops.IndexFileTracked},
+	}, files)
+
+	files, err = ops.ListFiles(context.Background(), f.dir, "", true, true)
+	require.NoError(t, err)
+	require.Equal(t, []gitops.IndexFile{
+		{Path: ".gitignore", Status: gitops.IndexFileTracked},
+		{Path: "src/main.go", Status: gitops.IndexFileTracked},
+		{Path: "notes.txt", Status: gitops.IndexFileUntracked},
+		{Path: "src/new.go", Status: gitops.IndexFileUntracked},
+		{Path: "build/", Status: gitops.IndexFileIgnored},
+		{Path: "debug.log", Status: gitops.IndexFileIgnored},
+	}, files)
+
+	files, err = ops.ListFiles(context.Background(), f.dir, "*.go", true, false)
+	require.NoError(t, err)
+	require.Equal(t, []gitops.IndexFile{
+		{Path: "src/main.go", Status: gitops.IndexFileTracked},
+		{Path: "src/new.go", Status: gitops.IndexFileUntracked},
+	}, files)
+}
+
+func testGrep(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.write("a.txt", "alpha\nbeta\ngamma\ndelta\n")
+	f.write("dir/b.txt", "Beta\n")
+	f.write("dir/c.go", "// beta\n")
+	f.write("binary.dat", "beta\x00")
+	f.git("add", ".")
+	f.git("commit", "-q", "-m", "Add files")
+	f.write("a.txt", "alpha\nbeta changed\n")
+	f.write("untracked.txt", "beta\n")
+
+	for _, opts := range []gitops.GrepOptions{
+		{Pattern: "beta"},
+		{Pattern: "beta", Revision: "HEAD"},
+		{Pattern: "BETA", IgnoreCase: true, Revision: "HEAD"},
+		{Pattern: "gamma", ContextLines: 1, Revision: "HEAD"},
+		{Pattern: "a$", Revision: "HEAD"},
+		{Pattern: "b.t", FixedStrings: true, Revision: "HEAD"},
+		{Pattern: "beta", Pathspecs: []string{"dir"}, Revision: "HEAD", IgnoreCase: true},
+		{Pattern: "beta", Pathspecs: []string{"*.go"}, Revision: "HEAD"},
+		{Pattern: "beta", Pathspecs: []string{"dir?b.txt", "[d]ir/[!b]*"}, Revision: "HEAD", IgnoreCase: true},
+		{Pattern: "beta", Pathspecs: []string{"d?r"}, Revision: "HEAD"},
+		{Pattern: "a", MaxResults: 2, Revision: "HEAD"},
+	} {
+		result, err := ops.Grep(context.Background(), f.dir, opts)
+		require.NoError(t, err, "%+v", opts)
+
+		// The git CLI is the reference
+		expected, err := gitops.RunGrepCommand(context.Background(), f.dir, opts)
+		require.NoError(t, err)
+		require.Equal(t, expected, result, "%+v", opts)
+	}
+
+	// A revision must not be passed to git as an option that runs a command
+	_, err := ops.Grep(context.Background(), f.dir, gitops.GrepOptions{Pattern: "beta", Revision: "-Otouch opened"})
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("opened"))
+}
+
+func testListTags(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("a.txt", "one\n", "Add a")
+	second := f.commit("a.txt", "two\n", "Change a")
+	f.git("tag", "v1.10.0", first)
+	f.git("tag", "-a", "v1.2.0", "-m", "Release 1.2\n\nNotes", second)
+	f.git("tag", "v1.9.0", second)
+	f.git("tag", "other", first)
+
+	tags, err := ops.ListTags(context.Background(), f.dir, "", gitops.TagSortName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"other", "v1.10.0", "v1.2.0", "v1.9.0"}, tagNames(tags))
+
+	annotated := tags[2]
+	require.True(t, annotated.Annotated)
+	require.Equal(t, second, annotated.Target)
+	require.Equal(t, "Test User <test@example.com>", annotated.Tagger)
+	require.Equal(t, "Release 1.2", annotated.Message)
+	lightweight := tags[1]
+	require.False(t, lightweight.Annotated)
+	require.Equal(t, first, lightweight.Target)
+	require.Empty(t, lightweight.Tagger)
+
+	tags, err = ops.ListTags(context.Background(), f.dir, "v*", gitops.TagSortVersion)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.2.0", "v1.9.0", "v1.10.0"}, tagNames(tags))
+
+	tags, err = ops.ListTags(context.Background(), f.dir, "", gitops.TagSortDate)
+	require.NoError(t, err)
+	require.Equal(t, strings.Fields(f.git("tag", "--list", "--sort=creatordate")), tagNames(tags))
+}
+
+func testCreateTag(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	first := f.commit("a.txt", "one\n", "Add a")
+	second := f.commit("a.txt", "two\n", "Change a")
+
+	_, err := ops.CreateTag(context.Background(), f.dir, "light", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "commit\n", f.git("cat-file", "-t", "light"))
+	require.Equal(t, second, f.revParse("light"))
+
+	_, err = ops.CreateTag(context.Background(), f.dir, "annotated", "HEAD~1", "Release\n\nNotes")
+	require.NoError(t, err)
+	require.Equal(t, "tag\n", f.git("cat-file", "-t", "annotated"))
+	require.Equal(t, first, f.revParse("annotated^{commit}"))
+	require.Equal(t, "Release\n\nNotes", strings.TrimSpace(f.git("tag", "--list", "--format=%(contents)", "annotated")))
+
+	_, err = ops.CreateTag(context.Background(), f.dir, "light", first, "")
+	require.Error(t, err)
+	require.Equal(t, second, f.revParse("light"))
+	_, err = ops.CreateTag(context.Background(), f.dir, "other", "missing", "")
+	require.Error(t, err)
+
+	// The name and revision must not be passed to git as options
+	_, err = ops.CreateTag(context.Background(), f.dir, "other", "--list", "")
+	require.ErrorContains(t, err, "must not start with '-'")
+	_, err = ops.CreateTag(context.Background(), f.dir, "--list", "", "")
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+func testDeleteTag(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.git("tag", "light")
+	f.git("tag", "-a", "annotated", "-m", "Release")
+
+	for _, name := range []string{"light", "annotated"} {
+		_, err := ops.DeleteTag(context.Background(), f.dir, name)
+		require.NoError(t, err)
+		require.False(t, f.hasRef(f.dir, "refs/tags/"+name))
+	}
+
+	_, err := ops.DeleteTag(context.Background(), f.dir, "missing")
+	require.Error(t, err)
+
+	// The name must not be passed to git as an option
+	_, err = ops.DeleteTag(context.Background(), f.dir, "--list")
+	require.ErrorContains(t, err, "must not start with '-'")
+}
+
+// commitHashes returns the hashes of commits
+func commitHashes(commits []gitops.CommitInfo) []string {
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		hashes = append(hashes, commit.Hash)
+	}
+	return hashes
+}
+
+// tagNames returns the names of tags
+func tagNames(tags []gitops.TagInfo) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// normalizeCommits converts the dates of commits to UTC, so that they can be compared
+func normalizeCommits(commits []gitops.CommitInfo) []gitops.CommitInfo {
+	normalized := make([]gitops.CommitInfo, 0, len(commits))
+	for _, commit := range commits {
+		commit.Date = commit.Date.UTC()
+		commit.CommitDate = commit.CommitDate.UTC()
+		normalized = append(normalized, commit)
+	}
+	return normalized
+}
@@ -0,0 +1,221 @@
+package conformance
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/stretchr/testify/require"
+)
+
+var remoteTests = []test{
+	{"PushChanges", testPushChanges},
+	{"PushTags", testPushTags},
+	{"Fetch", testFetch},
+	{"Pull", testPull},
+	{"Pull/Conflict", testPullConflict},
+	{"CloneRepo", testCloneRepo},
+}
+
+func testPushChanges(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	head := f.commit("b.txt", "bee\n", "Add b")
+
+	_, err := ops.PushChanges(context.Background(), f.dir, "", "")
+	require.NoError(t, err)
+	require.Equal(t, head, f.remoteRevParse("refs/heads/main"))
+
+	f.git("checkout", "-q", "-b", "feature")
+	feature := f.commit("c.txt", "sea\n", "Add c")
+	f.git("checkout", "-q", "main")
+	_, err = ops.PushChanges(context.Background(), f.dir, "origin", "feature")
+	require.NoError(t, err)
+	require.Equal(t, feature, f.remoteRevParse("refs/heads/feature"))
+
+	// Nothing to push
+	_, err = ops.PushChanges(context.Background(), f.dir, "origin", "main")
+	require.NoError(t, err)
+
+	// Diverged branches are rejected
+	other := f.clone()
+	other.commit("d.txt", "dee\n", "Add d")
+	other.git("push", "-q", "origin", "main")
+	f.commit("e.txt", "ee\n", "Add e")
+	_, err = ops.PushChanges(context.Background(), f.dir, "origin", "main")
+	require.Error(t, err)
+}
+
+func testPushTags(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	f.git("tag", "v1")
+	f.git("tag", "-a", "v2", "-m", "Release 2")
+	f.git("tag", "v3")
+
+	_, err := ops.PushTags(context.Background(), f.dir, "", []string{"v1", "v2"})
+	require.NoError(t, err)
+	require.Equal(t, f.revParse("v2"), f.remoteRevParse("refs/tags/v2"))
+	require.False(t, f.hasRef(f.remote, "refs/tags/v3"))
+
+	_, err = ops.PushTags(context.Background(), f.dir, "origin", nil)
+	require.NoError(t, err)
+	require.True(t, f.hasRef(f.remote, "refs/tags/v3"))
+
+	_, err = ops.PushTags(context.Background(), f.dir, "origin", []string{"missing"})
+	require.Error(t, err)
+
+	// The remote must not be passed to git as an option that runs a command
+	_, err = ops.PushTags(context.Background(), f.dir, "--receive-pack=touch received", nil)
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("received"))
+}
+
+func testFetch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	other := f.clone()
+	head := other.commit("b.txt", "bee\n", "Add b")
+	other.git("tag", "-a", "v1", "-m", "Release 1", "HEAD~1")
+	other.git("push", "-q", "origin", "main", "v1")
+	other.git("push", "-q", "origin", "main:feature")
+
+	_, err := ops.Fetch(context.Background(), f.dir, "", "feature", false, false)
+	require.NoError(t, err)
+	require.Equal(t, head, f.revParse("refs/remotes/origin/feature"))
+	require.Equal(t, f.revParse("HEAD"), f.revParse("refs/remotes/origin/main"))
+
+	_, err = ops.Fetch(context.Background(), f.dir, "origin", "", false, true)
+	require.NoError(t, err)
+	require.Equal(t, head, f.revParse("refs/remotes/origin/main"))
+	require.True(t, f.hasRef(f.dir, "refs/tags/v1"))
+	require.Equal(t, "main\n", f.git("branch", "--show-current"))
+
+	other.git("push", "-q", "origin", ":feature")
+	_, err = ops.Fetch(context.Background(), f.dir, "origin", "", true, false)
+	require.NoError(t, err)
+	require.False(t, f.hasRef(f.dir, "refs/remotes/origin/feature"))
+
+	_, err = ops.Fetch(context.Background(), f.dir, "missing", "", false, false)
+	require.Error(t, err)
+
+	// The remote and refspec must not be passed to git as options that run commands
+	_, err = ops.Fetch(context.Background(), f.dir, "--upload-pack=touch uploaded", "", false, false)
+	require.ErrorContains(t, err, "must not start with '-'")
+	_, err = ops.Fetch(context.Background(), f.dir, "origin", "--upload-pack=touch uploaded", false, false)
+	require.ErrorContains(t, err, "must not start with '-'")
+	require.NoFileExists(t, f.path("uploaded"))
+}
+
+func testPull(t *testing.T, ops gitops.GitOperations) {
+	for _, tc := range []struct {
+		name     string
+		strategy gitops.PullStrategy
+		ffOnly   bool
+		diverged bool
+	}{
+		{"FastForward", "", false, false},
+		{"FastForwardOnly", "", true, false},
+		{"Merge", gitops.PullStrategyMerge, false, true},
+		{"Rebase", gitops.PullStrategyRebase, false, true},
+		{"FastForwardOnly/Diverged", "", true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixtureWithRemote(t)
+			other := f.clone()
+			upstream := other.commit("b.txt", "bee\n", "Add b")
+			other.git("push", "-q", "origin", "main")
+			local := ""
+			if tc.diverged {
+				local = f.commit("c.txt", "sea\n", "Add c")
+			}
+
+			_, err := ops.Pull(context.Background(), f.dir, "", "", tc.strategy, tc.ffOnly)
+			if tc.ffOnly && tc.diverged {
+				require.Error(t, err)
+				require.Equal(t, local, f.revParse("HEAD"))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "bee\n", f.read("b.txt"))
+			require.Empty(t, f.status())
+
+			switch {
+			case !tc.diverged:
+				require.Equal(t, upstream, f.revParse("HEAD"))
+			case tc.strategy == gitops.PullStrategyMerge:
+				require.Equal(t, local, f.revParse("HEAD^1"))
+				require.Equal(t, upstream, f.revParse("HEAD^2"))
+			case tc.strategy == gitops.PullStrategyRebase:
+				require.Equal(t, upstream, f.revParse("HEAD~1"))
+				require.Equal(t, "Add c", f.message("HEAD"))
+			}
+		})
+	}
+
+	t.Run("Branch", func(t *testing.T) {
+		f := newFixtureWithRemote(t)
+		other := f.clone()
+		other.git("checkout", "-q", "-b", "feature")
+		feature := other.commit("b.txt", "bee\n", "Add b")
+		other.git("push", "-q", "origin", "feature")
+
+		_, err := ops.Pull(context.Background(), f.dir, "origin", "feature", "", true)
+		require.NoError(t, err)
+		require.Equal(t, feature, f.revParse("HEAD"))
+		require.Equal(t, "main", f.currentBranch())
+	})
+
+	t.Run("OptionLikeArguments", func(t *testing.T) {
+		f := newFixtureWithRemote(t)
+		for _, args := range [][2]string{{"--upload-pack=touch uploaded", ""}, {"origin", "--upload-pack=touch uploaded"}} {
+			for _, ffOnly := range []bool{false, true} {
+				_, err := ops.Pull(context.Background(), f.dir, args[0], args[1], "", ffOnly)
+				require.ErrorContains(t, err, "must not start with '-'")
+			}
+		}
+		require.NoFileExists(t, f.path("uploaded"))
+	})
+}
+
+func testPullConflict(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	other := f.clone()
+	other.commit("a.txt", "one\nupstream\nthree\n", "Change a upstream")
+	other.git("push", "-q", "origin", "main")
+	f.commit("a.txt", "one\nlocal\nthree\n", "Change a locally")
+
+	_, err := ops.Pull(context.Background(), f.dir, "", "", gitops.PullStrategyMerge, false)
+	requireConflict(t, err, "a.txt", "local", "upstream")
+}
+
+func testCloneRepo(t *testing.T, ops gitops.GitOperations) {
+	f := newFixtureWithRemote(t)
+	f.commit("b.txt", "bee\n", "Add b")
+	f.git("push", "-q", "origin", "main")
+	f.git("checkout", "-q", "-b", "feature")
+	feature := f.commit("c.txt", "sea\n", "Add c")
+	f.git("push", "-q", "origin", "feature")
+
+	target := filepath.Join(t.TempDir(), "clones", "repo")
+	_, err := ops.CloneRepo(context.Background(), f.remote, target, "", 0, "")
+	require.NoError(t, err)
+	clone := &fixture{t: t, dir: target}
+	require.Equal(t, "main", clone.currentBranch())
+	require.Equal(t, f.revParse("main"), clone.revParse("HEAD"))
+	require.Equal(t, "origin\n", clone.git("config", "branch.main.remote"))
+	require.Equal(t, "bee\n", clone.read("b.txt"))
+
+	target = filepath.Join(t.TempDir(), "shallow")
+	_, err = ops.CloneRepo(context.Background(), "file://"+f.remote, target, "feature", 1, "")
+	require.NoError(t, err)
+	clone = &fixture{t: t, dir: target}
+	require.Equal(t, "feature", clone.currentBranch())
+	require.Equal(t, feature, clone.revParse("HEAD"))
+	require.Equal(t, "1", strings.TrimSpace(clone.git("rev-list", "--count", "HEAD")))
+
+	// A failed clone leaves nothing behind
+	target = filepath.Join(t.TempDir(), "missing")
+	_, err = ops.CloneRepo(context.Background(), filepath.Join(t.TempDir(), "none"), target, "", 0, "")
+	require.Error(t, err)
+	require.NoDirExists(t, target)
+}
@@ -0,0 +1,944 @@
+package conformance
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+var worktreeTests = []test{
+	{"GetStatus", testGetStatus},
+	{"GetStatus/Clean", testGetStatusClean},
+	{"GetDiffUnstaged", testGetDiffUnstaged},
+	{"GetDiffStaged", testGetDiffStaged},
+	{"GetDiffStaged/Unborn", testGetDiffStagedUnborn},
+	{"GetDiff", testGetDiff},
+	{"GetDiff/Range", testGetDiffRange},
+	{"AddFiles", testAddFiles},
+	{"AddFiles/Pathspec", testAddFilesPathspec},
+	{"AddFiles/Update", testAddFilesUpdate},
+	{"AddFiles/All", testAddFilesAll},
+	{"AddFiles/IntentToAdd", testAddFilesIntentToAdd},
+	{"ResetStaged", testResetStaged},
+	{"Restore", testRestore},
+	{"Restore/Staged", testRestoreStaged},
+	{"Restore/StagedUnborn", testRestoreStagedUnborn},
+	{"Restore/Source", testRestoreSource},
+	{"Restore/Pathspec", testRestorePathspec},
+	{"StageHunks", testStageHunks},
+	{"StageHunks/Unstage", testStageHunksUnstage},
+	{"StageHunks/AddedAndDeleted", testStageHunksAddedAndDeleted},
+	{"StageHunks/NoNewline", testStageHunksNoNewline},
+	{"ApplyPatch", testApplyPatch},
+	{"ApplyPatch/Rejected", testApplyPatchRejected},
+	{"ApplyPatch/ThreeWay", testApplyPatchThreeWay},
+	{"CommitChanges", testCommitChanges},
+	{"CommitChanges/NothingStaged", testCommitChangesNothingStaged},
+	{"CommitChanges/Identity", testCommitChangesIdentity},
+	{"CommitChanges/Sign", testCommitChangesSign},
+	{"CommitChanges/Amend", testCommitChangesAmend},
+	{"CommitChanges/AmendRoot", testCommitChangesAmendRoot},
+	{"CommitChanges/AmendMerge", testCommitChangesAmendMerge},
+	{"CommitChanges/AmendUnborn", testCommitChangesAmendUnborn},
+	{"CommitChanges/AllowEmpty", testCommitChangesAllowEmpty},
+	{"CommitChanges/All", testCommitChangesAll},
+	{"CommitChanges/Date", testCommitChangesDate},
+	{"CommitChanges/Trailers", testCommitChangesTrailers},
+	{"ShowCommit", testShowCommit},
+	{"InitRepo", testInitRepo},
+	{"Stash", testStash},
+	{"Stash/IncludeUntracked", testStashIncludeUntracked},
+}
+
+func testGetStatus(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.commit("moved.txt", "moved\n", "Add moved")
+	f.write("a.txt", "two\n")
+	f.write("c.txt", "sea\n")
+	f.git("add", "c.txt")
+	f.git("rm", "-q", "b.txt")
+	f.git("mv", "moved.txt", "renamed.txt")
+	f.write("dir/untracked.txt", "new\n")
+
+	files, err := ops.GetStatus(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.Equal(t, []gitops.FileStatus{
+		{Path: "a.txt", Index: " ", Worktree: "M"},
+		{Path: "b.txt", Index: "D", Worktree: " "},
+		{Path: "c.txt", Index: "A", Worktree: " "},
+		{Path: "dir/untracked.txt", Index: "?", Worktree: "?"},
+		{Path: "renamed.txt", OrigPath: "moved.txt", Index: "R", Worktree: " "},
+	}, files)
+}
+
+func testGetStatusClean(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write(".gitignore", "*.log\n")
+	f.git("add", ".gitignore")
+	f.git("commit", "-q", "-m", "Ignore logs")
+	f.write("debug.log", "ignored\n")
+
+	files, err := ops.GetStatus(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func testGetDiffUnstaged(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\ntwo\nthree\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "one\n2\nthree\n")
+	f.write("b.txt", "staged\n")
+	f.git("add", "b.txt")
+	f.write("untracked.txt", "new\n")
+
+	files, err := ops.GetDiffUnstaged(context.Background(), f.dir)
+	require.NoError(t, err)
+	requireDiff(t, f.git(gitops.DiffArgs([]string{"diff"})...), files)
+	require.Len(t, files, 1)
+	require.Equal(t, "a.txt", files[0].Path)
+	require.Equal(t, "M", files[0].Status)
+	require.Equal(t, 1, files[0].Additions)
+	require.Equal(t, 1, files[0].Deletions)
+}
+
+func testGetDiffStaged(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("moved.txt", "moved\n", "Add moved")
+	f.write("b.txt", "bee\n")
+	f.git("add", "b.txt")
+	f.git("mv", "moved.txt", "renamed.txt")
+	f.write("a.txt", "unstaged\n")
+
+	files, err := ops.GetDiffStaged(context.Background(), f.dir)
+	require.NoError(t, err)
+	requireDiff(t, f.git(gitops.DiffArgs([]string{"diff", "--cached"})...), files)
+	require.Len(t, files, 2)
+	require.Equal(t, "A", files[0].Status)
+	require.Equal(t, "R", files[1].Status)
+	require.Equal(t, "moved.txt", files[1].OldPath)
+}
+
+func testGetDiffStagedUnborn(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.write("a.txt", "one\n")
+	f.git("add", "a.txt")
+
+	files, err := ops.GetDiffStaged(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "a.txt", files[0].Path)
+	require.Equal(t, "A", files[0].Status)
+	require.Equal(t, 1, files[0].Additions)
+}
+
+func testGetDiff(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\ntwo\nthree\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "one\n2\nthree\n")
+	f.write("c.txt", "staged\n")
+	f.git("add", "c.txt")
+
+	// The target is compared with the working tree, including staged changes
+	files, err := ops.GetDiff(context.Background(), f.dir, "HEAD~1")
+	require.NoError(t, err)
+	requireDiff(t, f.git(gitops.DiffArgs([]string{"diff"}, "HEAD~1")...), files)
+	require.Equal(t, []string{"a.txt", "b.txt", "c.txt"}, diffPaths(files))
+}
+
+func testGetDiffRange(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.mergeHistory()
+	f.write("a.txt", "uncommitted\n")
+
+	for _, target := range []string{"HEAD~3..HEAD", "main..side", "main...side"} {
+		files, err := ops.GetDiff(context.Background(), f.dir, target)
+		require.NoError(t, err, target)
+		requireDiff(t, f.git(gitops.DiffArgs([]string{"diff"}, target)...), files)
+	}
+}
+
+func testAddFiles(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.write("dir/c.txt", "sea\n")
+	require.NoError(t, os.Remove(f.path("b.txt")))
+	f.write("untouched.txt", "new\n")
+
+	_, err := ops.AddFiles(context.Background(), f.dir, []string{"a.txt", "b.txt", "dir"}, gitops.AddOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nD  b.txt\nA  dir/c.txt\n?? untouched.txt\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, []string{"missing.txt"}, gitops.AddOptions{})
+	require.Error(t, err)
+}
+
+func testAddFilesPathspec(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("x.go", "package x\n", "Add x")
+	f.write("a.txt", "two\n")
+	f.write("x.go", "package y\n")
+	f.write("dir/c.txt", "sea\n")
+	f.write("dir/d.go", "package d\n")
+	f.write("e.md", "e\n")
+	f.write("with,comma.md", "comma\n")
+
+	_, err := ops.AddFiles(context.Background(), f.dir, []string{"*.txt", "dir/*.go"}, gitops.AddOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nA  dir/c.txt\nA  dir/d.go\n M x.go\n?? e.md\n?? with,comma.md\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, []string{"with,comma.md"}, gitops.AddOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nA  dir/c.txt\nA  dir/d.go\nA  with,comma.md\n M x.go\n?? e.md\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, []string{"*.rs"}, gitops.AddOptions{})
+	require.Error(t, err)
+}
+
+func testAddFilesUpdate(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.commit("dir/c.txt", "sea\n", "Add c")
+	f.write("a.txt", "two\n")
+	require.NoError(t, os.Remove(f.path("b.txt")))
+	f.write("dir/c.txt", "see\n")
+	f.write("new.txt", "new\n")
+
+	_, err := ops.AddFiles(context.Background(), f.dir, []string{"dir"}, gitops.AddOptions{Mode: gitops.AddModeUpdate})
+	require.NoError(t, err)
+	require.Equal(t, " M a.txt\n D b.txt\nM  dir/c.txt\n?? new.txt\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, nil, gitops.AddOptions{Mode: gitops.AddModeUpdate})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nD  b.txt\nM  dir/c.txt\n?? new.txt\n", f.status())
+}
+
+func testAddFilesAll(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	require.NoError(t, os.Remove(f.path("b.txt")))
+	f.write("dir/new.txt", "new\n")
+	f.write("other.txt", "other\n")
+
+	_, err := ops.AddFiles(context.Background(), f.dir, []string{"dir"}, gitops.AddOptions{Mode: gitops.AddModeAll})
+	require.NoError(t, err)
+	require.Equal(t, " M a.txt\n D b.txt\nA  dir/new.txt\n?? other.txt\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, nil, gitops.AddOptions{Mode: gitops.AddModeAll})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nD  b.txt\nA  dir/new.txt\nA  other.txt\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, nil, gitops.AddOptions{})
+	require.Error(t, err)
+}
+
+func testAddFilesIntentToAdd(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("new.txt", "new\n")
+
+	_, err := ops.AddFiles(context.Background(), f.dir, []string{"new.txt"}, gitops.AddOptions{IntentToAdd: true})
+	require.NoError(t, err)
+	require.Equal(t, " A new.txt\n", f.status())
+
+	_, err = ops.AddFiles(context.Background(), f.dir, nil, gitops.AddOptions{Mode: gitops.AddModeAll, IntentToAdd: true})
+	require.Error(t, err)
+}
+
+func testResetStaged(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "two\n")
+	f.write("b.txt", "bee\n")
+	f.git("add", ".")
+
+	_, err := ops.ResetStaged(context.Background(), f.dir)
+	require.NoError(t, err)
+	require.Equal(t, " M a.txt\n?? b.txt\n", f.status())
+	require.Equal(t, "two\n", f.read("a.txt"))
+}
+
+func testRestore(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.git("add", "a.txt")
+	f.write("a.txt", "three\n")
+	require.NoError(t, os.Remove(f.path("b.txt")))
+
+	// The working tree is restored from the index
+	_, err := ops.Restore(context.Background(), f.dir, []string{"a.txt", "b.txt"}, gitops.RestoreOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\n", f.status())
+	require.Equal(t, "two\n", f.read("a.txt"))
+	require.Equal(t, "bee\n", f.read("b.txt"))
+
+	_, err = ops.Restore(context.Background(), f.dir, []string{"untracked.txt"}, gitops.RestoreOptions{})
+	require.Error(t, err)
+}
+
+func testRestoreStaged(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.write("b.txt", "buzz\n")
+	f.write("c.txt", "sea\n")
+	f.git("add", ".")
+
+	_, err := ops.Restore(context.Background(), f.dir, []string{"a.txt", "c.txt"}, gitops.RestoreOptions{Staged: true})
+	require.NoError(t, err)
+	require.Equal(t, " M a.txt\nM  b.txt\n?? c.txt\n", f.status())
+	require.Equal(t, "two\n", f.read("a.txt"))
+
+	// Restoring both the index and the working tree discards all changes
+	_, err = ops.Restore(context.Background(), f.dir, []string{"a.txt", "b.txt"}, gitops.RestoreOptions{Staged: true, Worktree: true})
+	require.NoError(t, err)
+	require.Equal(t, "?? c.txt\n", f.status())
+	require.Equal(t, "one\n", f.read("a.txt"))
+	require.Equal(t, "bee\n", f.read("b.txt"))
+}
+
+func testRestoreStagedUnborn(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.write("a.txt", "one\n")
+	f.write("b.txt", "bee\n")
+	f.git("add", ".")
+
+	_, err := ops.Restore(context.Background(), f.dir, []string{"a.txt"}, gitops.RestoreOptions{Staged: true})
+	require.NoError(t, err)
+	require.Equal(t, "A  b.txt\n?? a.txt\n", f.status())
+
+	// There is no HEAD to restore the working tree from
+	_, err = ops.Restore(context.Background(), f.dir, []string{"b.txt"}, gitops.RestoreOptions{Staged: true, Worktree: true})
+	require.Error(t, err)
+	require.Equal(t, "A  b.txt\n?? a.txt\n", f.status())
+	require.Equal(t, "bee\n", f.read("b.txt"))
+}
+
+func testRestoreSource(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("a.txt", "two\n", "Change a")
+	f.commit("b.txt", "bee\n", "Add b")
+
+	_, err := ops.Restore(context.Background(), f.dir, []string{"a.txt"}, gitops.RestoreOptions{Source: "HEAD~2"})
+	require.NoError(t, err)
+	require.Equal(t, " M a.txt\n", f.status())
+	require.Equal(t, "one\n", f.read("a.txt"))
+
+	// Files that aren't in the source are removed
+	_, err = ops.Restore(context.Background(), f.dir, []string{"a.txt", "b.txt"}, gitops.RestoreOptions{Source: "HEAD~2", Staged: true, Worktree: true})
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nD  b.txt\n", f.status())
+
+	_, err = ops.Restore(context.Background(), f.dir, []string{"a.txt"}, gitops.RestoreOptions{Source: "missing"})
+	require.Error(t, err)
+}
+
+func testRestorePathspec(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("dir/b.txt", "bee\n", "Add b")
+	f.commit("c.go", "package c\n", "Add c")
+	f.write("a.txt", "two\n")
+	f.write("dir/b.txt", "buzz\n")
+	f.write("c.go", "package d\n")
+
+	_, err := ops.Restore(context.Background(), f.dir, []string{"*.txt"}, gitops.RestoreOptions{})
+	require.NoError(t, err)
+	require.Equal(t, " M c.go\n", f.status())
+
+	_, err = ops.Restore(context.Background(), f.dir, []string{"*.rs"}, gitops.RestoreOptions{})
+	require.Error(t, err)
+}
+
+// numberedLines returns lines "1" to "n", with line i replaced by replace[i]
+func numberedLines(n int, replace map[int]string) string {
+	var lines strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			lines.WriteString(line + "\n")
+		} else {
+			lines.WriteString(strconv.Itoa(i) + "\n")
+		}
+	}
+	return lines.String()
+}
+
+// listHunks returns the hunks of the unstaged changes, or of the staged changes
+func listHunks(t *testing.T, ops gitops.GitOperations, dir string, staged bool) []gitops.Hunk {
+	t.Helper()
+	var files []gitops.DiffFile
+	var err error
+	if staged {
+		files, err = ops.GetDiffStaged(context.Background(), dir)
+	} else {
+		files, err = ops.GetDiffUnstaged(context.Background(), dir)
+	}
+	require.NoError(t, err)
+	hunks, err := gitops.ParseHunks(files)
+	require.NoError(t, err)
+	return hunks
+}
+
+func testStageHunks(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", numberedLines(20, nil), "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", numberedLines(20, map[int]string{2: "two", 18: "eighteen"}))
+	f.write("b.txt", "buzz\n")
+
+	hunks := listHunks(t, ops, f.dir, false)
+	require.Len(t, hunks, 3)
+	require.Equal(t, "a.txt", hunks[0].Path)
+	require.Equal(t, "@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n", hunks[1].Patch)
+	require.Equal(t, 1, hunks[1].Additions)
+	require.Equal(t, 1, hunks[1].Deletions)
+
+	_, err := ops.StageHunks(context.Background(), f.dir, []string{hunks[1].ID, hunks[2].ID}, false)
+	require.NoError(t, err)
+	require.Equal(t, numberedLines(20, map[int]string{18: "eighteen"}), f.git("show", ":a.txt"))
+	require.Equal(t, "MM a.txt\nM  b.txt\n", f.status())
+
+	// The ID of the remaining hunk doesn't change
+	remaining := listHunks(t, ops, f.dir, false)
+	require.Len(t, remaining, 1)
+	require.Equal(t, hunks[0].ID, remaining[0].ID)
+
+	// Hunks that aren't in the diff anymore aren't found
+	_, err = ops.StageHunks(context.Background(), f.dir, []string{hunks[0].ID, hunks[1].ID}, false)
+	require.Error(t, err)
+	require.Equal(t, "MM a.txt\nM  b.txt\n", f.status())
+
+	_, err = ops.StageHunks(context.Background(), f.dir, []string{hunks[0].ID}, false)
+	require.NoError(t, err)
+	require.Equal(t, "M  a.txt\nM  b.txt\n", f.status())
+}
+
+func testStageHunksUnstage(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", numberedLines(20, nil), "Add a")
+	f.write("a.txt", numberedLines(22, map[int]string{1: "one", 10: "ten"}))
+	f.git("add", "a.txt")
+
+	hunks := listHunks(t, ops, f.dir, true)
+	require.Len(t, hunks, 3)
+
+	_, err := ops.StageHunks(context.Background(), f.dir, []string{hunks[0].ID, hunks[2].ID}, true)
+	require.NoError(t, err)
+	require.Equal(t, numberedLines(20, map[int]string{10: "ten"}), f.git("show", ":a.txt"))
+	require.Equal(t, numberedLines(22, map[int]string{1: "one", 10: "ten"}), f.read("a.txt"))
+
+	// The unstaged hunks can be staged again
+	unstaged := listHunks(t, ops, f.dir, false)
+	require.Len(t, unstaged, 2)
+	_, err = ops.StageHunks(context.Background(), f.dir, []string{unstaged[1].ID}, false)
+	require.NoError(t, err)
+	require.Equal(t, numberedLines(22, map[int]string{10: "ten"}), f.git("show", ":a.txt"))
+}
+
+func testStageHunksAddedAndDeleted(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\ntwo\n", "Add a")
+	require.NoError(t, os.Remove(f.path("a.txt")))
+	f.write("b.txt", "bee\n")
+	f.git("add", "b.txt")
+
+	hunks := listHunks(t, ops, f.dir, false)
+	require.Len(t, hunks, 1)
+	require.Equal(t, "D", hunks[0].Status)
+	_, err := ops.StageHunks(context.Background(), f.dir, []string{hunks[0].ID}, false)
+	require.NoError(t, err)
+	require.Equal(t, "D  a.txt\nA  b.txt\n", f.status())
+
+	// Unstaging the deletion restores the file in the index, unstaging the
+	// addition removes it
+	hunks = listHunks(t, ops, f.dir, true)
+	require.Len(t, hunks, 2)
+	_, err = ops.StageHunks(context.Background(), f.dir, []string{hunks[0].ID, hunks[1].ID}, true)
+	require.NoError(t, err)
+	require.Equal(t, " D a.txt\n?? b.txt\n", f.status())
+	require.Equal(t, "one\ntwo\n", f.git("show", ":a.txt"))
+}
+
+func testStageHunksNoNewline(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", numberedLines(10, nil)+"last", "Add a")
+	f.write("a.txt", numberedLines(10, map[int]string{1: "one"})+"last\nmore")
+
+	hunks := listHunks(t, ops, f.dir, false)
+	require.Len(t, hunks, 2)
+	_, err := ops.StageHunks(context.Background(), f.dir, []string{hunks[1].ID}, false)
+	require.NoError(t, err)
+	require.Equal(t, numberedLines(10, nil)+"last\nmore", f.git("show", ":a.txt"))
+
+	_, err = ops.StageHunks(context.Background(), f.dir, []string{hunks[1].ID}, true)
+	require.NoError(t, err)
+	require.Equal(t, numberedLines(10, nil)+"last", f.git("show", ":a.txt"))
+}
+
+func testApplyPatch(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.git("mv", "b.txt", "c.txt")
+	f.write("d.txt", "dee\n")
+	f.git("add", "--all")
+	patch := f.git("diff", "--cached", "-M")
+	f.git("reset", "-q", "--hard")
+	f.git("clean", "-q", "-f")
+
+	result, err := ops.ApplyPatch(context.Background(), f.dir, patch, gitops.ApplyOptions{Check: true})
+	require.NoError(t, err)
+	require.False(t, result.Applied)
+	require.Equal(t, []gitops.ApplyFile{
+		{PatchedFile: gitops.PatchedFile{Path: "a.txt"}, Status: gitops.ApplyClean},
+		{PatchedFile: gitops.PatchedFile{Path: "c.txt", OldPath: "b.txt"}, Status: gitops.ApplyClean},
+		{PatchedFile: gitops.PatchedFile{Path: "d.txt"}, Status: gitops.ApplyClean},
+	}, result.Files)
+	require.Empty(t, f.status())
+
+	result, err = ops.ApplyPatch(context.Background(), f.dir, patch, gitops.ApplyOptions{Cached: true})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.Equal(t, "MM a.txt\nRD b.txt -> c.txt\nAD d.txt\n?? b.txt\n", f.status())
+	f.git("reset", "-q", "--hard")
+
+	// Patches without git headers are applied to the working tree by default
+	plain := "--- a/a.txt\t2024-01-01 00:00:00\n+++ b/a.txt\t2024-01-01 00:00:00\n@@ -1 +1 @@\n-one\n+three\n"
+	result, err = ops.ApplyPatch(context.Background(), f.dir, plain, gitops.ApplyOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.Equal(t, []gitops.ApplyFile{{PatchedFile: gitops.PatchedFile{Path: "a.txt"}, Status: gitops.ApplyClean}}, result.Files)
+	require.Equal(t, "three\n", f.read("a.txt"))
+
+	_, err = ops.ApplyPatch(context.Background(), f.dir, "not a patch\n", gitops.ApplyOptions{})
+	require.Error(t, err)
+}
+
+func testApplyPatchRejected(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.write("b.txt", "buzz\n")
+	patch := f.git("diff")
+	f.git("reset", "-q", "--hard")
+	f.commit("b.txt", "bumble\n", "Change b")
+
+	// Nothing is applied unless all files apply
+	result, err := ops.ApplyPatch(context.Background(), f.dir, patch, gitops.ApplyOptions{})
+	require.NoError(t, err)
+	require.False(t, result.Applied)
+	require.Equal(t, []gitops.ApplyFile{
+		{PatchedFile: gitops.PatchedFile{Path: "a.txt"}, Status: gitops.ApplyClean},
+		{PatchedFile: gitops.PatchedFile{Path: "b.txt"}, Status: gitops.ApplyRejected, Error: "patch does not apply"},
+	}, result.Files)
+	require.Empty(t, f.status())
+}
+
+func testApplyPatchThreeWay(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	f.write("a.txt", "two\n")
+	f.write("b.txt", "buzz\n")
+	patch := f.git("diff", "--full-index")
+	f.git("reset", "-q", "--hard")
+	f.commit("b.txt", "bumble\n", "Change b")
+
+	result, err := ops.ApplyPatch(context.Background(), f.dir, patch, gitops.ApplyOptions{ThreeWay: true})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.Equal(t, gitops.ApplyClean, result.Files[0].Status)
+	require.Equal(t, gitops.ApplyConflict, result.Files[1].Status)
+	require.Equal(t, "M  a.txt\nUU b.txt\n", f.status())
+}
+
+func testCommitChanges(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	parent := f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "two\n")
+	f.write("b.txt", "unstaged\n")
+	f.git("add", "a.txt")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Change a\n\nWith a body", gitops.CommitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, "Change a\n\nWith a body", f.message("HEAD"))
+	require.Equal(t, "two\n", f.git("show", "HEAD:a.txt"))
+	require.Equal(t, "?? b.txt\n", f.status())
+}
+
+func testCommitChangesNothingStaged(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	head := f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "unstaged\n")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Nothing", gitops.CommitOptions{})
+	require.Error(t, err)
+	require.Equal(t, head, f.revParse("HEAD"))
+}
+
+func testCommitChangesIdentity(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	identities := func() string {
+		return strings.TrimSpace(f.git("log", "-1", "--format=%an <%ae>, %cn <%ce>"))
+	}
+	author := gitops.Identity{Name: "Author", Email: "author@example.com"}
+	committer := gitops.Identity{Name: "Committer", Email: "committer@example.com"}
+
+	f.write("a.txt", "two\n")
+	f.git("add", "a.txt")
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Configured", gitops.CommitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Test User <test@example.com>, Test User <test@example.com>", identities())
+
+	f.write("a.txt", "three\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Author", gitops.CommitOptions{Author: &author})
+	require.NoError(t, err)
+	require.Equal(t, "Author <author@example.com>, Test User <test@example.com>", identities())
+
+	f.write("a.txt", "four\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Committer", gitops.CommitOptions{Committer: &committer})
+	require.NoError(t, err)
+	require.Equal(t, "Committer <committer@example.com>, Committer <committer@example.com>", identities())
+
+	f.git("config", "author.name", "Configured Author")
+	f.write("a.txt", "five\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Author config", gitops.CommitOptions{Committer: &committer})
+	require.NoError(t, err)
+	require.Equal(t, "Configured Author <committer@example.com>, Committer <committer@example.com>", identities())
+}
+
+func testCommitChangesSign(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+
+	keys := t.TempDir()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(keys, "id_ed25519"), pem.EncodeToMemory(block), 0600))
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+	allowedSigners := "test@example.com " + string(ssh.MarshalAuthorizedKey(sshPublicKey))
+	require.NoError(t, os.WriteFile(filepath.Join(keys, "allowed_signers"), []byte(allowedSigners), 0644))
+
+	f.git("config", "gpg.format", "ssh")
+	f.git("config", "user.signingkey", filepath.Join(keys, "id_ed25519"))
+	f.git("config", "gpg.ssh.allowedSignersFile", filepath.Join(keys, "allowed_signers"))
+
+	f.write("a.txt", "two\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Signed", gitops.CommitOptions{Sign: true})
+	require.NoError(t, err)
+	f.git("verify-commit", "HEAD")
+
+	// commit.gpgsign signs every commit
+	f.git("config", "commit.gpgsign", "true")
+	f.write("a.txt", "three\n")
+	f.git("add", "a.txt")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Configured", gitops.CommitOptions{})
+	require.NoError(t, err)
+	f.git("verify-commit", "HEAD")
+}
+
+func testCommitChangesAmend(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	parent := f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	author := func() string {
+		return strings.TrimSpace(f.git("log", "-1", "--format=%an <%ae> %aI"))
+	}
+	original := author()
+
+	f.write("b.txt", "buzz\n")
+	f.git("add", "b.txt")
+	_, err := ops.CommitChanges(context.Background(), f.dir, "", gitops.CommitOptions{Amend: true})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, "Add b", f.message("HEAD"))
+	require.Equal(t, "buzz\n", f.git("show", "HEAD:b.txt"))
+	require.Equal(t, original, author())
+
+	// The author date is kept when changing the author
+	other := gitops.Identity{Name: "Other", Email: "other@example.com"}
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Add buzzing b", gitops.CommitOptions{Amend: true, Author: &other})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, "Add buzzing b", f.message("HEAD"))
+	require.Equal(t, strings.Replace(original, "Test User <test@example.com>", "Other <other@example.com>", 1), author())
+}
+
+func testCommitChangesAmendRoot(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "two\n")
+	f.git("add", "a.txt")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "", gitops.CommitOptions{Amend: true})
+	require.NoError(t, err)
+	require.Equal(t, "1\n", f.git("rev-list", "--count", "HEAD"))
+	require.Equal(t, "two\n", f.git("show", "HEAD:a.txt"))
+}
+
+func testCommitChangesAmendMerge(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	merge := f.mergeHistory()
+	f.git("reset", "-q", "--hard", merge)
+	parents := f.git("log", "-1", "--format=%P")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Merge the side branch", gitops.CommitOptions{Amend: true})
+	require.NoError(t, err)
+	require.Equal(t, parents, f.git("log", "-1", "--format=%P"))
+	require.Equal(t, "Merge the side branch", f.message("HEAD"))
+}
+
+func testCommitChangesAmendUnborn(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.write("a.txt", "one\n")
+	f.git("add", "a.txt")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Add a", gitops.CommitOptions{Amend: true})
+	require.Error(t, err)
+	require.False(t, f.hasRef(f.dir, "HEAD"))
+}
+
+func testCommitChangesAllowEmpty(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	parent := f.commit("a.txt", "one\n", "Add a")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Empty", gitops.CommitOptions{AllowEmpty: true})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, f.revParse("HEAD~1^{tree}"), f.revParse("HEAD^{tree}"))
+
+	// Amending doesn't allow a commit that makes no change to its parent either
+	_, err = ops.CommitChanges(context.Background(), f.dir, "Still empty", gitops.CommitOptions{Amend: true})
+	require.Error(t, err)
+	require.Equal(t, "Empty", f.message("HEAD"))
+}
+
+func testCommitChangesAll(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.commit("b.txt", "bee\n", "Add b")
+	parent := f.commit("c.txt", "sea\n", "Add c")
+	f.write("a.txt", "two\n")
+	require.NoError(t, os.Remove(f.path("b.txt")))
+	f.write("d.txt", "untracked\n")
+
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Change all", gitops.CommitOptions{All: true})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, "a.txt\nc.txt\n", f.git("ls-tree", "--name-only", "HEAD"))
+	require.Equal(t, "two\n", f.git("show", "HEAD:a.txt"))
+	require.Equal(t, "?? d.txt\n", f.status())
+
+	f.write("c.txt", "see\n")
+	_, err = ops.CommitChanges(context.Background(), f.dir, "", gitops.CommitOptions{All: true, Amend: true})
+	require.NoError(t, err)
+	require.Equal(t, parent, f.revParse("HEAD~1"))
+	require.Equal(t, "Change all", f.message("HEAD"))
+	require.Equal(t, "see\n", f.git("show", "HEAD:c.txt"))
+	require.Equal(t, "?? d.txt\n", f.status())
+}
+
+func testCommitChangesDate(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "two\n")
+	f.git("add", "a.txt")
+
+	date := time.Date(2023, 5, 6, 7, 8, 9, 0, time.FixedZone("", 2*60*60))
+	_, err := ops.CommitChanges(context.Background(), f.dir, "Change a", gitops.CommitOptions{Date: date})
+	require.NoError(t, err)
+	require.Equal(t, "2023-05-06T07:08:09+02:00\n", f.git("log", "-1", "--format=%aI"))
+	require.NotEqual(t, "2023-05-06T07:08:09+02:00\n", f.git("log", "-1", "--format=%cI"))
+}
+
+func testCommitChangesTrailers(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+
+	for _, tc := range []struct {
+		message  string
+		trailers []string
+		expected string
+	}{
+		{
+			"Subject",
+			[]string{"Signed-off-by: A <a@example.com>", "Refs: #1"},
+			"Subject\n\nSigned-off-by: A <a@example.com>\nRefs: #1",
+		},
+		{
+			"Subject\n\nBody\n\nCo-authored-by: B <b@example.com>",
+			[]string{"Co-authored-by: B <b@example.com>", "Refs: #2", "refs: #2"},
+			"Subject\n\nBody\n\nCo-authored-by: B <b@example.com>\nRefs: #2",
+		},
+		{
+			"Fix: a subject that looks like a trailer",
+			[]string{"Refs: #3"},
+			"Fix: a subject that looks like a trailer\n\nRefs: #3",
+		},
+		{
+			"Subject\n\nBody text\nNot: a trailer block\nplain\n\n",
+			[]string{"Refs: #4"},
+			"Subject\n\nBody text\nNot: a trailer block\nplain\n\nRefs: #4",
+		},
+		{
+			"Subject\n\nKey: value\n continued",
+			[]string{"Refs: #5"},
+			"Subject\n\nKey: value\n continued\nRefs: #5",
+		},
+	} {
+		_, err := ops.CommitChanges(context.Background(), f.dir, tc.message, gitops.CommitOptions{AllowEmpty: true, Trailers: tc.trailers})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, f.message("HEAD"))
+	}
+
+	// Amending without a message adds the trailers to the message of the commit
+	_, err := ops.CommitChanges(context.Background(), f.dir, "", gitops.CommitOptions{Amend: true, AllowEmpty: true, Trailers: []string{"Refs: #5", "Refs: #6"}})
+	require.NoError(t, err)
+	require.Equal(t, "Subject\n\nKey: value\n continued\nRefs: #5\nRefs: #6", f.message("HEAD"))
+}
+
+func testShowCommit(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	merge := f.mergeHistory()
+	f.git("tag", "-a", "v1", "-m", "Release 1", merge)
+
+	for _, revision := range []string{"HEAD", "HEAD~1", f.revParse("side~1"), "v1", "HEAD:", "HEAD:a.txt"} {
+		output, err := ops.ShowCommit(context.Background(), f.dir, revision)
+		require.NoError(t, err, revision)
+		require.Equal(t, f.git(gitops.ShowArgs(revision)...), output, revision)
+	}
+
+	_, err := ops.ShowCommit(context.Background(), f.dir, "missing")
+	require.Error(t, err)
+}
+
+func testInitRepo(t *testing.T, ops gitops.GitOperations) {
+	dir := filepath.Join(t.TempDir(), "new", "repo")
+
+	_, err := ops.InitRepo(context.Background(), dir)
+	require.NoError(t, err)
+	require.DirExists(t, filepath.Join(dir, ".git"))
+
+	f := &fixture{t: t, dir: dir}
+	require.Equal(t, "false", strings.TrimSpace(f.git("rev-parse", "--is-bare-repository")))
+	require.Empty(t, f.status())
+}
+
+func testStash(t *testing.T, ops gitops.GitOperations) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("a.txt", "first\n")
+
+	_, err := ops.StashPush(ctx, f.dir, "first change", false)
+	require.NoError(t, err)
+	require.Empty(t, f.status())
+	f.write("a.txt", "second\n")
+	_, err = ops.StashPush(ctx, f.dir, "", false)
+	require.NoError(t, err)
+
+	entries, err := ops.StashList(ctx, f.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 0, entries[0].Index)
+	require.Equal(t, "main", entries[0].Branch)
+	require.Equal(t, 1, entries[1].Index)
+	require.Equal(t, gitops.StashEntry{Index: 1, Branch: "main", Message: "first change"}, entries[1])
+
+	files, err := ops.StashShow(ctx, f.dir, 1)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Contains(t, files[0].Patch, "+first\n")
+
+	_, err = ops.StashApply(ctx, f.dir, 1)
+	require.NoError(t, err)
+	require.Equal(t, "first\n", f.read("a.txt"))
+	f.git("checkout", "--", "a.txt")
+
+	_, err = ops.StashPop(ctx, f.dir, 0)
+	require.NoError(t, err)
+	require.Equal(t, "second\n", f.read("a.txt"))
+
+	_, err = ops.StashDrop(ctx, f.dir, 0)
+	require.NoError(t, err)
+	entries, err = ops.StashList(ctx, f.dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	_, err = ops.StashDrop(ctx, f.dir, 0)
+	require.Error(t, err)
+}
+
+func testStashIncludeUntracked(t *testing.T, ops gitops.GitOperations) {
+	f := newFixture(t)
+	f.commit("a.txt", "one\n", "Add a")
+	f.write("untracked.txt", "new\n")
+
+	_, err := ops.StashPush(context.Background(), f.dir, "", true)
+	require.NoError(t, err)
+	require.NoFileExists(t, f.path("untracked.txt"))
+
+	_, err = ops.StashPop(context.Background(), f.dir, 0)
+	require.NoError(t, err)
+	require.Equal(t, "new\n", f.read("untracked.txt"))
+}
+
+// requireDiff checks that files describe the output of a git diff command
+func requireDiff(t *testing.T, expected string, files []gitops.DiffFile) {
+	t.Helper()
+	var patch strings.Builder
+	for _, file := range files {
+		patch.WriteString(file.Patch)
+	}
+	require.Equal(t, expected, patch.String())
+
+	expectedFiles, err := gitops.ParseDiff(expected)
+	require.NoError(t, err)
+	require.Equal(t, expectedFiles, files)
+}
+
+// diffPaths returns the paths of changed files
+func diffPaths(files []gitops.DiffFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
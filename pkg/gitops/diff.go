@@ -0,0 +1,155 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffArgs builds the arguments for a git command that prints a diff parsed by
+// ParseDiff, such as "diff" or "stash show -p", followed by revisions and paths.
+// The configuration of the user must not change the format of the diff. Hashes
+// are not abbreviated and only renames without changes are detected, which
+// makes the diff match the one rendered by FormatPatch.
+func DiffArgs(command []string, args ...string) []string {
+	diffArgs := append([]string{}, command...)
+	diffArgs = append(diffArgs, "--no-color", "--no-ext-diff", "--no-textconv", "--src-prefix=a/", "--dst-prefix=b/",
+		"--full-index", "-M100%", "--unified=3", "--inter-hunk-context=0", "--diff-algorithm=myers", "--no-relative")
+	return append(diffArgs, args...)
+}
+
+// DiffFile describes the changes to a single file
+type DiffFile struct {
+	Path      string `json:"path"`               // path after the change, or before it for deleted files
+	OldPath   string `json:"old_path,omitempty"` // path before a rename or copy
+	Status    string `json:"status"`             // A added, M modified, D deleted, R renamed or C copied
+	Binary    bool   `json:"binary"`
+	Additions int    `json:"additions"` // number of added lines
+	Deletions int    `json:"deletions"` // number of removed lines
+	Patch     string `json:"-"`         // section of the unified diff for this file, starting with "diff --git"
+}
+
+// ParseDiff splits the output of `git diff` into the changes to each file
+func ParseDiff(output string) ([]DiffFile, error) {
+	var files []DiffFile
+	var current *DiffFile
+	var patch strings.Builder
+	inHunk := false
+
+	finish := func() {
+		if current != nil {
+			current.Patch = patch.String()
+			files = append(files, *current)
+		}
+		patch.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(output, "\n") {
+		if line == "" {
+			continue
+		}
+		content := strings.TrimSuffix(line, "\n")
+
+		if strings.HasPrefix(content, "diff --git ") {
+			finish()
+			oldPath, newPath, err := parseDiffGitLine(content)
+			if err != nil {
+				return nil, err
+			}
+			current = &DiffFile{Path: newPath, OldPath: oldPath, Status: "M"}
+			inHunk = false
+			patch.WriteString(line)
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("unexpected diff line: %q", content)
+		}
+		patch.WriteString(line)
+
+		if inHunk {
+			switch {
+			case strings.HasPrefix(content, "+"):
+				current.Additions++
+			case strings.HasPrefix(content, "-"):
+				current.Deletions++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(content, "@@"):
+			inHunk = true
+		case strings.HasPrefix(content, "new file mode"):
+			current.Status = "A"
+		case strings.HasPrefix(content, "deleted file mode"):
+			current.Status = "D"
+		case strings.HasPrefix(content, "rename from "):
+			current.Status = "R"
+			current.OldPath = unquoteDiffPath(strings.TrimPrefix(content, "rename from "))
+		case strings.HasPrefix(content, "rename to "):
+			current.Path = unquoteDiffPath(strings.TrimPrefix(content, "rename to "))
+		case strings.HasPrefix(content, "copy from "):
+			current.Status = "C"
+			current.OldPath = unquoteDiffPath(strings.TrimPrefix(content, "copy from "))
+		case strings.HasPrefix(content, "copy to "):
+			current.Path = unquoteDiffPath(strings.TrimPrefix(content, "copy to "))
+		case strings.HasPrefix(content, "Binary files ") || content == "GIT binary patch":
+			current.Binary = true
+		}
+	}
+	finish()
+
+	for i := range files {
+		// The old path is only reported where it differs from the new one
+		if files[i].Status != "R" && files[i].Status != "C" {
+			files[i].OldPath = ""
+		}
+	}
+	return files, nil
+}
+
+// parseDiffGitLine extracts the old and new path from a "diff --git a/old b/new" line.
+// Both paths are the same unless the file was renamed or copied, which is
+// reported by later header lines, so only lines with equal paths need to be split exactly.
+func parseDiffGitLine(line string) (string, string, error) {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasPrefix(paths, "\"") {
+		// Quoted paths contain special characters, the new path follows the closing quote
+		for i := 1; i < len(paths); i++ {
+			if paths[i] == '\\' {
+				i++
+				continue
+			}
+			if paths[i] == '"' {
+				oldPath := unquoteDiffPath(paths[:i+1])
+				newPath := unquoteDiffPath(strings.TrimSpace(paths[i+1:]))
+				return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/"), nil
+			}
+		}
+		return "", "", fmt.Errorf("unexpected diff header: %q", line)
+	}
+
+	// "a/x b/x" is split in the middle when both paths are equal
+	if len(paths)%2 == 1 {
+		middle := len(paths) / 2
+		if paths[middle] == ' ' && strings.HasPrefix(paths, "a/") && strings.HasPrefix(paths[middle+1:], "b/") &&
+			paths[2:middle] == paths[middle+3:] {
+			return paths[2:middle], paths[middle+3:], nil
+		}
+	}
+	oldPath, newPath, found := strings.Cut(paths, " b/")
+	if !found {
+		return "", "", fmt.Errorf("unexpected diff header: %q", line)
+	}
+	return strings.TrimPrefix(oldPath, "a/"), newPath, nil
+}
+
+// unquoteDiffPath removes the quotes git adds to paths with special characters
+func unquoteDiffPath(path string) string {
+	if strings.HasPrefix(path, "\"") {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
@@ -0,0 +1,83 @@
+package gitops
+
+import "fmt"
+
+// PullStrategy selects how fetched changes are integrated into the current branch
+type PullStrategy string
+
+const (
+	// PullStrategyMerge merges the fetched branch into the current branch
+	PullStrategyMerge PullStrategy = "merge"
+	// PullStrategyRebase rebases the current branch onto the fetched branch
+	PullStrategyRebase PullStrategy = "rebase"
+)
+
+// FetchArgs builds the git fetch arguments
+func FetchArgs(remote string, refspec string, prune bool, tags bool) ([]string, error) {
+	if err := ValidateRemoteArguments(remote, refspec); err != nil {
+		return nil, err
+	}
+
+	args := []string{"fetch"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	if tags {
+		args = append(args, "--tags")
+	}
+
+	// A refspec can only be given together with a remote
+	if remote == "" && refspec != "" {
+		remote = "origin"
+	}
+	if remote != "" {
+		args = append(args, remote)
+	}
+	if refspec != "" {
+		args = append(args, refspec)
+	}
+	return args, nil
+}
+
+// ValidateRemoteArguments checks that the remote and the refspec or branch
+// fetched from it can't be mistaken by git for options
+func ValidateRemoteArguments(remote string, ref string) error {
+	if err := ValidateArgument("remote", remote); err != nil {
+		return err
+	}
+	return ValidateArgument("refspec", ref)
+}
+
+// PullArgs builds the git pull arguments. The strategy is always passed
+// explicitly so the result doesn't depend on the user's pull.rebase setting.
+func PullArgs(remote string, branch string, strategy PullStrategy, ffOnly bool) ([]string, error) {
+	if err := ValidateRemoteArguments(remote, branch); err != nil {
+		return nil, err
+	}
+
+	args := []string{"pull"}
+	switch strategy {
+	case "", PullStrategyMerge:
+		// Never open an editor for the merge commit message
+		args = append(args, "--no-rebase", "--no-edit")
+	case PullStrategyRebase:
+		args = append(args, "--rebase")
+	default:
+		return nil, fmt.Errorf("unsupported pull strategy: %s", strategy)
+	}
+	if ffOnly {
+		args = append(args, "--ff-only")
+	}
+
+	// A branch can only be given together with a remote
+	if remote == "" && branch != "" {
+		remote = "origin"
+	}
+	if remote != "" {
+		args = append(args, remote)
+	}
+	if branch != "" {
+		args = append(args, branch)
+	}
+	return args, nil
+}
@@ -0,0 +1,83 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// binaryCheckSize is the number of leading bytes inspected for NUL bytes,
+// the same heuristic git uses to detect binary files
+const binaryCheckSize = 8000
+
+// FileContent describes a file as it exists at a revision
+type FileContent struct {
+	Path       string `json:"path"`
+	Revision   string `json:"revision"`   // commit the file was read from
+	Hash       string `json:"hash"`       // blob hash
+	Size       int64  `json:"size"`       // size of the whole file in bytes
+	Binary     bool   `json:"binary"`     // binary files are described by size and hash only, without content
+	Content    string `json:"content"`    // requested lines of the file, empty for binary files
+	StartLine  int    `json:"start_line"` // first line included in Content, starting at 1
+	EndLine    int    `json:"end_line"`   // last line included in Content
+	TotalLines int    `json:"total_lines"`
+}
+
+// IsBinary reports whether content looks like binary data
+func IsBinary(content []byte) bool {
+	if len(content) > binaryCheckSize {
+		content = content[:binaryCheckSize]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// NormalizeRevisionPath converts a path to the repository-relative, slash-separated
+// form used to look up files in a tree
+func NormalizeRevisionPath(filePath string) string {
+	filePath = strings.ReplaceAll(filePath, "\\", "/")
+	for strings.HasPrefix(filePath, "./") {
+		filePath = filePath[2:]
+	}
+	return strings.Trim(filePath, "/")
+}
+
+// SetFileContent fills in the content and line range of file from the full
+// file contents, limited to the lines from startLine to endLine. A startLine
+// or endLine of 0 leaves that end of the range open.
+func SetFileContent(file *FileContent, content []byte, startLine int, endLine int) error {
+	if err := ValidateLineRange(startLine, endLine); err != nil {
+		return err
+	}
+
+	file.Size = int64(len(content))
+	if IsBinary(content) {
+		file.Binary = true
+		return nil
+	}
+
+	text := string(content)
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		// Content ended with a newline
+		lines = lines[:len(lines)-1]
+	}
+	file.TotalLines = len(lines)
+
+	file.StartLine, file.EndLine = 1, len(lines)
+	if startLine > 0 {
+		if startLine > len(lines) {
+			return fmt.Errorf("file %s has only %d lines", file.Path, len(lines))
+		}
+		file.StartLine = startLine
+	}
+	if endLine > 0 && endLine < file.EndLine {
+		file.EndLine = endLine
+	}
+
+	if file.TotalLines > 0 {
+		file.Content = strings.Join(lines[file.StartLine-1:file.EndLine], "")
+	} else {
+		file.StartLine = 0
+	}
+	return nil
+}
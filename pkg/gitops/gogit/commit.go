@@ -0,0 +1,399 @@
+package gogit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrMissingIdentity is returned when committing without a committer identity
+var ErrMissingIdentity = errors.New("committer identity unknown, set user.name and user.email in git config")
+
+// gitConfig is the git config of a repository, ordered from the narrowest scope
+// to the widest, so that the first scope setting an option takes precedence
+type gitConfig []*config.Config
+
+// loadConfig loads the config of a repository. Repositories that aren't on disk
+// only have their own config, otherwise the global and system config are
+// loaded as well.
+func (g *GoGitOperations) loadConfig(repo *git.Repository) (gitConfig, error) {
+	local, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	// Marshaling updates the raw options from the fields, which are only in sync
+	// for configs read from disk
+	if _, err := local.Marshal(); err != nil {
+		return nil, err
+	}
+	cfg := gitConfig{local}
+	if g.open != nil {
+		return cfg, nil
+	}
+
+	for _, scope := range []config.Scope{config.GlobalScope, config.SystemScope} {
+		scoped, err := config.LoadConfig(scope)
+		if err != nil {
+			return nil, err
+		}
+		cfg = append(cfg, scoped)
+	}
+	return cfg, nil
+}
+
+// option returns the value of an option, empty if no scope sets it
+func (c gitConfig) option(section string, key string) string {
+	for _, scoped := range c {
+		if scoped.Raw.HasSection(section) && scoped.Raw.Section(section).HasOption(key) {
+			return scoped.Raw.Section(section).Option(key)
+		}
+	}
+	return ""
+}
+
+// boolOption returns the value of a boolean option, false if no scope sets it
+func (c gitConfig) boolOption(section string, key string) bool {
+	switch strings.ToLower(c.option(section, key)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+// identity resolves the identity of the author or committer of a new commit
+// like git does: from the GIT_AUTHOR_* or GIT_COMMITTER_* environment variables,
+// then author.* or committer.*, then user.name and user.email
+func (c gitConfig) identity(role string) gitops.Identity {
+	resolve := func(key string) string {
+		if value := os.Getenv("GIT_" + strings.ToUpper(role+"_"+key)); value != "" {
+			return value
+		}
+		if value := c.option(role, key); value != "" {
+			return value
+		}
+		return c.option("user", key)
+	}
+	return gitops.Identity{Name: resolve("name"), Email: resolve("email")}
+}
+
+// commitOptions returns the go-git options of a new commit, with the author,
+// committer and signing key resolved from opts and the repository's config. If
+// amended is set, the commit replaces it and keeps its author by default.
+func (g *GoGitOperations) commitOptions(repo *git.Repository, opts gitops.CommitOptions, amended *object.Commit) (*git.CommitOptions, error) {
+	cfg, err := g.loadConfig(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Like `git -c user.name=... -c user.email=...`, the committer replaces the
+	// user identity from the config
+	if opts.Committer != nil {
+		user := config.NewConfig()
+		user.Raw.Section("user").SetOption("name", opts.Committer.Name).SetOption("email", opts.Committer.Email)
+		cfg = append(gitConfig{user}, cfg...)
+	}
+
+	committer := cfg.identity("committer")
+	if committer.Name == "" || committer.Email == "" {
+		return nil, ErrMissingIdentity
+	}
+	now := time.Now()
+	author := object.Signature{When: now}
+	if amended != nil {
+		author = amended.Author
+	} else {
+		identity := cfg.identity("author")
+		author.Name, author.Email = identity.Name, identity.Email
+	}
+	if opts.Author != nil {
+		author.Name, author.Email = opts.Author.Name, opts.Author.Email
+	}
+	if !opts.Date.IsZero() {
+		author.When = opts.Date
+	}
+
+	commitOpts := &git.CommitOptions{
+		Author:            &author,
+		Committer:         &object.Signature{Name: committer.Name, Email: committer.Email, When: now},
+		AllowEmptyCommits: opts.AllowEmpty,
+	}
+	if amended != nil {
+		// go-git's Amend only keeps the first parent of merges
+		commitOpts.Parents = amended.ParentHashes
+		commitOpts.Amend = len(amended.ParentHashes) == 0
+	}
+	if opts.Sign || cfg.boolOption("commit", "gpgsign") {
+		commitOpts.Signer, err = loadSigner(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key: %w", err)
+		}
+	}
+	return commitOpts, nil
+}
+
+// pendingCommit is an operation that stopped at conflicts and is concluded by
+// the next commit, like `git commit` concludes a conflicted merge by recording
+// the merged commits as parents, and a conflicted cherry-pick by keeping the
+// author of the picked commit
+type pendingCommit struct {
+	fs        billy.Filesystem
+	operation string
+	merged    []plumbing.Hash
+	picked    *object.Commit
+}
+
+// pendingStateFiles are the files of a pending operation in the git directory,
+// removed once it is committed
+var pendingStateFiles = []string{"MERGE_HEAD", "MERGE_MSG", "MERGE_MODE", "AUTO_MERGE", "CHERRY_PICK_HEAD", "REVERT_HEAD"}
+
+// readPendingCommit returns the operation pending in a repository, nil if there
+// is none. Only repositories on disk can have one, as the operations stopping
+// at conflicts run the git binary.
+func readPendingCommit(repo *git.Repository) (*pendingCommit, error) {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, nil
+	}
+	fs := storage.Filesystem()
+	merged, err := readHeadFile(fs, "MERGE_HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) > 0 {
+		return &pendingCommit{fs: fs, operation: "merge", merged: merged}, nil
+	}
+
+	picked, err := readHeadFile(fs, "CHERRY_PICK_HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if len(picked) > 0 {
+		commit, err := repo.CommitObject(picked[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the picked commit: %w", err)
+		}
+		return &pendingCommit{fs: fs, operation: "cherry-pick", picked: commit}, nil
+	}
+
+	reverted, err := readHeadFile(fs, "REVERT_HEAD")
+	if err != nil || len(reverted) == 0 {
+		return nil, err
+	}
+	return &pendingCommit{fs: fs, operation: "revert"}, nil
+}
+
+// readHeadFile reads the commits listed in a file like MERGE_HEAD, none if the
+// file doesn't exist
+func readHeadFile(fs billy.Filesystem, name string) ([]plumbing.Hash, error) {
+	content, err := util.ReadFile(fs, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	var hashes []plumbing.Hash
+	for _, line := range strings.Fields(string(content)) {
+		if !plumbing.IsHash(line) {
+			return nil, fmt.Errorf("invalid %s: %q is not a commit hash", name, line)
+		}
+		hashes = append(hashes, plumbing.NewHash(line))
+	}
+	return hashes, nil
+}
+
+// apply makes the commit conclude the pending operation. The author and date
+// in opts still take precedence over the ones of the picked commit.
+func (p *pendingCommit) apply(repo *git.Repository, commitOpts *git.CommitOptions, opts gitops.CommitOptions) error {
+	if len(p.merged) > 0 {
+		head, err := repo.Head()
+		if err != nil {
+			return err
+		}
+		commitOpts.Parents = append([]plumbing.Hash{head.Hash()}, p.merged...)
+	}
+	if p.picked != nil {
+		author := p.picked.Author
+		if opts.Author != nil {
+			author.Name, author.Email = opts.Author.Name, opts.Author.Email
+		}
+		if !opts.Date.IsZero() {
+			author.When = opts.Date
+		}
+		commitOpts.Author = &author
+	}
+	return nil
+}
+
+// finish removes the state of the pending operation once it is committed
+func (p *pendingCommit) finish() error {
+	for _, name := range pendingStateFiles {
+		if err := p.fs.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stageTracked stages the changes of tracked files, like `git commit --all`.
+// Unlike go-git's CommitOptions.All, it can be combined with amending.
+func stageTracked(wt *git.Worktree) error {
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+	for path, fileStatus := range status {
+		if fileStatus.Worktree != git.Modified && fileStatus.Worktree != git.Deleted {
+			continue
+		}
+		if _, err := wt.Add(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSigner loads the key in user.signingkey in the format selected by
+// gpg.format. go-git can't use gpg or ssh-agent, so the key must be the path of
+// an unencrypted private key file: an armored OpenPGP key, or an OpenSSH key.
+func loadSigner(cfg gitConfig) (git.Signer, error) {
+	keyPath := cfg.option("user", "signingkey")
+	if keyPath == "" {
+		return nil, errors.New("user.signingkey is not set")
+	}
+	if strings.HasPrefix(keyPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		keyPath = filepath.Join(home, keyPath[2:])
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("user.signingkey must be the path of a private key file: %w", err)
+	}
+
+	switch format := cfg.option("gpg", "format"); format {
+	case "", "openpgp":
+		return newOpenPGPSigner(key)
+	case "ssh":
+		return newSSHSigner(key)
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format %q", format)
+	}
+}
+
+// openPGPSigner signs commits with an OpenPGP key
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// newOpenPGPSigner creates a signer from the first private key of an armored key ring
+func newOpenPGPSigner(key []byte) (*openPGPSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			return nil, errors.New("OpenPGP key is encrypted")
+		}
+		return &openPGPSigner{entity: entity}, nil
+	}
+	return nil, errors.New("no OpenPGP private key found")
+}
+
+// Sign creates an armored detached signature of message
+func (s *openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, s.entity, message, nil); err != nil {
+		return nil, err
+	}
+	return signature.Bytes(), nil
+}
+
+// sshSigner signs commits with an SSH key, creating the signatures of
+// `ssh-keygen -Y sign -n git`
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+// newSSHSigner creates a signer from an OpenSSH private key
+func newSSHSigner(key []byte) (*sshSigner, error) {
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &sshSigner{signer: signer}, nil
+}
+
+// sshSignatureMagic starts both the signed data and the blob of an SSH signature
+const sshSignatureMagic = "SSHSIG"
+
+// Sign creates an armored SSH signature of message in the "git" namespace
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, message); err != nil {
+		return nil, err
+	}
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{"git", "", "sha512", hash.Sum(nil)})...)
+
+	var signature *ssh.Signature
+	var err error
+	if algorithmSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// SHA-1 RSA signatures aren't accepted by ssh-keygen
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, s.signer.PublicKey().Marshal(), "git", "", "sha512", ssh.Marshal(signature)})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var armored strings.Builder
+	armored.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+	return []byte(armored.String()), nil
+}
@@ -0,0 +1,57 @@
+package gogit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommitChangesOpenPGP signs a commit with an OpenPGP key file, which the
+// shell backend can't use without importing it into gpg
+func TestCommitChangesOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	require.NoError(t, err)
+	var privateKey, publicKey bytes.Buffer
+	w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	w, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	keyPath := filepath.Join(t.TempDir(), "key.asc")
+	require.NoError(t, os.WriteFile(keyPath, privateKey.Bytes(), 0600))
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name, cfg.User.Email = "Test User", "test@example.com"
+	cfg.Raw.Section("user").SetOption("signingkey", keyPath)
+	cfg.Raw.Section("gpg").SetOption("format", "openpgp")
+	require.NoError(t, repo.SetConfig(cfg))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644))
+
+	ops := NewGoGitOperations()
+	_, err = ops.AddFiles(context.Background(), dir, []string{"a.txt"}, gitops.AddOptions{})
+	require.NoError(t, err)
+	_, err = ops.CommitChanges(context.Background(), dir, "Signed", gitops.CommitOptions{Sign: true})
+	require.NoError(t, err)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	_, err = commit.Verify(publicKey.String())
+	require.NoError(t, err)
+}
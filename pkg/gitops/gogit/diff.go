@@ -0,0 +1,303 @@
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// mergedStage is the stage of index entries without conflicts. go-git's
+// index.Merged is the stage of the common ancestor of a conflict.
+const mergedStage index.Stage = 0
+
+// snapshotFile is a file in a version of the repository, its content is only
+// read if the file changed
+type snapshotFile struct {
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content func() ([]byte, error)
+}
+
+// snapshot is a version of the files of a repository, by path
+type snapshot map[string]snapshotFile
+
+// blobContent returns a function reading the content of a blob
+func blobContent(repo *git.Repository, hash plumbing.Hash) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		blob, err := repo.BlobObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blob %s: %w", hash, err)
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+}
+
+// treeSnapshot returns the files of a tree, nil stands for the empty tree
+func treeSnapshot(repo *git.Repository, tree *object.Tree) (snapshot, error) {
+	files := make(snapshot)
+	if tree == nil {
+		return files, nil
+	}
+	err := tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = snapshotFile{mode: f.Mode, hash: f.Hash, content: blobContent(repo, f.Hash)}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree: %w", err)
+	}
+	return files, nil
+}
+
+// indexSnapshot returns the files staged in the index. Unmerged files and
+// submodules are left out.
+func indexSnapshot(repo *git.Repository, idx *index.Index) snapshot {
+	files := make(snapshot)
+	for _, entry := range idx.Entries {
+		if entry.Stage != mergedStage || entry.Mode == filemode.Submodule || entry.IntentToAdd {
+			continue
+		}
+		files[entry.Name] = snapshotFile{mode: entry.Mode, hash: entry.Hash, content: blobContent(repo, entry.Hash)}
+	}
+	return files
+}
+
+// worktreeSnapshot returns the files of the working tree that are tracked in the
+// index. Files whose size and modification time match the index are assumed to
+// be unchanged, like git does.
+func worktreeSnapshot(repo *git.Repository, wt *git.Worktree, idx *index.Index) (snapshot, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	trustExecutableBit := cfg.Raw.Section("core").Option("filemode") != "false"
+
+	files := make(snapshot)
+	for _, entry := range idx.Entries {
+		if entry.Stage != mergedStage || entry.Mode == filemode.Submodule {
+			continue
+		}
+
+		info, err := wt.Filesystem.Lstat(entry.Name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		var content []byte
+		mode := filemode.Regular
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			mode = filemode.Symlink
+			target, err := wt.Filesystem.Readlink(entry.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read link %s: %w", entry.Name, err)
+			}
+			content = []byte(target)
+		case !trustExecutableBit && entry.Mode.IsRegular():
+			mode = entry.Mode
+		case info.Mode()&0111 != 0:
+			mode = filemode.Executable
+		}
+
+		if mode != filemode.Symlink {
+			if info.ModTime().Equal(entry.ModifiedAt) && uint32(info.Size()) == entry.Size && !entry.IntentToAdd {
+				files[entry.Name] = snapshotFile{mode: mode, hash: entry.Hash, content: blobContent(repo, entry.Hash)}
+				continue
+			}
+			content, err = readWorktreeFile(wt, entry.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		loaded := content
+		files[entry.Name] = snapshotFile{
+			mode:    mode,
+			hash:    plumbing.ComputeHash(plumbing.BlobObject, content),
+			content: func() ([]byte, error) { return loaded, nil },
+		}
+	}
+	return files, nil
+}
+
+// readWorktreeFile reads the content of a file in the working tree
+func readWorktreeFile(wt *git.Worktree, name string) ([]byte, error) {
+	file, err := wt.Filesystem.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// diffSnapshots returns the changes from one version of the files to another
+func diffSnapshots(ctx context.Context, from snapshot, to snapshot) ([]gitops.DiffFile, error) {
+	paths := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		paths = append(paths, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []gitops.FileChange
+	for _, name := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		oldFile, inFrom := from[name]
+		newFile, inTo := to[name]
+		if inFrom && inTo && oldFile.hash == newFile.hash && oldFile.mode == newFile.mode {
+			continue
+		}
+
+		var change gitops.FileChange
+		var err error
+		if inFrom {
+			if change.From, err = patchFile(name, oldFile); err != nil {
+				return nil, err
+			}
+		}
+		if inTo {
+			if change.To, err = patchFile(name, newFile); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+	return gitops.ParseDiff(gitops.FormatPatch(changes))
+}
+
+// patchFile reads a changed file for gitops.FormatPatch
+func patchFile(name string, file snapshotFile) (*gitops.PatchFile, error) {
+	content, err := file.content()
+	if err != nil {
+		return nil, err
+	}
+	return &gitops.PatchFile{Path: name, Mode: uint32(file.mode), Hash: file.hash.String(), Content: content}, nil
+}
+
+// diffTrees returns the changes between two trees, nil stands for the empty tree
+func diffTrees(ctx context.Context, repo *git.Repository, from *object.Tree, to *object.Tree) ([]gitops.DiffFile, error) {
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare trees: %w", err)
+	}
+
+	// Only the changed files are compared
+	fromFiles, toFiles := make(snapshot), make(snapshot)
+	for _, change := range changes {
+		for _, side := range []struct {
+			entry object.ChangeEntry
+			files snapshot
+		}{{change.From, fromFiles}, {change.To, toFiles}} {
+			if side.entry.Name == "" || side.entry.TreeEntry.Mode == filemode.Submodule {
+				continue
+			}
+			side.files[side.entry.Name] = snapshotFile{
+				mode:    side.entry.TreeEntry.Mode,
+				hash:    side.entry.TreeEntry.Hash,
+				content: blobContent(repo, side.entry.TreeEntry.Hash),
+			}
+		}
+	}
+	return diffSnapshots(ctx, fromFiles, toFiles)
+}
+
+// resolveCommit resolves a revision to a commit, an empty revision stands for HEAD
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", revision, err)
+	}
+	return commit, nil
+}
+
+// parentTree returns the tree of a commit's first parent, or nil for a root commit
+func parentTree(commit *object.Commit) (*object.Tree, error) {
+	if commit.NumParents() == 0 {
+		return nil, nil
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+	}
+	return parent.Tree()
+}
+
+// resolveRange resolves the revision ranges "A..B", comparing A to B, and "A...B",
+// comparing the merge base of A and B to B. ok is false if target is no range.
+func resolveRange(repo *git.Repository, target string) (from *object.Tree, to *object.Tree, ok bool, err error) {
+	separator := ".."
+	if strings.Contains(target, "...") {
+		separator = "..."
+	}
+	fromRevision, toRevision, found := strings.Cut(target, separator)
+	if !found {
+		return nil, nil, false, nil
+	}
+
+	fromCommit, err := resolveCommit(repo, fromRevision)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	toCommit, err := resolveCommit(repo, toRevision)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	if separator == "..." {
+		bases, err := fromCommit.MergeBase(toCommit)
+		if err != nil {
+			return nil, nil, true, fmt.Errorf("failed to find merge base of %s: %w", target, err)
+		}
+		if len(bases) == 0 {
+			return nil, nil, true, fmt.Errorf("no merge base of %s", target)
+		}
+		fromCommit = bases[0]
+	}
+
+	if from, err = fromCommit.Tree(); err != nil {
+		return nil, nil, true, fmt.Errorf("failed to get tree of %s: %w", fromRevision, err)
+	}
+	if to, err = toCommit.Tree(); err != nil {
+		return nil, nil, true, fmt.Errorf("failed to get tree of %s: %w", toRevision, err)
+	}
+	return from, to, true, nil
+}
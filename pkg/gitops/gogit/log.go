@@ -0,0 +1,172 @@
+package gogit
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// queuedCommit is a commit waiting to be visited by a logWalker
+type queuedCommit struct {
+	commit *object.Commit
+	seq    int
+}
+
+// commitQueue orders commits like git's default revision walk: the commit with
+// the newest committer date comes first, commits with the same date in the
+// order they were queued.
+type commitQueue struct {
+	items []queuedCommit
+	seq   int
+}
+
+func (q *commitQueue) Len() int { return len(q.items) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if !a.commit.Committer.When.Equal(b.commit.Committer.When) {
+		return a.commit.Committer.When.After(b.commit.Committer.When)
+	}
+	return a.seq < b.seq
+}
+
+func (q *commitQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *commitQueue) Push(x any) { q.items = append(q.items, x.(queuedCommit)) }
+
+func (q *commitQueue) Pop() any {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
+
+// add queues a commit
+func (q *commitQueue) add(commit *object.Commit) {
+	heap.Push(q, queuedCommit{commit: commit, seq: q.seq})
+	q.seq++
+}
+
+// next removes the next commit to visit from the queue
+func (q *commitQueue) next() *object.Commit {
+	return heap.Pop(q).(queuedCommit).commit
+}
+
+// logWalker walks the history of a commit in the order of `git log` without
+// ordering options. go-git's log iterators walk depth first instead.
+type logWalker struct {
+	repo *git.Repository
+	// matches selects the paths the history is limited to, nil if it isn't.
+	// Like git's default history simplification, commits that don't change
+	// a matching path are left out, and merges that don't change a matching
+	// path compared to one of their parents are only followed to that parent.
+	matches func(path string) bool
+}
+
+// walk calls fn with the commits reachable from hash, newest first. Walking
+// stops without an error if fn returns storer.ErrStop.
+func (w *logWalker) walk(ctx context.Context, hash plumbing.Hash, fn func(*object.Commit) error) error {
+	start, err := w.repo.CommitObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	queue := &commitQueue{}
+	queue.add(start)
+	seen := map[plumbing.Hash]bool{hash: true}
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		commit := queue.next()
+		parents, show, err := w.simplify(ctx, commit)
+		if err != nil {
+			return err
+		}
+		for _, parentHash := range parents {
+			if seen[parentHash] {
+				continue
+			}
+			seen[parentHash] = true
+			parent, err := w.repo.CommitObject(parentHash)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				// The history of a shallow clone ends here
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get commit %s: %w", parentHash, err)
+			}
+			queue.add(parent)
+		}
+
+		if !show {
+			continue
+		}
+		if err := fn(commit); err != nil {
+			if err == storer.ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// simplify returns the parents to follow from a commit and whether it is shown
+func (w *logWalker) simplify(ctx context.Context, commit *object.Commit) ([]plumbing.Hash, bool, error) {
+	if w.matches == nil {
+		return commit.ParentHashes, true, nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get tree of %s: %w", commit.Hash, err)
+	}
+	if commit.NumParents() == 0 {
+		changed, err := w.changesMatch(ctx, nil, tree)
+		return nil, changed, err
+	}
+
+	for _, parentHash := range commit.ParentHashes {
+		parent, err := w.repo.CommitObject(parentHash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get commit %s: %w", parentHash, err)
+		}
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get tree of %s: %w", parentHash, err)
+		}
+		changed, err := w.changesMatch(ctx, parentTree, tree)
+		if err != nil {
+			return nil, false, err
+		}
+		if !changed {
+			return []plumbing.Hash{parentHash}, false, nil
+		}
+	}
+	return commit.ParentHashes, true, nil
+}
+
+// changesMatch reports whether a matching path differs between two trees, nil
+// stands for the empty tree
+func (w *logWalker) changesMatch(ctx context.Context, from *object.Tree, to *object.Tree) (bool, error) {
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare trees: %w", err)
+	}
+	for _, change := range changes {
+		if w.matches(change.From.Name) || w.matches(change.To.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
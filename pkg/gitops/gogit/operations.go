@@ -1,70 +1,302 @@
 package gogit
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"time"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/geropl/git-mcp-go/pkg/gitops"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 )
 
+// ErrGitBinaryRequired is returned by operations that go-git doesn't support if
+// the repositories aren't on disk, so that the git binary can't be used instead
+var ErrGitBinaryRequired = errors.New("operation requires the git binary, which only works on repositories on disk")
+
+// Opener opens the repository at a path
+type Opener func(repoPath string) (*git.Repository, error)
+
 // GoGitOperations implements GitOperations using the go-git library
-type GoGitOperations struct{}
+type GoGitOperations struct {
+	open Opener // opens repositories that aren't on disk, nil if they are
+}
 
 // NewGoGitOperations creates a new GoGitOperations instance
 func NewGoGitOperations() *GoGitOperations {
 	return &GoGitOperations{}
 }
 
-// GetStatus returns the status of the working tree
-func (g *GoGitOperations) GetStatus(repoPath string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+// NewGoGitOperationsWithOpener creates a GoGitOperations instance for repositories
+// that aren't on disk, which are opened with open. Operations that go-git doesn't
+// support fail with ErrGitBinaryRequired, and only the repositories' own git
+// config is read. InitRepo and CloneRepo still create repositories on disk,
+// implementations storing them elsewhere replace them.
+func NewGoGitOperationsWithOpener(open Opener) *GoGitOperations {
+	return &GoGitOperations{open: open}
+}
+
+// openRepository opens the repository at repoPath
+func (g *GoGitOperations) openRepository(repoPath string) (*git.Repository, error) {
+	if g.open == nil {
+		return git.PlainOpen(repoPath)
+	}
+	return g.open(repoPath)
+}
+
+// requireGitBinary checks that the git binary can be used on the repositories
+func (g *GoGitOperations) requireGitBinary() error {
+	if g.open != nil {
+		return ErrGitBinaryRequired
+	}
+	return nil
+}
+
+// conflictError returns a *gitops.ConflictError if a git command failed with
+// conflicts, nil otherwise
+func (g *GoGitOperations) conflictError(ctx context.Context, repoPath string, operation string, err error) error {
+	if errors.Is(err, ErrGitBinaryRequired) {
+		return nil
+	}
+	return gitops.NewConflictError(ctx, repoPath, operation, err.Error())
+}
+
+// runGit runs a git command for an operation that go-git doesn't support
+func (g *GoGitOperations) runGit(ctx context.Context, repoPath string, args ...string) (string, error) {
+	if err := g.requireGitBinary(); err != nil {
+		return "", err
+	}
+	return gitops.RunGitCommand(ctx, repoPath, args...)
+}
+
+// GetStatus returns the status of each changed file
+func (g *GoGitOperations) GetStatus(ctx context.Context, repoPath string) ([]gitops.FileStatus, error) {
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
 
 	wt, err := repo.Worktree()
 	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
 	}
 
 	status, err := wt.Status()
 	if err != nil {
-		return "", fmt.Errorf("failed to get status: %w", err)
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var files []gitops.FileStatus
+	for path, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		file := gitops.FileStatus{
+			Path:     path,
+			Index:    string(fileStatus.Staging),
+			Worktree: string(fileStatus.Worktree),
+		}
+		if fileStatus.Staging == git.Renamed || fileStatus.Staging == git.Copied {
+			file.OrigPath = fileStatus.Extra
+		}
+		files = append(files, file)
+	}
+
+	// go-git doesn't detect renames
+	files, err = detectStagedRenames(repo, files)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// detectStagedRenames reports staged files that were deleted and added with the
+// same content as renamed, like git status does for exact renames
+func detectStagedRenames(repo *git.Repository, files []gitops.FileStatus) ([]gitops.FileStatus, error) {
+	var deleted, added []string
+	for _, file := range files {
+		switch file.Index {
+		case string(git.Deleted):
+			deleted = append(deleted, file.Path)
+		case string(git.Added):
+			added = append(added, file.Path)
+		}
+	}
+	if len(deleted) == 0 || len(added) == 0 {
+		return files, nil
+	}
+
+	commit, err := resolveCommit(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var changes []gitops.FileChange
+	for _, name := range deleted {
+		entry, err := tree.FindEntry(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s in HEAD: %w", name, err)
+		}
+		changes = append(changes, gitops.FileChange{From: &gitops.PatchFile{Path: name, Hash: entry.Hash.String()}})
+	}
+	for _, name := range added {
+		entry, err := idx.Entry(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s in the index: %w", name, err)
+		}
+		changes = append(changes, gitops.FileChange{To: &gitops.PatchFile{Path: name, Hash: entry.Hash.String()}})
+	}
+
+	renamedFrom := make(map[string]string)
+	sources := make(map[string]bool)
+	for _, change := range gitops.DetectExactRenames(changes) {
+		if change.From != nil && change.To != nil {
+			renamedFrom[change.To.Path] = change.From.Path
+			sources[change.From.Path] = true
+		}
 	}
 
-	return status.String(), nil
+	var result []gitops.FileStatus
+	for _, file := range files {
+		if file.Index == string(git.Deleted) && sources[file.Path] {
+			continue
+		}
+		if origPath, ok := renamedFrom[file.Path]; ok && file.Index == string(git.Added) {
+			file.Index = string(git.Renamed)
+			file.OrigPath = origPath
+		}
+		result = append(result, file)
+	}
+	return result, nil
 }
 
 // GetDiffUnstaged returns the diff of unstaged changes
-func (g *GoGitOperations) GetDiffUnstaged(repoPath string) (string, error) {
-	// go-git doesn't have a direct equivalent to git diff
-	// We'll use git command for this operation
-	return gitops.RunGitCommand(repoPath, "diff")
+func (g *GoGitOperations) GetDiffUnstaged(ctx context.Context, repoPath string) ([]gitops.DiffFile, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	worktreeFiles, err := worktreeSnapshot(repo, wt, idx)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(ctx, indexSnapshot(repo, idx), worktreeFiles)
 }
 
 // GetDiffStaged returns the diff of staged changes
-func (g *GoGitOperations) GetDiffStaged(repoPath string) (string, error) {
-	// go-git doesn't have a direct equivalent to git diff --cached
-	// We'll use git command for this operation
-	return gitops.RunGitCommand(repoPath, "diff", "--cached")
+func (g *GoGitOperations) GetDiffStaged(ctx context.Context, repoPath string) ([]gitops.DiffFile, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	// Before the first commit, everything in the index is staged
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := treeSnapshot(repo, tree)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(ctx, headFiles, indexSnapshot(repo, idx))
 }
 
 // GetDiff returns the diff between the current state and a target
-func (g *GoGitOperations) GetDiff(repoPath string, target string) (string, error) {
-	// go-git doesn't have a direct equivalent to git diff with target
-	// We'll use git command for this operation
-	return gitops.RunGitCommand(repoPath, "diff", target)
+func (g *GoGitOperations) GetDiff(ctx context.Context, repoPath string, target string) ([]gitops.DiffFile, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Revision ranges compare two commits instead of the working tree
+	from, to, isRange, err := resolveRange(repo, target)
+	if err != nil {
+		return nil, err
+	}
+	if isRange {
+		return diffTrees(ctx, repo, from, to)
+	}
+
+	commit, err := resolveCommit(repo, target)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", target, err)
+	}
+	targetFiles, err := treeSnapshot(repo, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	worktreeFiles, err := worktreeSnapshot(repo, wt, idx)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(ctx, targetFiles, worktreeFiles)
+}
+
+// runDiff runs a git command that prints a diff and parses its output
+func (g *GoGitOperations) runDiff(ctx context.Context, repoPath string, args []string) ([]gitops.DiffFile, error) {
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	return gitops.ParseDiff(output)
 }
 
 // CommitChanges commits the staged changes
-func (g *GoGitOperations) CommitChanges(repoPath string, message string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+func (g *GoGitOperations) CommitChanges(ctx context.Context, repoPath string, message string, opts gitops.CommitOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open repository: %w", err)
 	}
@@ -74,23 +306,71 @@ func (g *GoGitOperations) CommitChanges(repoPath string, message string) (string
 		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	commit, err := wt.Commit(message, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  "MCP Git Server",
-			Email: "mcp-git@example.com",
-			When:  time.Now(),
-		},
-	})
+	pending, err := readPendingCommit(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+	if pending != nil && opts.Amend {
+		return "", fmt.Errorf("failed to commit: cannot amend in the middle of a %s", pending.operation)
+	}
+
+	var amended *object.Commit
+	if opts.Amend {
+		head, err := repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("failed to commit: nothing to amend: %w", err)
+		}
+		amended, err = repo.CommitObject(head.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get commit: %w", err)
+		}
+		if message == "" {
+			message = amended.Message
+		}
+	}
+	if opts.All {
+		if err := stageTracked(wt); err != nil {
+			return "", fmt.Errorf("failed to stage changes: %w", err)
+		}
+	}
+
+	commitOpts, err := g.commitOptions(repo, opts, amended)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+	if pending != nil {
+		if err := pending.apply(repo, commitOpts, opts); err != nil {
+			return "", fmt.Errorf("failed to commit: %w", err)
+		}
+	}
+	commit, err := wt.Commit(gitops.AddTrailers(message, opts.Trailers), commitOpts)
 	if err != nil {
 		return "", fmt.Errorf("failed to commit: %w", err)
 	}
+	if pending != nil {
+		if err := pending.finish(); err != nil {
+			return "", fmt.Errorf("committed %s, but failed to conclude the %s: %w", commit.String(), pending.operation, err)
+		}
+	}
 
 	return fmt.Sprintf("Changes committed successfully with hash %s", commit.String()), nil
 }
 
 // AddFiles adds files to the staging area
-func (g *GoGitOperations) AddFiles(repoPath string, files []string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+func (g *GoGitOperations) AddFiles(ctx context.Context, repoPath string, files []string, opts gitops.AddOptions) (string, error) {
+	if err := gitops.ValidateAddOptions(files, opts); err != nil {
+		return "", err
+	}
+	if opts.IntentToAdd {
+		// go-git doesn't support intent-to-add entries, it would commit them as empty files
+		// We'll use git command for this operation
+		if _, err := g.runGit(ctx, repoPath, gitops.AddArgs(files, opts)...); err != nil {
+			return "", fmt.Errorf("failed to add files: %w", err)
+		}
+		return "Files staged successfully", nil
+	}
+
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open repository: %w", err)
 	}
@@ -100,10 +380,13 @@ func (g *GoGitOperations) AddFiles(repoPath string, files []string) (string, err
 		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	for _, file := range files {
-		_, err := wt.Add(file)
-		if err != nil {
-			return "", fmt.Errorf("failed to add file %s: %w", file, err)
+	paths, err := changedPaths(repo, wt, files, opts.Mode != gitops.AddModeUpdate)
+	if err != nil {
+		return "", fmt.Errorf("failed to add files: %w", err)
+	}
+	for _, path := range paths {
+		if _, err := wt.Add(path); err != nil {
+			return "", fmt.Errorf("failed to add file %s: %w", path, err)
 		}
 	}
 
@@ -111,165 +394,555 @@ func (g *GoGitOperations) AddFiles(repoPath string, files []string) (string, err
 }
 
 // ResetStaged unstages all staged changes
-func (g *GoGitOperations) ResetStaged(repoPath string) (string, error) {
-	// go-git doesn't have a direct equivalent to git reset
-	// We'll use git command for this operation
-	_, err := gitops.RunGitCommand(repoPath, "reset")
+func (g *GoGitOperations) ResetStaged(ctx context.Context, repoPath string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Before the first commit, nothing remains staged
+	if _, err := repo.Head(); err == plumbing.ErrReferenceNotFound {
+		if err := repo.Storer.SetIndex(&index.Index{Version: 2}); err != nil {
+			return "", fmt.Errorf("failed to reset staged changes: %w", err)
+		}
+		return "All staged changes reset", nil
+	}
+
+	wt, err := repo.Worktree()
 	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := wt.Reset(&git.ResetOptions{Mode: git.MixedReset}); err != nil {
 		return "", fmt.Errorf("failed to reset staged changes: %w", err)
 	}
 	return "All staged changes reset", nil
 }
 
-// GetLog returns the commit history
-func (g *GoGitOperations) GetLog(repoPath string, maxCount int) ([]string, error) {
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open repository: %w", err)
+// Restore restores paths in the working tree or the index
+func (g *GoGitOperations) Restore(ctx context.Context, repoPath string, paths []string, opts gitops.RestoreOptions) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no paths to restore")
 	}
+	restoreWorktree := opts.Worktree || !opts.Staged
 
-	// Get the HEAD reference
-	ref, err := repo.Head()
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		return "", fmt.Errorf("failed to open repository: %w", err)
 	}
-
-	// Get the commit object for the HEAD reference
-	commit, err := repo.CommitObject(ref.Hash())
+	wt, err := repo.Worktree()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit: %w", err)
+		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
-
-	// Create a commit iterator
-	commitIter, err := repo.Log(&git.LogOptions{From: commit.Hash})
+	idx, err := repo.Storer.Index()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit iterator: %w", err)
+		return "", fmt.Errorf("failed to read index: %w", err)
 	}
 
-	// Collect commits
-	var logs []string
-	count := 0
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		if maxCount > 0 && count >= maxCount {
-			return fmt.Errorf("stop iteration")
+	// The working tree is restored from the index by default, the index from HEAD
+	var source snapshot
+	if opts.Source == "" && !opts.Staged {
+		source = indexSnapshot(repo, idx)
+	} else {
+		var tree *object.Tree
+		if opts.Source != "" {
+			commit, err := resolveCommit(repo, opts.Source)
+			if err != nil {
+				return "", err
+			}
+			if tree, err = commit.Tree(); err != nil {
+				return "", fmt.Errorf("failed to get tree of %s: %w", opts.Source, err)
+			}
+		} else if tree, err = headTree(repo); err != nil {
+			return "", err
+		} else if tree == nil && restoreWorktree {
+			// Like git, don't remove the files before the first commit
+			return "", fmt.Errorf("failed to restore files: HEAD doesn't exist yet")
 		}
+		if source, err = treeSnapshot(repo, tree); err != nil {
+			return "", err
+		}
+	}
 
-		log := fmt.Sprintf("Commit: %s\nAuthor: %s\nDate: %s\nMessage: %s\n",
-			c.Hash.String(),
-			c.Author.String(),
-			c.Author.When.String(),
-			c.Message)
-
-		logs = append(logs, log)
-		count++
-		return nil
-	})
+	// Paths that aren't in the source are removed
+	known := make(map[string]bool)
+	for path := range source {
+		known[path] = true
+	}
+	for path := range indexSnapshot(repo, idx) {
+		known[path] = true
+	}
+	matched, err := gitops.MatchPathspecs(paths, sortedPaths(known))
+	if err != nil {
+		return "", fmt.Errorf("failed to restore files: %w", err)
+	}
 
-	if err != nil && err.Error() != "stop iteration" {
-		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	for _, path := range matched {
+		file, inSource := source[path]
+		if opts.Staged {
+			if !inSource {
+				if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+					return "", fmt.Errorf("failed to unstage %s: %w", path, err)
+				}
+			} else {
+				entry, err := idx.Entry(path)
+				if err != nil {
+					entry = idx.Add(path)
+				}
+				// Clearing the stat data makes the working tree file compare by content
+				*entry = index.Entry{Name: path, Hash: file.hash, Mode: file.mode}
+			}
+		}
+		if restoreWorktree {
+			if err := restoreWorktreeFile(wt, path, file, inSource); err != nil {
+				return "", fmt.Errorf("failed to restore %s: %w", path, err)
+			}
+		}
 	}
 
-	return logs, nil
+	if opts.Staged {
+		if err := repo.Storer.SetIndex(idx); err != nil {
+			return "", fmt.Errorf("failed to write index: %w", err)
+		}
+	}
+	return "Files restored successfully", nil
 }
 
-// CreateBranch creates a new branch
-func (g *GoGitOperations) CreateBranch(repoPath string, branchName string, baseBranch string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+// StageHunks stages hunks of the unstaged changes, or unstages hunks of the
+// staged changes, selected by the IDs returned by gitops.ParseHunks
+func (g *GoGitOperations) StageHunks(ctx context.Context, repoPath string, ids []string, unstage bool) (string, error) {
+	var files []gitops.DiffFile
+	var err error
+	if unstage {
+		files, err = g.GetDiffStaged(ctx, repoPath)
+	} else {
+		files, err = g.GetDiffUnstaged(ctx, repoPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	hunks, err := gitops.ParseHunks(files)
+	if err != nil {
+		return "", err
+	}
+	selected, err := gitops.SelectHunks(hunks, ids)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open repository: %w", err)
 	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
 
-	var baseRef *plumbing.Reference
-	if baseBranch != "" {
-		// Get the base branch reference
-		baseRef, err = repo.Reference(plumbing.NewBranchReferenceName(baseBranch), true)
-		if err != nil {
-			return "", fmt.Errorf("failed to get base branch reference: %w", err)
+	// The selected hunks are in the order of the diff, grouped by file
+	for start := 0; start < len(selected); {
+		end := start + 1
+		for end < len(selected) && selected[end].Path == selected[start].Path {
+			end++
 		}
-	} else {
-		// Use HEAD as base
-		baseRef, err = repo.Head()
-		if err != nil {
-			return "", fmt.Errorf("failed to get HEAD: %w", err)
+		if err := stageHunks(repo, idx, selected[start:end], unstage); err != nil {
+			return "", fmt.Errorf("failed to apply hunks: %w", err)
 		}
+		start = end
 	}
 
-	// Create the new branch
-	branchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), baseRef.Hash())
-	err = repo.Storer.SetReference(branchRef)
-	if err != nil {
-		return "", fmt.Errorf("failed to create branch: %w", err)
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return "", fmt.Errorf("failed to write index: %w", err)
+	}
+	if unstage {
+		return "Hunks unstaged successfully", nil
 	}
+	return "Hunks staged successfully", nil
+}
 
-	baseName := baseBranch
-	if baseName == "" {
-		if baseRef.Name().IsBranch() {
-			baseName = baseRef.Name().Short()
-		} else {
-			baseName = baseRef.Hash().String()
-		}
+// ApplyPatch applies a patch to the working tree or the index and reports
+// which files it applies to
+func (g *GoGitOperations) ApplyPatch(ctx context.Context, repoPath string, patch string, opts gitops.ApplyOptions) (*gitops.ApplyResult, error) {
+	// go-git doesn't support applying patches
+	// We'll use git command for this operation
+	if err := g.requireGitBinary(); err != nil {
+		return nil, err
 	}
+	return gitops.RunApply(ctx, repoPath, patch, opts)
+}
 
-	return fmt.Sprintf("Created branch '%s' from '%s'", branchName, baseName), nil
+// ApplyMailbox commits the patches of a mailbox, leaving the repository
+// unchanged if one of them doesn't apply
+func (g *GoGitOperations) ApplyMailbox(ctx context.Context, repoPath string, mailbox string, opts gitops.AmOptions) (string, error) {
+	// go-git doesn't support applying patches
+	// We'll use git command for this operation
+	if err := g.requireGitBinary(); err != nil {
+		return "", err
+	}
+	return gitops.RunAm(ctx, repoPath, mailbox, opts)
 }
 
-// CheckoutBranch switches to a branch
-func (g *GoGitOperations) CheckoutBranch(repoPath string, branchName string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+// GetLog returns the commit history
+func (g *GoGitOperations) GetLog(ctx context.Context, repoPath string, opts gitops.LogOptions) ([]gitops.CommitInfo, error) {
+	if err := gitops.ValidateLogOptions(opts); err != nil {
+		return nil, err
 	}
 
-	wt, err := repo.Worktree()
-	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
+	since, sinceOK := gitops.ParseLogDate(opts.Since)
+	until, untilOK := gitops.ParseLogDate(opts.Until)
+	if opts.PickaxeString != "" || opts.PickaxeRegex != "" || opts.Follow ||
+		(opts.Since != "" && !sinceOK) || (opts.Until != "" && !untilOK) ||
+		strings.Contains(opts.Revision, "...") {
+		// go-git doesn't support pickaxe search, following renames, relative dates or symmetric ranges
+		// We'll use git command for this operation
+		output, err := g.runGit(ctx, repoPath, gitops.LogArgs(opts)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get log: %w", err)
+		}
+		return gitops.ParseLog(output)
 	}
 
-	err = wt.Checkout(&git.CheckoutOptions{
-		Branch: plumbing.NewBranchReferenceName(branchName),
-	})
+	repo, err := g.openRepository(repoPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to checkout branch: %w", err)
+		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
 
-	return fmt.Sprintf("Switched to branch '%s'", branchName), nil
-}
-
-// InitRepo initializes a new Git repository
-func (g *GoGitOperations) InitRepo(repoPath string) (string, error) {
-	// Create directory if it doesn't exist
-	err := os.MkdirAll(repoPath, 0755)
-	if err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+	// Resolve the revision range; "a..b" lists the commits reachable from b but not from a
+	from, exclude := opts.Revision, ""
+	if before, after, found := strings.Cut(opts.Revision, ".."); found {
+		exclude, from = before, after
+		if exclude == "" {
+			exclude = "HEAD"
+		}
+	}
+	if from == "" {
+		from = "HEAD"
 	}
 
-	// Initialize repository
-	_, err = git.PlainInit(repoPath, false)
+	fromHash, err := repo.ResolveRevision(plumbing.Revision(from))
 	if err != nil {
-		return "", fmt.Errorf("failed to initialize repository: %w", err)
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", from, err)
 	}
 
-	gitDir := filepath.Join(repoPath, ".git")
-	return fmt.Sprintf("Initialized empty Git repository in %s", gitDir), nil
-}
+	var excluded map[plumbing.Hash]struct{}
+	if exclude != "" {
+		excludeHash, err := repo.ResolveRevision(plumbing.Revision(exclude))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve revision %s: %w", exclude, err)
+		}
+		excluded, err = reachableCommits(repo, *excludeHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history of %s: %w", exclude, err)
+		}
+	}
 
-// ShowCommit shows the contents of a commit
-func (g *GoGitOperations) ShowCommit(repoPath string, revision string) (string, error) {
-	// go-git doesn't have a direct equivalent to git show
-	// We'll use git command for this operation
-	return gitops.RunGitCommand(repoPath, "show", revision)
-}
+	walker := &logWalker{repo: repo}
+	if len(opts.Paths) > 0 {
+		var pathspecs []*regexp.Regexp
+		for _, p := range opts.Paths {
+			pathspec, err := gitops.PathspecRegexp(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", p, err)
+			}
+			pathspecs = append(pathspecs, pathspec)
+		}
+		walker.matches = func(filePath string) bool {
+			for _, pathspec := range pathspecs {
+				if pathspec.MatchString(filePath) {
+					return true
+				}
+			}
+			return false
+		}
+	}
 
-// PushChanges pushes local commits to a remote repository
-func (g *GoGitOperations) PushChanges(repoPath string, remote string, branch string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+	authorRegexp, err := compileLogPattern("author", opts.Author)
 	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+		return nil, err
 	}
-	
-	// Use "origin" as default remote if not specified
-	if remote == "" {
-		remote = "origin"
+	committerRegexp, err := compileLogPattern("committer", opts.Committer)
+	if err != nil {
+		return nil, err
+	}
+	grepRegexp, err := compileLogPattern("grep", opts.Grep)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect commits
+	var logs []gitops.CommitInfo
+	count := 0
+	skipped := 0
+	err = walker.walk(ctx, *fromHash, func(c *object.Commit) error {
+		if opts.MaxCount > 0 && count >= opts.MaxCount {
+			return storer.ErrStop
+		}
+
+		if _, ok := excluded[c.Hash]; ok {
+			return nil
+		}
+		if opts.Since != "" && c.Committer.When.Before(since) {
+			return nil
+		}
+		if opts.Until != "" && c.Committer.When.After(until) {
+			return nil
+		}
+		if authorRegexp != nil && !authorRegexp.MatchString(c.Author.String()) {
+			return nil
+		}
+		if committerRegexp != nil && !committerRegexp.MatchString(c.Committer.String()) {
+			return nil
+		}
+		if grepRegexp != nil && !grepRegexp.MatchString(c.Message) {
+			return nil
+		}
+		if opts.Merges == gitops.LogMergesOnly && c.NumParents() < 2 {
+			return nil
+		}
+		if opts.Merges == gitops.LogMergesExclude && c.NumParents() > 1 {
+			return nil
+		}
+		if skipped < opts.Skip {
+			skipped++
+			return nil
+		}
+
+		logs = append(logs, commitInfo(c))
+		count++
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	return logs, nil
+}
+
+// compileLogPattern compiles an optional log filter pattern, returning nil if it is empty
+func compileLogPattern(name string, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern %q: %w", name, pattern, err)
+	}
+	return re, nil
+}
+
+// CreateBranch creates a new branch. The base can be any revision, HEAD is used
+// if it is empty.
+func (g *GoGitOperations) CreateBranch(ctx context.Context, repoPath string, branchName string, baseBranch string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refName, err := validateBranchName(branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+	if _, err := repo.Reference(refName, false); err == nil {
+		return "", fmt.Errorf("failed to create branch: a branch named '%s' already exists", branchName)
+	}
+
+	base := baseBranch
+	if base == "" {
+		base = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve base revision %s: %w", base, err)
+	}
+
+	// Create the new branch
+	err = repo.Storer.SetReference(plumbing.NewHashReference(refName, *hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	// Like the shell backend, name the current branch if no base was given
+	baseName := baseBranch
+	if baseName == "" {
+		baseName = "HEAD"
+		if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+			baseName = head.Name().Short()
+		}
+	}
+
+	return fmt.Sprintf("Created branch '%s' from '%s'", branchName, baseName), nil
+}
+
+// CheckoutBranch switches to a branch. Like git checkout, a branch that only
+// exists on a remote is created to track it, and other revisions detach HEAD.
+func (g *GoGitOperations) CheckoutBranch(ctx context.Context, repoPath string, branchName string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	opts, remoteName, err := checkoutTarget(repo, branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to checkout branch: %w", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	commit, err := repo.CommitObject(opts.Hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+	keep, err := hasLocalChanges(wt, tree)
+	if err != nil {
+		return "", err
+	}
+	if keep {
+		// go-git can't carry local changes over to another commit
+		// We'll use git command for this operation
+		_, err := g.runGit(ctx, repoPath, "checkout", branchName)
+		if err != nil {
+			return "", fmt.Errorf("failed to checkout branch: %w", err)
+		}
+		return fmt.Sprintf("Switched to branch '%s'", branchName), nil
+	}
+
+	if remoteName != "" {
+		err = repo.Storer.SetReference(plumbing.NewHashReference(opts.Branch, opts.Hash))
+		if err != nil {
+			return "", fmt.Errorf("failed to create branch: %w", err)
+		}
+		cfg, err := repo.Config()
+		if err != nil {
+			return "", fmt.Errorf("failed to read config: %w", err)
+		}
+		cfg.Branches[branchName] = &config.Branch{Name: branchName, Remote: remoteName, Merge: opts.Branch}
+		if err := repo.SetConfig(cfg); err != nil {
+			return "", fmt.Errorf("failed to set upstream: %w", err)
+		}
+	}
+	if opts.Branch != "" {
+		// Checking out a branch by hash would detach HEAD
+		opts.Hash = plumbing.ZeroHash
+	}
+
+	err = wt.Checkout(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to checkout branch: %w", err)
+	}
+
+	return fmt.Sprintf("Switched to branch '%s'", branchName), nil
+}
+
+// checkoutTarget resolves what `git checkout name` switches to: a local branch,
+// a new branch tracking the only remote-tracking branch of that name, whose
+// remote is returned, or any other revision to detach HEAD at
+func checkoutTarget(repo *git.Repository, name string) (*git.CheckoutOptions, string, error) {
+	branch := plumbing.NewBranchReferenceName(name)
+	if ref, err := repo.Reference(branch, true); err == nil {
+		return &git.CheckoutOptions{Branch: branch, Hash: ref.Hash()}, "", nil
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read config: %w", err)
+	}
+	remotes := make([]string, 0, len(cfg.Remotes))
+	for remote := range cfg.Remotes {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+
+	var tracked *plumbing.Reference
+	var trackedRemote string
+	for _, remote := range remotes {
+		ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, name), true)
+		if err != nil {
+			continue
+		}
+		if tracked != nil {
+			return nil, "", fmt.Errorf("'%s' matches more than one remote-tracking branch", name)
+		}
+		tracked, trackedRemote = ref, remote
+	}
+	if tracked != nil {
+		return &git.CheckoutOptions{Branch: branch, Hash: tracked.Hash()}, trackedRemote, nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(name))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve revision %s: %w", name, err)
+	}
+	return &git.CheckoutOptions{Hash: *hash}, "", nil
+}
+
+// hasLocalChanges reports whether the working tree has changes that a checkout
+// of tree has to keep: changes to tracked files, and untracked files that would
+// be overwritten
+func hasLocalChanges(wt *git.Worktree, tree *object.Tree) (bool, error) {
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to get status: %w", err)
+	}
+	for name, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked {
+			if _, err := tree.FindEntry(name); err == nil {
+				return true, nil
+			}
+			continue
+		}
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InitRepo initializes a new Git repository
+func (g *GoGitOperations) InitRepo(ctx context.Context, repoPath string) (string, error) {
+	// Create directory if it doesn't exist
+	err := os.MkdirAll(repoPath, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Initialize repository
+	_, err = git.PlainInit(repoPath, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	gitDir := filepath.Join(repoPath, ".git")
+	return fmt.Sprintf("Initialized empty Git repository in %s", gitDir), nil
+}
+
+// ShowCommit shows the contents of a commit
+func (g *GoGitOperations) ShowCommit(ctx context.Context, repoPath string, revision string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	return showRevision(ctx, repo, revision)
+}
+
+// PushChanges pushes local commits to a remote repository
+func (g *GoGitOperations) PushChanges(ctx context.Context, repoPath string, remote string, branch string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	
+	// Use "origin" as default remote if not specified
+	if remote == "" {
+		remote = "origin"
 	}
 	
 	// Determine refspec based on branch
@@ -292,7 +965,7 @@ func (g *GoGitOperations) PushChanges(repoPath string, remote string, branch str
 	}
 	
 	// Push to remote
-	err = repo.Push(&git.PushOptions{
+	err = repo.PushContext(ctx, &git.PushOptions{
 		RemoteName: remote,
 		RefSpecs:   []config.RefSpec{config.RefSpec(refspec + ":" + refspec)},
 	})
@@ -306,3 +979,1413 @@ func (g *GoGitOperations) PushChanges(repoPath string, remote string, branch str
 	
 	return fmt.Sprintf("Successfully pushed to %s/%s", remote, branchName), nil
 }
+
+// StashPush saves local modifications to a new stash entry
+func (g *GoGitOperations) StashPush(ctx context.Context, repoPath string, message string, includeUntracked bool) (string, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	args := []string{"stash", "push"}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to stash changes: %w", err)
+	}
+	return output, nil
+}
+
+// StashList returns the entries of the stash list
+func (g *GoGitOperations) StashList(ctx context.Context, repoPath string) ([]gitops.StashEntry, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	output, err := g.runGit(ctx, repoPath, "stash", "list", gitops.StashListFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	return gitops.ParseStashList(output)
+}
+
+// StashShow shows the changes recorded in a stash entry as a patch
+func (g *GoGitOperations) StashShow(ctx context.Context, repoPath string, index int) ([]gitops.DiffFile, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	files, err := g.runDiff(ctx, repoPath, gitops.DiffArgs([]string{"stash", "show", "-p"}, gitops.StashRef(index)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to show stash: %w", err)
+	}
+	return files, nil
+}
+
+// StashApply applies a stash entry on top of the working tree
+func (g *GoGitOperations) StashApply(ctx context.Context, repoPath string, index int) (string, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	output, err := g.runGit(ctx, repoPath, "stash", "apply", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to apply stash: %w", err)
+	}
+	return output, nil
+}
+
+// StashPop applies a stash entry and removes it from the stash list
+func (g *GoGitOperations) StashPop(ctx context.Context, repoPath string, index int) (string, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	output, err := g.runGit(ctx, repoPath, "stash", "pop", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to pop stash: %w", err)
+	}
+	return output, nil
+}
+
+// StashDrop removes a stash entry from the stash list
+func (g *GoGitOperations) StashDrop(ctx context.Context, repoPath string, index int) (string, error) {
+	// go-git doesn't support stashing
+	// We'll use git command for this operation
+	output, err := g.runGit(ctx, repoPath, "stash", "drop", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to drop stash: %w", err)
+	}
+	return output, nil
+}
+
+// MergeBranch merges a branch into the current branch.
+// If the merge stops with conflicts, a *gitops.ConflictError is returned.
+func (g *GoGitOperations) MergeBranch(ctx context.Context, repoPath string, branch string, mode gitops.MergeMode, message string) (string, error) {
+	args, err := gitops.MergeArgs(branch, mode, message)
+	if err != nil {
+		return "", err
+	}
+	if mode == gitops.MergeModeFastForwardOnly {
+		return g.fastForward(ctx, repoPath, branch)
+	}
+
+	// go-git only supports fast-forward merges
+	// We'll use git command for this operation
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := g.conflictError(ctx, repoPath, "merge", err); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to merge: %w", err)
+	}
+	return output, nil
+}
+
+// fastForward moves the current branch to revision if it is a descendant of HEAD
+func (g *GoGitOperations) fastForward(ctx context.Context, repoPath string, revision string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	target, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", revision, err)
+	}
+
+	if head.Hash() == *target {
+		return "Already up to date.", nil
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	targetCommit, err := repo.CommitObject(*target)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit for %s: %w", revision, err)
+	}
+
+	isAncestor, err := headCommit.IsAncestor(targetCommit)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare commits: %w", err)
+	}
+	if !isAncestor {
+		return "", fmt.Errorf("failed to merge: %w", git.ErrFastForwardMergeNotPossible)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	for path, fileStatus := range status {
+		if fileStatus.Staging != git.Untracked || fileStatus.Worktree != git.Untracked {
+			return "", fmt.Errorf("failed to merge: local changes to %s would be overwritten", path)
+		}
+	}
+
+	err = wt.Reset(&git.ResetOptions{
+		Commit: *target,
+		Mode:   git.MergeReset,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fast-forward: %w", err)
+	}
+
+	return fmt.Sprintf("Fast-forward %s..%s", head.Hash().String()[:7], target.String()[:7]), nil
+}
+
+// MergeAbort aborts an in-progress merge and restores the pre-merge state
+func (g *GoGitOperations) MergeAbort(ctx context.Context, repoPath string) (string, error) {
+	// go-git doesn't track in-progress merges
+	// We'll use git command for this operation
+	_, err := g.runGit(ctx, repoPath, "merge", "--abort")
+	if err != nil {
+		return "", fmt.Errorf("failed to abort merge: %w", err)
+	}
+	return "Merge aborted", nil
+}
+
+// Rebase rebases the current branch onto upstream, or onto a different base if onto is set
+func (g *GoGitOperations) Rebase(ctx context.Context, repoPath string, upstream string, onto string, autosquash bool) (*gitops.RebaseState, error) {
+	// go-git doesn't support rebasing
+	// We'll use git command for this operation
+	if err := g.requireGitBinary(); err != nil {
+		return nil, fmt.Errorf("failed to rebase: %w", err)
+	}
+	args, err := gitops.RebaseArgs(upstream, onto, autosquash)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebase: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseContinue continues an in-progress rebase after conflicts have been resolved
+func (g *GoGitOperations) RebaseContinue(ctx context.Context, repoPath string) (*gitops.RebaseState, error) {
+	// go-git doesn't support rebasing
+	// We'll use git command for this operation
+	if err := g.requireGitBinary(); err != nil {
+		return nil, fmt.Errorf("failed to continue rebase: %w", err)
+	}
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, gitops.RebaseContinueArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to continue rebase: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseSkip skips the commit currently being applied and continues the rebase
+func (g *GoGitOperations) RebaseSkip(ctx context.Context, repoPath string) (*gitops.RebaseState, error) {
+	// go-git doesn't support rebasing
+	// We'll use git command for this operation
+	if err := g.requireGitBinary(); err != nil {
+		return nil, fmt.Errorf("failed to skip commit: %w", err)
+	}
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, "rebase", "--skip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to skip commit: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseAbort aborts an in-progress rebase and restores the original branch
+func (g *GoGitOperations) RebaseAbort(ctx context.Context, repoPath string) (string, error) {
+	// go-git doesn't support rebasing
+	// We'll use git command for this operation
+	_, err := g.runGit(ctx, repoPath, "rebase", "--abort")
+	if err != nil {
+		return "", fmt.Errorf("failed to abort rebase: %w", err)
+	}
+	return "Rebase aborted", nil
+}
+
+// CherryPick applies the changes introduced by the given revisions on top of HEAD.
+// If a pick stops with conflicts, a *gitops.ConflictError is returned.
+func (g *GoGitOperations) CherryPick(ctx context.Context, repoPath string, revisions []string, recordOrigin bool, noCommit bool) (string, error) {
+	// go-git doesn't support cherry-picking
+	// We'll use git command for this operation
+	args, err := gitops.CherryPickArgs(revisions, recordOrigin, noCommit)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := g.conflictError(ctx, repoPath, "cherry-pick", err); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to cherry-pick: %w", err)
+	}
+	return output, nil
+}
+
+// Revert records new commits that undo the changes introduced by the given revisions.
+// If a revert stops with conflicts, a *gitops.ConflictError is returned.
+func (g *GoGitOperations) Revert(ctx context.Context, repoPath string, revisions []string, noCommit bool) (string, error) {
+	// go-git doesn't support reverting commits
+	// We'll use git command for this operation
+	args, err := gitops.RevertArgs(revisions, noCommit)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := g.conflictError(ctx, repoPath, "revert", err); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to revert: %w", err)
+	}
+	return output, nil
+}
+
+// ListTags lists the tags matching pattern (all tags if empty) in the given order
+func (g *GoGitOperations) ListTags(ctx context.Context, repoPath string, pattern string, sortBy gitops.TagSort) ([]gitops.TagInfo, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []gitops.TagInfo
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if pattern != "" {
+			matched, err := path.Match(pattern, name)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if !matched {
+				return nil
+			}
+		}
+
+		tag := gitops.TagInfo{Name: name}
+
+		tagObject, err := repo.TagObject(ref.Hash())
+		switch err {
+		case nil:
+			// Annotated tag
+			tag.Annotated = true
+			tag.Target = tagObject.Target.String()
+			tag.Tagger = fmt.Sprintf("%s <%s>", tagObject.Tagger.Name, tagObject.Tagger.Email)
+			tag.Date = tagObject.Tagger.When
+			tag.Message = strings.SplitN(strings.TrimSpace(tagObject.Message), "\n", 2)[0]
+			if commit, err := tagObject.Commit(); err == nil {
+				tag.Target = commit.Hash.String()
+			}
+		case plumbing.ErrObjectNotFound:
+			// Lightweight tag
+			tag.Target = ref.Hash().String()
+			if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+				tag.Date = commit.Committer.When
+			}
+		default:
+			return fmt.Errorf("failed to get tag %s: %w", name, err)
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gitops.SortTags(tags, sortBy); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CreateTag creates a tag on revision (HEAD if empty).
+// The tag is annotated if a message is given, lightweight otherwise.
+func (g *GoGitOperations) CreateTag(ctx context.Context, repoPath string, name string, revision string, message string) (string, error) {
+	if err := gitops.ValidateArgument("tag name", name); err != nil {
+		return "", err
+	}
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", revision, err)
+	}
+
+	var opts *git.CreateTagOptions
+	kind := "lightweight"
+	if message != "" {
+		// The tagger is loaded from the git config
+		opts = &git.CreateTagOptions{Message: message}
+		kind = "annotated"
+	}
+
+	_, err = repo.CreateTag(name, *hash, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return fmt.Sprintf("Created %s tag '%s' on '%s'", kind, name, revision), nil
+}
+
+// DeleteTag deletes a tag
+func (g *GoGitOperations) DeleteTag(ctx context.Context, repoPath string, name string) (string, error) {
+	if err := gitops.ValidateArgument("tag name", name); err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	err = repo.DeleteTag(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return fmt.Sprintf("Deleted tag '%s'", name), nil
+}
+
+// PushTags pushes tags to a remote repository. All tags are pushed if none are given.
+func (g *GoGitOperations) PushTags(ctx context.Context, repoPath string, remote string, tags []string) (string, error) {
+	if err := gitops.ValidateArgument("remote", remote); err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Use "origin" as default remote if not specified
+	if remote == "" {
+		remote = "origin"
+	}
+
+	refSpecs := []config.RefSpec{"refs/tags/*:refs/tags/*"}
+	if len(tags) > 0 {
+		refSpecs = make([]config.RefSpec, 0, len(tags))
+		for _, tag := range tags {
+			// go-git silently skips refspecs that match nothing, git fails
+			if _, err := repo.Tag(tag); err != nil {
+				return "", fmt.Errorf("failed to push tags: tag '%s' not found", tag)
+			}
+			refName := plumbing.NewTagReferenceName(tag).String()
+			refSpecs = append(refSpecs, config.RefSpec(refName+":"+refName))
+		}
+	}
+
+	err = repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   refSpecs,
+	})
+	if err != nil {
+		if err == git.NoErrAlreadyUpToDate {
+			return "Everything up-to-date", nil
+		}
+		return "", fmt.Errorf("failed to push tags: %w", err)
+	}
+
+	return fmt.Sprintf("Successfully pushed tags to %s", remote), nil
+}
+
+// ListBranches lists local branches, and remote-tracking branches if includeRemote is set
+func (g *GoGitOperations) ListBranches(ctx context.Context, repoPath string, includeRemote bool) ([]gitops.BranchInfo, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// HEAD may not point to a commit yet in an empty repository
+	var headName plumbing.ReferenceName
+	if head, err := repo.Head(); err == nil {
+		headName = head.Name()
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	var branches []gitops.BranchInfo
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		// Symbolic references such as refs/remotes/origin/HEAD are skipped
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if !name.IsBranch() && !(includeRemote && name.IsRemote()) {
+			return nil
+		}
+
+		branch := gitops.BranchInfo{
+			Name:    name.Short(),
+			Remote:  name.IsRemote(),
+			Current: name == headName,
+			Commit:  ref.Hash().String(),
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			branch.LastCommitDate = commit.Committer.When
+			branch.Subject = strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+		}
+
+		if name.IsBranch() {
+			if err := fillUpstream(repo, cfg, &branch, ref.Hash()); err != nil {
+				return err
+			}
+		}
+
+		branches = append(branches, branch)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	// Match git for-each-ref ordering: local branches first, then by name
+	sort.SliceStable(branches, func(i, j int) bool {
+		if branches[i].Remote != branches[j].Remote {
+			return !branches[i].Remote
+		}
+		return branches[i].Name < branches[j].Name
+	})
+
+	return branches, nil
+}
+
+// fillUpstream sets the upstream and ahead/behind counts of a local branch from its configuration
+func fillUpstream(repo *git.Repository, cfg *config.Config, branch *gitops.BranchInfo, hash plumbing.Hash) error {
+	branchCfg, ok := cfg.Branches[branch.Name]
+	if !ok || branchCfg.Merge == "" {
+		return nil
+	}
+
+	upstreamRefName := branchCfg.Merge
+	branch.Upstream = branchCfg.Merge.Short()
+	if branchCfg.Remote != "" && branchCfg.Remote != "." {
+		upstreamRefName = plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short())
+		branch.Upstream = upstreamRefName.Short()
+	}
+
+	upstreamRef, err := repo.Reference(upstreamRefName, true)
+	if err != nil {
+		branch.UpstreamGone = true
+		return nil
+	}
+
+	branch.Ahead, branch.Behind, err = countAheadBehind(repo, hash, upstreamRef.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to compare %s with %s: %w", branch.Name, branch.Upstream, err)
+	}
+	return nil
+}
+
+// countAheadBehind counts the commits reachable from local but not from upstream (ahead)
+// and the commits reachable from upstream but not from local (behind)
+func countAheadBehind(repo *git.Repository, local plumbing.Hash, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	localCommits, err := reachableCommits(repo, local)
+	if err != nil {
+		return 0, 0, err
+	}
+	upstreamCommits, err := reachableCommits(repo, upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ahead := 0
+	for hash := range localCommits {
+		if _, ok := upstreamCommits[hash]; !ok {
+			ahead++
+		}
+	}
+	behind := 0
+	for hash := range upstreamCommits {
+		if _, ok := localCommits[hash]; !ok {
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitInfo describes a commit like gitops.ParseLog does
+func commitInfo(c *object.Commit) gitops.CommitInfo {
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, parent := range c.ParentHashes {
+		parents = append(parents, parent.String())
+	}
+	return gitops.CommitInfo{
+		Hash:           c.Hash.String(),
+		Parents:        parents,
+		Author:         c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		Date:           c.Author.When,
+		Committer:      c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommitDate:     c.Committer.When,
+		Message:        strings.TrimRight(c.Message, "\n"),
+	}
+}
+
+// reachableCommits returns the set of commits reachable from hash
+func reachableCommits(repo *git.Repository, hash plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	commitIter, err := repo.Log(&git.LogOptions{From: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[plumbing.Hash]struct{})
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		commits[c.Hash] = struct{}{}
+		return nil
+	})
+	return commits, err
+}
+
+// DeleteBranch deletes a local branch. Branches that are not fully merged are
+// only deleted if force is set.
+func (g *GoGitOperations) DeleteBranch(ctx context.Context, repoPath string, branchName string, force bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refName, err := validateBranchName(branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete branch: %w", err)
+	}
+	ref, err := repo.Reference(refName, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete branch: branch '%s' not found", branchName)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Name() == refName {
+		return "", fmt.Errorf("failed to delete branch: cannot delete the currently checked out branch '%s'", branchName)
+	}
+
+	if !force {
+		merged, err := isBranchMerged(repo, branchName, ref.Hash(), head.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to delete branch: %w", err)
+		}
+		if !merged {
+			return "", fmt.Errorf("failed to delete branch: branch '%s' is not fully merged, use force to delete it anyway", branchName)
+		}
+	}
+
+	err = repo.Storer.RemoveReference(refName)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	// Remove the branch configuration, if any
+	err = repo.DeleteBranch(branchName)
+	if err != nil && err != git.ErrBranchNotFound {
+		return "", fmt.Errorf("failed to delete branch configuration: %w", err)
+	}
+
+	return fmt.Sprintf("Deleted branch '%s'", branchName), nil
+}
+
+// isBranchMerged checks whether a branch is merged into its upstream, or into HEAD
+// if it has no upstream, mirroring `git branch -d`
+func isBranchMerged(repo *git.Repository, branchName string, branchHash plumbing.Hash, headHash plumbing.Hash) (bool, error) {
+	target := headHash
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return false, err
+	}
+	if branchCfg, ok := cfg.Branches[branchName]; ok && branchCfg.Merge != "" {
+		upstreamRefName := branchCfg.Merge
+		if branchCfg.Remote != "" && branchCfg.Remote != "." {
+			upstreamRefName = plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short())
+		}
+		if upstreamRef, err := repo.Reference(upstreamRefName, true); err == nil {
+			target = upstreamRef.Hash()
+		}
+	}
+
+	branchCommit, err := repo.CommitObject(branchHash)
+	if err != nil {
+		return false, err
+	}
+	targetCommit, err := repo.CommitObject(target)
+	if err != nil {
+		return false, err
+	}
+	return branchCommit.IsAncestor(targetCommit)
+}
+
+// validateBranchName returns the reference of a local branch, or an error if
+// the name can't be used for one
+func validateBranchName(name string) (plumbing.ReferenceName, error) {
+	if err := gitops.ValidateArgument("branch name", name); err != nil {
+		return "", err
+	}
+	refName := plumbing.NewBranchReferenceName(name)
+	if err := refName.Validate(); err != nil {
+		return "", fmt.Errorf("'%s' is not a valid branch name", name)
+	}
+	return refName, nil
+}
+
+// RenameBranch renames a local branch, moving its configuration
+func (g *GoGitOperations) RenameBranch(ctx context.Context, repoPath string, oldName string, newName string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	oldRefName, err := validateBranchName(oldName)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: %w", err)
+	}
+	newRefName, err := validateBranchName(newName)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: %w", err)
+	}
+
+	ref, err := repo.Reference(oldRefName, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: branch '%s' not found", oldName)
+	}
+	if _, err := repo.Reference(newRefName, false); err == nil {
+		return "", fmt.Errorf("failed to rename branch: a branch named '%s' already exists", newName)
+	}
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference(newRefName, ref.Hash()))
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: %w", err)
+	}
+	err = repo.Storer.RemoveReference(oldRefName)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: %w", err)
+	}
+
+	// Keep HEAD on the branch if it was checked out
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == oldRefName {
+		err = repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, newRefName))
+		if err != nil {
+			return "", fmt.Errorf("failed to update HEAD: %w", err)
+		}
+	}
+
+	// Move the branch configuration
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	if branchCfg, ok := cfg.Branches[oldName]; ok {
+		delete(cfg.Branches, oldName)
+		branchCfg.Name = newName
+		cfg.Branches[newName] = branchCfg
+		if err := repo.SetConfig(cfg); err != nil {
+			return "", fmt.Errorf("failed to update branch configuration: %w", err)
+		}
+	}
+
+	return fmt.Sprintf("Renamed branch '%s' to '%s'", oldName, newName), nil
+}
+
+// SetUpstream sets the upstream of a local branch (the current branch if empty)
+func (g *GoGitOperations) SetUpstream(ctx context.Context, repoPath string, branchName string, upstream string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if branchName == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if !head.Name().IsBranch() {
+			return "", fmt.Errorf("HEAD is not a branch")
+		}
+		branchName = head.Name().Short()
+	} else if refName, err := validateBranchName(branchName); err != nil {
+		return "", fmt.Errorf("failed to set upstream: %w", err)
+	} else if _, err := repo.Reference(refName, false); err != nil {
+		return "", fmt.Errorf("failed to set upstream: branch '%s' not found", branchName)
+	}
+	if err := gitops.ValidateArgument("upstream", upstream); err != nil {
+		return "", err
+	}
+	// The names of remote-tracking branches are valid branch names as well
+	if err := plumbing.NewBranchReferenceName(upstream).Validate(); err != nil {
+		return "", fmt.Errorf("failed to set upstream: '%s' is not a valid upstream branch", upstream)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var remoteName string
+	var merge plumbing.ReferenceName
+	if _, err := repo.Reference(plumbing.ReferenceName("refs/remotes/"+upstream), false); err == nil {
+		// Remote-tracking branch: split off the longest matching remote name
+		for name := range cfg.Remotes {
+			if strings.HasPrefix(upstream, name+"/") && len(name) > len(remoteName) {
+				remoteName = name
+			}
+		}
+		if remoteName == "" {
+			return "", fmt.Errorf("failed to set upstream: no remote configured for '%s'", upstream)
+		}
+		merge = plumbing.NewBranchReferenceName(strings.TrimPrefix(upstream, remoteName+"/"))
+	} else if _, err := repo.Reference(plumbing.NewBranchReferenceName(upstream), false); err == nil {
+		// Local branch
+		remoteName = "."
+		merge = plumbing.NewBranchReferenceName(upstream)
+	} else {
+		return "", fmt.Errorf("failed to set upstream: the requested upstream branch '%s' does not exist", upstream)
+	}
+
+	branchCfg, ok := cfg.Branches[branchName]
+	if !ok {
+		branchCfg = &config.Branch{Name: branchName}
+		cfg.Branches[branchName] = branchCfg
+	}
+	branchCfg.Remote = remoteName
+	branchCfg.Merge = merge
+
+	if err := repo.SetConfig(cfg); err != nil {
+		return "", fmt.Errorf("failed to set upstream: %w", err)
+	}
+
+	return fmt.Sprintf("Branch '%s' set up to track '%s'", branchName, upstream), nil
+}
+
+// Fetch downloads objects and refs from a remote repository
+func (g *GoGitOperations) Fetch(ctx context.Context, repoPath string, remote string, refspec string, prune bool, tags bool) (string, error) {
+	if err := gitops.ValidateRemoteArguments(remote, refspec); err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Use "origin" as default remote if not specified
+	if remote == "" {
+		remote = "origin"
+	}
+
+	opts := &git.FetchOptions{
+		RemoteName: remote,
+		Prune:      prune,
+	}
+	if tags {
+		opts.Tags = git.AllTags
+	}
+	if refspec != "" {
+		opts.RefSpecs = []config.RefSpec{fetchRefSpec(remote, refspec)}
+	}
+
+	err = repo.FetchContext(ctx, opts)
+	if err != nil {
+		if err == git.NoErrAlreadyUpToDate {
+			return "Already up to date", nil
+		}
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	return fmt.Sprintf("Successfully fetched from %s", remote), nil
+}
+
+// fetchRefSpec expands a branch name or source-only refspec into a full refspec
+// that updates the corresponding remote-tracking branch, like git does for configured remotes
+func fetchRefSpec(remote string, refspec string) config.RefSpec {
+	if strings.Contains(refspec, ":") {
+		return config.RefSpec(refspec)
+	}
+
+	branch := strings.TrimPrefix(strings.TrimPrefix(refspec, "+"), "refs/heads/")
+	if strings.HasPrefix(branch, "refs/") {
+		// Not a branch, fetch without updating a local reference
+		return config.RefSpec(refspec + ":")
+	}
+
+	return config.RefSpec(fmt.Sprintf("+%s:%s",
+		plumbing.NewBranchReferenceName(branch),
+		plumbing.NewRemoteReferenceName(remote, branch)))
+}
+
+// Pull fetches from a remote repository and integrates the changes into the current branch.
+// If the integration stops with conflicts, a *gitops.ConflictError is returned.
+func (g *GoGitOperations) Pull(ctx context.Context, repoPath string, remote string, branch string, strategy gitops.PullStrategy, ffOnly bool) (string, error) {
+	if ffOnly && (strategy == "" || strategy == gitops.PullStrategyMerge) {
+		return g.pullFastForward(ctx, repoPath, remote, branch)
+	}
+
+	// go-git only supports fast-forward pulls
+	// We'll use git command for this operation
+	args, err := gitops.PullArgs(remote, branch, strategy, ffOnly)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := g.runGit(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := g.conflictError(ctx, repoPath, "pull", err); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to pull: %w", err)
+	}
+	return output, nil
+}
+
+// pullFastForward fetches branch (the upstream of the current branch if empty)
+// and fast-forwards the current branch to it
+func (g *GoGitOperations) pullFastForward(ctx context.Context, repoPath string, remote string, branch string) (string, error) {
+	if err := gitops.ValidateRemoteArguments(remote, branch); err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is not a branch")
+	}
+
+	// Default to the configured upstream of the current branch, like git pull does
+	var mergeRef plumbing.ReferenceName
+	if branch != "" {
+		mergeRef = plumbing.NewBranchReferenceName(branch)
+	} else {
+		cfg, err := repo.Config()
+		if err != nil {
+			return "", fmt.Errorf("failed to read config: %w", err)
+		}
+		branchCfg, ok := cfg.Branches[head.Name().Short()]
+		if !ok || branchCfg.Merge == "" {
+			return "", fmt.Errorf("failed to pull: there is no tracking information for the current branch")
+		}
+		mergeRef = branchCfg.Merge
+		if remote == "" {
+			remote = branchCfg.Remote
+		}
+	}
+
+	// Use "origin" as default remote if not specified
+	if remote == "" {
+		remote = "origin"
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	err = wt.PullContext(ctx, &git.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: mergeRef,
+	})
+	if err != nil {
+		if err == git.NoErrAlreadyUpToDate {
+			return "Already up to date.", nil
+		}
+		return "", fmt.Errorf("failed to pull: %w", err)
+	}
+
+	newHead, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	return fmt.Sprintf("Fast-forward %s..%s", head.Hash().String()[:7], newHead.Hash().String()[:7]), nil
+}
+
+// CloneRepo clones a repository into targetPath
+func (g *GoGitOperations) CloneRepo(ctx context.Context, url string, targetPath string, branch string, depth int, filter string) (string, error) {
+	if filter != "" {
+		// go-git doesn't support partial clones
+		// We'll use git command for this operation
+		parentDir := filepath.Dir(targetPath)
+		err := os.MkdirAll(parentDir, 0755)
+		if err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		_, err = gitops.RunGitCommand(ctx, parentDir, gitops.CloneArgs(url, targetPath, branch, depth, filter)...)
+		if err != nil {
+			return "", fmt.Errorf("failed to clone repository: %w", err)
+		}
+		return fmt.Sprintf("Cloned %s into %s", url, targetPath), nil
+	}
+
+	opts := &git.CloneOptions{
+		URL:   url,
+		Depth: depth,
+	}
+	if branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+
+	_, statErr := os.Stat(targetPath)
+	targetExisted := statErr == nil
+
+	_, err := git.PlainCloneContext(ctx, targetPath, false, opts)
+	if err != nil {
+		// Don't leave a partial clone behind, like git clone
+		if !targetExisted {
+			os.RemoveAll(targetPath)
+		}
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	return fmt.Sprintf("Cloned %s into %s", url, targetPath), nil
+}
+
+// Blame returns the commit that last changed each line of a file, optionally
+// limited to a line range. A startLine or endLine of 0 leaves that end of the range open.
+// OriginalLineNumber is found by matching the unchanged lines of the file with
+// the file in the commit that introduced each line.
+func (g *GoGitOperations) Blame(ctx context.Context, repoPath string, filePath string, revision string, startLine int, endLine int) ([]gitops.BlameLine, error) {
+	if err := gitops.ValidateLineRange(startLine, endLine); err != nil {
+		return nil, err
+	}
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	filePath = filepath.ToSlash(filePath)
+	result, err := git.Blame(commit, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file: %w", err)
+	}
+	content, err := fileContentsAt(commit, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file: %w", err)
+	}
+
+	// Limit the result to the requested range, like git blame -L
+	first, last := 1, len(result.Lines)
+	if startLine > 0 {
+		if startLine > len(result.Lines) {
+			return nil, fmt.Errorf("failed to blame file: file %s has only %d lines", filePath, len(result.Lines))
+		}
+		first = startLine
+	}
+	if endLine > 0 && endLine < last {
+		last = endLine
+	}
+
+	summaries := make(map[plumbing.Hash]string)
+	// go-git doesn't report the line numbers in the commits that introduced the
+	// lines, so the lines are matched up with the file in those commits
+	originalLines := make(map[plumbing.Hash]map[int]int)
+	lines := make([]gitops.BlameLine, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		line := result.Lines[i-1]
+
+		summary, ok := summaries[line.Hash]
+		if !ok {
+			lineCommit, err := repo.CommitObject(line.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get commit: %w", err)
+			}
+			summary, _, _ = strings.Cut(lineCommit.Message, "\n")
+			summaries[line.Hash] = summary
+
+			original, err := fileContentsAt(lineCommit, filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to blame file: %w", err)
+			}
+			originalLines[line.Hash] = gitops.MapUnchangedLines(original, content)
+		}
+
+		lines = append(lines, gitops.BlameLine{
+			LineNumber:         i,
+			OriginalLineNumber: originalLines[line.Hash][i],
+			Commit:             line.Hash.String(),
+			Author:             line.AuthorName,
+			AuthorEmail:        line.Author,
+			Date:               line.Date,
+			Summary:            summary,
+			Content:            line.Text,
+		})
+	}
+
+	return lines, nil
+}
+
+// fileContentsAt reads a file as it exists in a commit
+func fileContentsAt(commit *object.Commit, filePath string) (string, error) {
+	file, err := commit.File(filePath)
+	if err != nil {
+		return "", err
+	}
+	return file.Contents()
+}
+
+// ReadFileAtRevision reads a file as it exists at a revision, optionally limited
+// to a line range. A startLine or endLine of 0 leaves that end of the range open.
+func (g *GoGitOperations) ReadFileAtRevision(ctx context.Context, repoPath string, revision string, filePath string, startLine int, endLine int) (*gitops.FileContent, error) {
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	filePath = gitops.NormalizeRevisionPath(filePath)
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	treeFile, err := tree.File(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s at %s: %w", filePath, revision, err)
+	}
+	contents, err := treeFile.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, revision, err)
+	}
+
+	file := &gitops.FileContent{
+		Path:     filePath,
+		Revision: commit.Hash.String(),
+		Hash:     treeFile.Hash.String(),
+	}
+	if err := gitops.SetFileContent(file, []byte(contents), startLine, endLine); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ListTree lists the entries of a tree at a revision. The contents of the directory
+// pathPrefix are listed, or the root if it is empty. Recursive listings contain
+// files but no directories.
+func (g *GoGitOperations) ListTree(ctx context.Context, repoPath string, revision string, pathPrefix string, recursive bool, pattern string) ([]gitops.TreeEntry, error) {
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	pathPrefix = gitops.NormalizeRevisionPath(pathPrefix)
+	if pathPrefix != "" {
+		tree, err = tree.Tree(pathPrefix)
+		if err == object.ErrDirectoryNotFound {
+			// Like git ls-tree, a path that isn't a directory lists nothing
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree for %s: %w", pathPrefix, err)
+		}
+	}
+
+	var entries []gitops.TreeEntry
+	if recursive {
+		// Unlike tree.Files, the walker also returns submodules
+		walker := object.NewTreeWalker(tree, true, nil)
+		defer walker.Close()
+		for {
+			name, treeEntry, err := walker.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tree: %w", err)
+			}
+			if treeEntry.Mode == filemode.Dir {
+				continue
+			}
+			entry, err := listTreeEntry(repo, path.Join(pathPrefix, name), treeEntry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		for _, treeEntry := range tree.Entries {
+			entry, err := listTreeEntry(repo, path.Join(pathPrefix, treeEntry.Name), treeEntry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return gitops.FilterTreeEntries(entries, pattern)
+}
+
+// listTreeEntry describes an entry of a tree like gitops.ParseLsTree does
+func listTreeEntry(repo *git.Repository, entryPath string, treeEntry object.TreeEntry) (gitops.TreeEntry, error) {
+	entry := gitops.TreeEntry{
+		Path: entryPath,
+		Mode: fmt.Sprintf("%06o", uint32(treeEntry.Mode)),
+		Type: "blob",
+		Hash: treeEntry.Hash.String(),
+		Size: -1,
+	}
+
+	switch treeEntry.Mode {
+	case filemode.Dir:
+		entry.Type = "tree"
+	case filemode.Submodule:
+		entry.Type = "commit"
+	default:
+		blob, err := repo.BlobObject(treeEntry.Hash)
+		if err != nil {
+			return entry, fmt.Errorf("failed to get blob for %s: %w", entry.Path, err)
+		}
+		entry.Size = blob.Size
+	}
+	return entry, nil
+}
+
+// ListFiles lists the files in the index, optionally with untracked and ignored files
+func (g *GoGitOperations) ListFiles(ctx context.Context, repoPath string, pattern string, includeUntracked bool, includeIgnored bool) ([]gitops.IndexFile, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var files []gitops.IndexFile
+	for _, entry := range index.Entries {
+		files = append(files, gitops.IndexFile{Path: entry.Name, Status: gitops.IndexFileTracked})
+	}
+
+	if includeUntracked {
+		wt, err := repo.Worktree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get worktree: %w", err)
+		}
+		status, err := wt.Status()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status: %w", err)
+		}
+
+		var untracked []string
+		for filePath, fileStatus := range status {
+			if fileStatus.Worktree == git.Untracked {
+				untracked = append(untracked, filePath)
+			}
+		}
+		sort.Strings(untracked)
+		for _, filePath := range untracked {
+			files = append(files, gitops.IndexFile{Path: filePath, Status: gitops.IndexFileUntracked})
+		}
+	}
+
+	if includeIgnored {
+		// go-git doesn't support listing ignored files
+		// We'll use git command for this operation
+		output, err := g.runGit(ctx, repoPath, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ignored files: %w", err)
+		}
+		files = append(files, gitops.ParseLsFiles(output, gitops.IndexFileIgnored)...)
+	}
+
+	return gitops.FilterIndexFiles(files, pattern)
+}
+
+// Grep searches the tracked files in the working tree, or the files at a revision
+func (g *GoGitOperations) Grep(ctx context.Context, repoPath string, opts gitops.GrepOptions) (*gitops.GrepResult, error) {
+	if err := gitops.ValidateArgument("revision", opts.Revision); err != nil {
+		return nil, err
+	}
+	if opts.Revision == "" {
+		// go-git's Worktree.Grep searches the HEAD commit instead of the working tree files
+		// We'll use git command for this operation
+		if err := g.requireGitBinary(); err != nil {
+			return nil, fmt.Errorf("failed to search: %w", err)
+		}
+		return gitops.RunGrepCommand(ctx, repoPath, opts)
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(opts.Revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", opts.Revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	pattern, err := gitops.GrepRegexp(opts)
+	if err != nil {
+		return nil, err
+	}
+	grepOpts := &git.GrepOptions{
+		Patterns:   []*regexp.Regexp{pattern},
+		CommitHash: commit.Hash,
+	}
+	for _, pathspec := range opts.Pathspecs {
+		pathspecRegexp, err := gitops.PathspecRegexp(pathspec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathspec %q: %w", pathspec, err)
+		}
+		grepOpts.PathSpecs = append(grepOpts.PathSpecs, pathspecRegexp)
+	}
+
+	results, err := repo.Grep(grepOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Group the matching lines by file, keeping the order of the files
+	var fileNames []string
+	matchLines := make(map[string][]int)
+	for _, result := range results {
+		if _, ok := matchLines[result.FileName]; !ok {
+			fileNames = append(fileNames, result.FileName)
+		}
+		matchLines[result.FileName] = append(matchLines[result.FileName], result.LineNumber)
+	}
+
+	contextLines := opts.ContextLines
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	var matches []gitops.GrepMatch
+	for _, fileName := range fileNames {
+		file, err := tree.File(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s: %w", fileName, err)
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+
+		// Like git grep -I, binary files are skipped
+		if gitops.IsBinary([]byte(contents)) {
+			continue
+		}
+
+		lines := strings.Split(contents, "\n")
+		if lines[len(lines)-1] == "" {
+			// The empty string after the final newline is not a line
+			lines = lines[:len(lines)-1]
+		}
+		var fileMatchLines []int
+		for _, n := range matchLines[fileName] {
+			if n <= len(lines) {
+				fileMatchLines = append(fileMatchLines, n)
+			}
+		}
+
+		matches = append(matches, gitops.AddGrepContext(fileName, lines, fileMatchLines, contextLines)...)
+	}
+
+	limited, truncated := gitops.LimitGrepMatches(matches, opts.MaxResults, contextLines)
+	return &gitops.GrepResult{Matches: limited, Truncated: truncated}, nil
+}
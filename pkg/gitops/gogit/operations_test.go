@@ -0,0 +1,14 @@
+package gogit
+
+import (
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/geropl/git-mcp-go/pkg/gitops/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func() gitops.GitOperations {
+		return NewGoGitOperations()
+	})
+}
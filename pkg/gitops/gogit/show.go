@@ -0,0 +1,127 @@
+package gogit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var fullHashPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// showRevision renders a revision like `git show` run with gitops.ShowArgs.
+// Revisions of the form "rev:path" show a file or directory at a commit.
+func showRevision(ctx context.Context, repo *git.Repository, revision string) (string, error) {
+	if commitRevision, filePath, found := strings.Cut(revision, ":"); found {
+		commit, err := resolveCommit(repo, commitRevision)
+		if err != nil {
+			return "", err
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree of %s: %w", commitRevision, err)
+		}
+
+		filePath = gitops.NormalizeRevisionPath(filePath)
+		if filePath == "" {
+			return showTree(revision, tree), nil
+		}
+		entry, err := tree.FindEntry(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to find %s at %s: %w", filePath, commitRevision, err)
+		}
+		return showObject(ctx, repo, revision, entry.Hash)
+	}
+
+	// Annotated tags are shown along with the object they point to
+	if ref, err := repo.Tag(revision); err == nil {
+		return showObject(ctx, repo, revision, ref.Hash())
+	}
+	if fullHashPattern.MatchString(revision) {
+		return showObject(ctx, repo, revision, plumbing.NewHash(revision))
+	}
+
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return "", err
+	}
+	return showCommit(ctx, repo, commit)
+}
+
+// showObject renders a commit, tag, tree or blob
+func showObject(ctx context.Context, repo *git.Repository, revision string, hash plumbing.Hash) (string, error) {
+	obj, err := repo.Object(plumbing.AnyObject, hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get object %s: %w", revision, err)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Commit:
+		return showCommit(ctx, repo, obj)
+	case *object.Tag:
+		target, err := showObject(ctx, repo, obj.Target.String(), obj.Target)
+		if err != nil {
+			return "", err
+		}
+		return gitops.FormatShowTag(gitops.TagObject{
+			Name:        obj.Name,
+			Tagger:      obj.Tagger.Name,
+			TaggerEmail: obj.Tagger.Email,
+			Date:        obj.Tagger.When,
+			Message:     obj.Message,
+		}, target), nil
+	case *object.Tree:
+		return showTree(revision, obj), nil
+	case *object.Blob:
+		content, err := blobContent(repo, obj.Hash)()
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	default:
+		return "", fmt.Errorf("unsupported object %s", revision)
+	}
+}
+
+// showCommit renders a commit with the changes to its first parent
+func showCommit(ctx context.Context, repo *git.Repository, commit *object.Commit) (string, error) {
+	from, err := parentTree(commit)
+	if err != nil {
+		return "", err
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree of %s: %w", commit.Hash, err)
+	}
+
+	files, err := diffTrees(ctx, repo, from, to)
+	if err != nil {
+		return "", err
+	}
+	var patch strings.Builder
+	for _, file := range files {
+		patch.WriteString(file.Patch)
+	}
+	return gitops.FormatShowCommit(commitInfo(commit), patch.String()), nil
+}
+
+// showTree renders the names of the entries of a tree, in the order of git
+func showTree(revision string, tree *object.Tree) string {
+	names := make([]string, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		name := entry.Name
+		if entry.Mode == filemode.Dir {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return gitops.FormatShowTree(revision, names)
+}
@@ -0,0 +1,165 @@
+package gogit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// headTree returns the tree of HEAD, nil before the first commit
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+	}
+	return tree, nil
+}
+
+// changedPaths returns the changed files matching the pathspecs, all changed
+// files if there are none. New files are only included if untracked is set.
+// Like `git add`, it fails if a pathspec matches neither a tracked nor a new file.
+func changedPaths(repo *git.Repository, wt *git.Worktree, pathspecs []string, untracked bool) ([]string, error) {
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, entry := range idx.Entries {
+		known[entry.Name] = true
+	}
+	for path := range status {
+		known[path] = true
+	}
+	paths := sortedPaths(known)
+	if len(pathspecs) > 0 {
+		if paths, err = gitops.MatchPathspecs(pathspecs, paths); err != nil {
+			return nil, err
+		}
+	}
+
+	var changed []string
+	for _, path := range paths {
+		fileStatus, ok := status[path]
+		if !ok || fileStatus.Worktree == git.Unmodified || (fileStatus.Worktree == git.Untracked && !untracked) {
+			continue
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
+
+// restoreWorktreeFile replaces a file in the working tree with a file of a
+// snapshot, or removes it if it isn't in the snapshot
+func restoreWorktreeFile(wt *git.Worktree, path string, file snapshotFile, exists bool) error {
+	if err := wt.Filesystem.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	content, err := file.content()
+	if err != nil {
+		return err
+	}
+	switch file.mode {
+	case filemode.Symlink:
+		return wt.Filesystem.Symlink(string(content), path)
+	case filemode.Executable:
+		return util.WriteFile(wt.Filesystem, path, content, 0755)
+	default:
+		return util.WriteFile(wt.Filesystem, path, content, 0644)
+	}
+}
+
+// stageHunks applies hunks of the changes to a single file to its entry in the
+// index, or the reverse of the hunks if reverse is set. Like `git apply --cached`,
+// the entry is removed once all lines of a deleted file are.
+func stageHunks(repo *git.Repository, idx *index.Index, hunks []gitops.Hunk, reverse bool) error {
+	path := hunks[0].Path
+	var content []byte
+	mode := filemode.FileMode(hunks[0].FileMode)
+	entry, err := idx.Entry(path)
+	if err == nil && !entry.IntentToAdd {
+		if content, err = blobContent(repo, entry.Hash)(); err != nil {
+			return err
+		}
+		mode = entry.Mode
+	}
+
+	patched, err := gitops.ApplyHunks(content, hunks, reverse)
+	if err != nil {
+		return err
+	}
+	status := hunks[0].Status
+	if len(patched) == 0 && ((status == "D" && !reverse) || (status == "A" && reverse)) {
+		if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+			return err
+		}
+		return nil
+	}
+
+	hash, err := writeBlob(repo, patched)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		entry = idx.Add(path)
+	}
+	// Clearing the stat data makes the working tree file compare by content
+	*entry = index.Entry{Name: path, Hash: hash, Mode: mode}
+	return nil
+}
+
+// writeBlob stores content as a blob and returns its hash
+func writeBlob(repo *git.Repository, content []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	writer, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// sortedPaths returns the paths of a set in order
+func sortedPaths(set map[string]bool) []string {
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,231 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GrepOptions selects what Grep searches for and where
+type GrepOptions struct {
+	Pattern      string   // extended regular expression, or literal text if FixedStrings is set
+	FixedStrings bool     // match Pattern literally
+	IgnoreCase   bool     // match case-insensitively
+	Pathspecs    []string // limit the search to paths matching these pathspecs (directory prefixes or globs)
+	Revision     string   // search the files at this revision instead of the working tree
+	ContextLines int      // number of lines to include before and after each match
+	MaxResults   int      // maximum number of matching lines, 0 for no limit
+}
+
+// GrepMatch is a matching line, or a context line around a match
+type GrepMatch struct {
+	Path       string `json:"path"`
+	LineNumber int    `json:"line_number"`
+	Text       string `json:"text"`
+	Context    bool   `json:"context"` // whether this is a context line rather than a match
+}
+
+// GrepResult is the result of a search
+type GrepResult struct {
+	Matches   []GrepMatch `json:"matches"`
+	Truncated bool        `json:"truncated"` // whether matches were dropped to honor MaxResults
+}
+
+// GrepArgs builds the arguments for `git grep`, parsed by ParseGrepOutput.
+// Binary files are skipped.
+func GrepArgs(opts GrepOptions, contextLines int) []string {
+	args := []string{"grep", "-n", "-z", "-I"}
+	if opts.FixedStrings {
+		args = append(args, "-F")
+	} else {
+		args = append(args, "-E")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if contextLines > 0 {
+		args = append(args, "-C", strconv.Itoa(contextLines))
+	}
+	args = append(args, "-e", opts.Pattern)
+	if opts.Revision != "" {
+		args = append(args, opts.Revision)
+	}
+	args = append(args, "--")
+	return append(args, opts.Pathspecs...)
+}
+
+// ParseGrepOutput parses the output of `git grep -n -z`. All lines are reported
+// as matches, since -z output doesn't distinguish context lines.
+func ParseGrepOutput(output string, revision string) ([]GrepMatch, error) {
+	var matches []GrepMatch
+	for _, line := range strings.Split(output, "\n") {
+		// Empty lines and separators between groups of context lines
+		if line == "" || line == "--" {
+			continue
+		}
+
+		// <path> NUL <line number> NUL <text>
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected grep line: %q", line)
+		}
+		lineNumber, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected grep line: %q", line)
+		}
+
+		// Paths are prefixed with the revision they were found at
+		path := fields[0]
+		if revision != "" {
+			path = strings.TrimPrefix(path, revision+":")
+		}
+
+		matches = append(matches, GrepMatch{
+			Path:       path,
+			LineNumber: lineNumber,
+			Text:       fields[2],
+		})
+	}
+	return matches, nil
+}
+
+// RunGrepCommand searches with `git grep`, marking context lines and limiting the
+// number of matches as requested
+func RunGrepCommand(ctx context.Context, repoPath string, opts GrepOptions) (*GrepResult, error) {
+	if err := ValidateArgument("revision", opts.Revision); err != nil {
+		return nil, err
+	}
+
+	matches, err := runGrep(ctx, repoPath, opts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ContextLines > 0 && len(matches) > 0 {
+		// Context lines can't be told apart from matches in -z output, so
+		// search again with context and mark the lines that didn't match
+		isMatch := make(map[string]bool)
+		for _, match := range matches {
+			isMatch[match.Path+"\x00"+strconv.Itoa(match.LineNumber)] = true
+		}
+
+		matches, err = runGrep(ctx, repoPath, opts, opts.ContextLines)
+		if err != nil {
+			return nil, err
+		}
+		for i := range matches {
+			matches[i].Context = !isMatch[matches[i].Path+"\x00"+strconv.Itoa(matches[i].LineNumber)]
+		}
+	}
+
+	limited, truncated := LimitGrepMatches(matches, opts.MaxResults, opts.ContextLines)
+	return &GrepResult{Matches: limited, Truncated: truncated}, nil
+}
+
+// runGrep runs `git grep`, treating "no matches" as an empty result
+func runGrep(ctx context.Context, repoPath string, opts GrepOptions, contextLines int) ([]GrepMatch, error) {
+	output, err := RunGitCommand(ctx, repoPath, GrepArgs(opts, contextLines)...)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return ParseGrepOutput(output, opts.Revision)
+}
+
+// GrepRegexp compiles the search pattern of opts for use with Go's regexp package
+func GrepRegexp(opts GrepOptions) (*regexp.Regexp, error) {
+	pattern := opts.Pattern
+	if opts.FixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", opts.Pattern, err)
+	}
+	return re, nil
+}
+
+// AddGrepContext returns the matching lines of a file together with up to
+// contextLines lines of context around each of them. matchLines are the
+// line numbers of the matches in ascending order, starting at 1.
+func AddGrepContext(path string, lines []string, matchLines []int, contextLines int) []GrepMatch {
+	isMatch := make(map[int]bool, len(matchLines))
+	for _, n := range matchLines {
+		isMatch[n] = true
+	}
+
+	var result []GrepMatch
+	next := 1 // first line that hasn't been added yet
+	for _, n := range matchLines {
+		start := n - contextLines
+		if start < next {
+			start = next
+		}
+		end := n + contextLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		for i := start; i <= end; i++ {
+			result = append(result, GrepMatch{
+				Path:       path,
+				LineNumber: i,
+				Text:       lines[i-1],
+				Context:    !isMatch[i],
+			})
+		}
+		if end+1 > next {
+			next = end + 1
+		}
+	}
+	return result
+}
+
+// LimitGrepMatches keeps the first maxResults matching lines along with their
+// context lines. It reports whether any matches were dropped.
+func LimitGrepMatches(matches []GrepMatch, maxResults int, contextLines int) ([]GrepMatch, bool) {
+	if maxResults <= 0 {
+		return matches, false
+	}
+
+	count := 0
+	for i, match := range matches {
+		if match.Context {
+			continue
+		}
+		count++
+		if count <= maxResults {
+			continue
+		}
+
+		// Keep the trailing context of the last kept match, but not the
+		// leading context of the first dropped one
+		kept := matches[:i]
+		var last GrepMatch
+		for _, m := range kept {
+			if !m.Context {
+				last = m
+			}
+		}
+		for {
+			tail := kept[len(kept)-1]
+			if !tail.Context || (tail.Path == last.Path && tail.LineNumber <= last.LineNumber+contextLines) {
+				break
+			}
+			kept = kept[:len(kept)-1]
+		}
+		return kept, true
+	}
+	return matches, false
+}
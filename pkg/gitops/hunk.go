@@ -0,0 +1,256 @@
+package gitops
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkIDLength is the number of hex digits of a hunk ID
+const hunkIDLength = 12
+
+// Hunk is a hunk of the changes to a file in a diff parsed by ParseDiff
+type Hunk struct {
+	// ID identifies the hunk by its path and lines. It doesn't depend on the
+	// position of the hunk, so it stays the same while other hunks are staged.
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`    // status of the file, see DiffFile
+	OldStart  int    `json:"old_start"` // first line of the old side, see OldLines
+	OldLines  int    `json:"old_lines"` // number of lines of the old side. If 0, OldStart is the line before the hunk.
+	NewStart  int    `json:"new_start"`
+	NewLines  int    `json:"new_lines"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Patch     string `json:"patch"` // the hunk starting with its "@@" line
+
+	FileHeader string `json:"-"` // lines of the file's patch before the first hunk
+	FileMode   uint32 `json:"-"` // mode of an added or deleted file
+}
+
+// hunkHeaderPattern matches the "@@ -start,count +start,count @@" line of a hunk
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks splits the patches of changed files into hunks. Binary files and
+// files whose mode or name changed without changes to their content have none.
+func ParseHunks(files []DiffFile) ([]Hunk, error) {
+	var hunks []Hunk
+	for _, file := range files {
+		if file.Binary {
+			continue
+		}
+
+		var header strings.Builder
+		var fileHunks []Hunk
+		var fileMode uint32
+		for _, line := range splitLines(file.Patch) {
+			content := strings.TrimSuffix(line, "\n")
+			if !strings.HasPrefix(content, "@@") {
+				if len(fileHunks) > 0 {
+					fileHunks[len(fileHunks)-1].Patch += line
+					continue
+				}
+				header.WriteString(line)
+				for _, prefix := range []string{"new file mode ", "deleted file mode "} {
+					if mode, found := strings.CutPrefix(content, prefix); found {
+						parsed, err := strconv.ParseUint(mode, 8, 32)
+						if err != nil {
+							return nil, fmt.Errorf("unexpected diff line: %q", content)
+						}
+						fileMode = uint32(parsed)
+					}
+				}
+				continue
+			}
+
+			match := hunkHeaderPattern.FindStringSubmatch(content)
+			if match == nil {
+				return nil, fmt.Errorf("unexpected hunk header: %q", content)
+			}
+			hunk := Hunk{Path: file.Path, Status: file.Status, Patch: line}
+			hunk.OldStart, hunk.OldLines = parseHunkRange(match[1], match[2])
+			hunk.NewStart, hunk.NewLines = parseHunkRange(match[3], match[4])
+			fileHunks = append(fileHunks, hunk)
+		}
+
+		// Hunks with the same lines are told apart by their order
+		seen := make(map[string]int)
+		for i := range fileHunks {
+			hunk := &fileHunks[i]
+			hunk.FileHeader = header.String()
+			hunk.FileMode = fileMode
+			_, body, _ := strings.Cut(hunk.Patch, "\n")
+			for _, line := range splitLines(body) {
+				switch line[0] {
+				case '+':
+					hunk.Additions++
+				case '-':
+					hunk.Deletions++
+				}
+			}
+			hash := sha1.Sum([]byte(fmt.Sprintf("%s\x00%d\x00%s", hunk.Path, seen[body], body)))
+			hunk.ID = hex.EncodeToString(hash[:])[:hunkIDLength]
+			seen[body]++
+		}
+		hunks = append(hunks, fileHunks...)
+	}
+	return hunks, nil
+}
+
+// parseHunkRange parses the start and count of one side of a hunk header,
+// where a missing count stands for one line
+func parseHunkRange(start string, count string) (int, int) {
+	parsedStart, _ := strconv.Atoi(start)
+	parsedCount := 1
+	if count != "" {
+		parsedCount, _ = strconv.Atoi(count)
+	}
+	return parsedStart, parsedCount
+}
+
+// SelectHunks returns the hunks with the given IDs in the order of the diff
+func SelectHunks(hunks []Hunk, ids []string) ([]Hunk, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no hunks selected")
+	}
+	selected := make(map[string]bool)
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	var result []Hunk
+	for _, hunk := range hunks {
+		if selected[hunk.ID] {
+			result = append(result, hunk)
+			delete(selected, hunk.ID)
+		}
+	}
+	for _, id := range ids {
+		if selected[id] {
+			return nil, fmt.Errorf("unknown hunk %q, the diff may have changed", id)
+		}
+	}
+	return result, nil
+}
+
+// FormatHunkPatch renders hunks returned by SelectHunks as a patch for
+// `git apply`. The lines the hunks start at are adjusted for the hunks that
+// aren't included. If reverse is set, the patch is meant for `git apply -R`
+// and the lines of the new side are kept instead. Changes to the mode of a file
+// are left out, only the content of files is patched.
+func FormatHunkPatch(hunks []Hunk, reverse bool) string {
+	var result strings.Builder
+	offset := 0
+	for i, hunk := range hunks {
+		if i == 0 || hunks[i-1].Path != hunk.Path {
+			offset = 0
+			for _, line := range splitLines(hunk.FileHeader) {
+				if strings.HasPrefix(line, "old mode ") || strings.HasPrefix(line, "new mode ") || strings.HasPrefix(line, "index ") {
+					continue
+				}
+				result.WriteString(line)
+			}
+		}
+
+		oldStart, newStart := hunk.OldStart, hunk.NewStart
+		if reverse {
+			oldStart = hunkStart(hunkIndex(newStart, hunk.NewLines)-offset, hunk.OldLines)
+		} else {
+			newStart = hunkStart(hunkIndex(oldStart, hunk.OldLines)+offset, hunk.NewLines)
+		}
+		offset += hunk.NewLines - hunk.OldLines
+
+		header, body, _ := strings.Cut(hunk.Patch, "\n")
+		if match := hunkHeaderPattern.FindString(header); match != "" {
+			header = fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, hunk.OldLines, newStart, hunk.NewLines) + header[len(match):]
+		}
+		result.WriteString(header + "\n" + body)
+	}
+	return result.String()
+}
+
+// hunkIndex converts the start of one side of a hunk to the index of its first line
+func hunkIndex(start int, count int) int {
+	if count == 0 {
+		return start
+	}
+	return start - 1
+}
+
+// hunkStart converts the index of the first line of one side of a hunk to its start
+func hunkStart(index int, count int) int {
+	if count == 0 {
+		return index
+	}
+	return index + 1
+}
+
+// ApplyHunks applies hunks of the changes to a single file to its content, which
+// must match the old side of the hunks, or the new side if reverse is set. The
+// hunks must be in the order of the diff, like the ones returned by SelectHunks.
+func ApplyHunks(content []byte, hunks []Hunk, reverse bool) ([]byte, error) {
+	lines := splitLines(string(content))
+	var result strings.Builder
+	line := 0
+	for _, hunk := range hunks {
+		before, after := hunkSides(hunk)
+		start := hunkIndex(hunk.OldStart, hunk.OldLines)
+		if reverse {
+			before, after = after, before
+			start = hunkIndex(hunk.NewStart, hunk.NewLines)
+		}
+
+		if start < line || start+len(before) > len(lines) {
+			return nil, fmt.Errorf("hunk %s of %s does not apply", hunk.ID, hunk.Path)
+		}
+		for i, expected := range before {
+			if lines[start+i] != expected {
+				return nil, fmt.Errorf("hunk %s of %s does not apply", hunk.ID, hunk.Path)
+			}
+		}
+		for _, unchanged := range lines[line:start] {
+			result.WriteString(unchanged)
+		}
+		for _, changed := range after {
+			result.WriteString(changed)
+		}
+		line = start + len(before)
+	}
+	for _, unchanged := range lines[line:] {
+		result.WriteString(unchanged)
+	}
+	return []byte(result.String()), nil
+}
+
+// hunkSides returns the lines of the old and new side of a hunk, with their
+// line endings
+func hunkSides(hunk Hunk) ([]string, []string) {
+	var before, after []string
+	lastBefore, lastAfter := -1, -1
+	_, body, _ := strings.Cut(hunk.Patch, "\n")
+	for _, line := range splitLines(body) {
+		switch line[0] {
+		case ' ':
+			before, after = append(before, line[1:]), append(after, line[1:])
+			lastBefore, lastAfter = len(before)-1, len(after)-1
+		case '-':
+			before = append(before, line[1:])
+			lastBefore, lastAfter = len(before)-1, -1
+		case '+':
+			after = append(after, line[1:])
+			lastBefore, lastAfter = -1, len(after)-1
+		case '\\':
+			// "\ No newline at end of file" applies to the line before it
+			if lastBefore >= 0 {
+				before[lastBefore] = strings.TrimSuffix(before[lastBefore], "\n")
+			}
+			if lastAfter >= 0 {
+				after[lastAfter] = strings.TrimSuffix(after[lastAfter], "\n")
+			}
+		}
+	}
+	return before, after
+}
@@ -1,18 +1,61 @@
 package gitops
 
+import "context"
+
 // GitOperations defines the interface for Git operations
 type GitOperations interface {
-	GetStatus(repoPath string) (string, error)
-	GetDiffUnstaged(repoPath string) (string, error)
-	GetDiffStaged(repoPath string) (string, error)
-	GetDiff(repoPath string, target string) (string, error)
-	CommitChanges(repoPath string, message string) (string, error)
-	AddFiles(repoPath string, files []string) (string, error)
-	ResetStaged(repoPath string) (string, error)
-	GetLog(repoPath string, maxCount int) ([]string, error)
-	CreateBranch(repoPath string, branchName string, baseBranch string) (string, error)
-	CheckoutBranch(repoPath string, branchName string) (string, error)
-	InitRepo(repoPath string) (string, error)
-	ShowCommit(repoPath string, revision string) (string, error)
-	PushChanges(repoPath string, remote string, branch string) (string, error)
+	GetStatus(ctx context.Context, repoPath string) ([]FileStatus, error)
+	GetDiffUnstaged(ctx context.Context, repoPath string) ([]DiffFile, error)
+	GetDiffStaged(ctx context.Context, repoPath string) ([]DiffFile, error)
+	GetDiff(ctx context.Context, repoPath string, target string) ([]DiffFile, error)
+	CommitChanges(ctx context.Context, repoPath string, message string, opts CommitOptions) (string, error)
+	AddFiles(ctx context.Context, repoPath string, files []string, opts AddOptions) (string, error)
+	ResetStaged(ctx context.Context, repoPath string) (string, error)
+	Restore(ctx context.Context, repoPath string, paths []string, opts RestoreOptions) (string, error)
+	StageHunks(ctx context.Context, repoPath string, ids []string, unstage bool) (string, error)
+	ApplyPatch(ctx context.Context, repoPath string, patch string, opts ApplyOptions) (*ApplyResult, error)
+	ApplyMailbox(ctx context.Context, repoPath string, mailbox string, opts AmOptions) (string, error)
+	GetLog(ctx context.Context, repoPath string, opts LogOptions) ([]CommitInfo, error)
+	CreateBranch(ctx context.Context, repoPath string, branchName string, baseBranch string) (string, error)
+	CheckoutBranch(ctx context.Context, repoPath string, branchName string) (string, error)
+	InitRepo(ctx context.Context, repoPath string) (string, error)
+	ShowCommit(ctx context.Context, repoPath string, revision string) (string, error)
+	PushChanges(ctx context.Context, repoPath string, remote string, branch string) (string, error)
+	StashPush(ctx context.Context, repoPath string, message string, includeUntracked bool) (string, error)
+	StashList(ctx context.Context, repoPath string) ([]StashEntry, error)
+	StashShow(ctx context.Context, repoPath string, index int) ([]DiffFile, error)
+	StashApply(ctx context.Context, repoPath string, index int) (string, error)
+	StashPop(ctx context.Context, repoPath string, index int) (string, error)
+	StashDrop(ctx context.Context, repoPath string, index int) (string, error)
+	MergeBranch(ctx context.Context, repoPath string, branch string, mode MergeMode, message string) (string, error)
+	MergeAbort(ctx context.Context, repoPath string) (string, error)
+	Rebase(ctx context.Context, repoPath string, upstream string, onto string, autosquash bool) (*RebaseState, error)
+	RebaseContinue(ctx context.Context, repoPath string) (*RebaseState, error)
+	RebaseSkip(ctx context.Context, repoPath string) (*RebaseState, error)
+	RebaseAbort(ctx context.Context, repoPath string) (string, error)
+	CherryPick(ctx context.Context, repoPath string, revisions []string, recordOrigin bool, noCommit bool) (string, error)
+	Revert(ctx context.Context, repoPath string, revisions []string, noCommit bool) (string, error)
+	ListTags(ctx context.Context, repoPath string, pattern string, sortBy TagSort) ([]TagInfo, error)
+	CreateTag(ctx context.Context, repoPath string, name string, revision string, message string) (string, error)
+	DeleteTag(ctx context.Context, repoPath string, name string) (string, error)
+	PushTags(ctx context.Context, repoPath string, remote string, tags []string) (string, error)
+	ListBranches(ctx context.Context, repoPath string, includeRemote bool) ([]BranchInfo, error)
+	DeleteBranch(ctx context.Context, repoPath string, branchName string, force bool) (string, error)
+	RenameBranch(ctx context.Context, repoPath string, oldName string, newName string) (string, error)
+	SetUpstream(ctx context.Context, repoPath string, branchName string, upstream string) (string, error)
+	Fetch(ctx context.Context, repoPath string, remote string, refspec string, prune bool, tags bool) (string, error)
+	Pull(ctx context.Context, repoPath string, remote string, branch string, strategy PullStrategy, ffOnly bool) (string, error)
+	CloneRepo(ctx context.Context, url string, targetPath string, branch string, depth int, filter string) (string, error)
+	Blame(ctx context.Context, repoPath string, filePath string, revision string, startLine int, endLine int) ([]BlameLine, error)
+	ReadFileAtRevision(ctx context.Context, repoPath string, revision string, filePath string, startLine int, endLine int) (*FileContent, error)
+	ListTree(ctx context.Context, repoPath string, revision string, pathPrefix string, recursive bool, pattern string) ([]TreeEntry, error)
+	ListFiles(ctx context.Context, repoPath string, pattern string, includeUntracked bool, includeIgnored bool) ([]IndexFile, error)
+	Grep(ctx context.Context, repoPath string, opts GrepOptions) (*GrepResult, error)
+}
+
+// RepositoryChecker is implemented by GitOperations whose repositories aren't
+// directories on disk. Otherwise a path is a repository if it contains a .git
+// directory.
+type RepositoryChecker interface {
+	IsRepository(repoPath string) bool
 }
@@ -0,0 +1,183 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogMergeFilter selects whether merge commits are included in the log
+type LogMergeFilter string
+
+const (
+	// LogMergesInclude lists merge commits along with all other commits
+	LogMergesInclude LogMergeFilter = ""
+	// LogMergesOnly lists only merge commits
+	LogMergesOnly LogMergeFilter = "only"
+	// LogMergesExclude lists no merge commits
+	LogMergesExclude LogMergeFilter = "exclude"
+)
+
+// LogFormat is the format used to print log entries, parsed by ParseLog.
+// Fields are separated by NUL bytes and commits are terminated by a record separator:
+// hash, parent hashes, author name, email and date, committer name, email and date, message.
+const LogFormat = "--format=%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%B%x1e"
+
+// CommitInfo describes a commit
+type CommitInfo struct {
+	Hash           string    `json:"hash"`
+	Parents        []string  `json:"parents,omitempty"`
+	Author         string    `json:"author"`
+	AuthorEmail    string    `json:"author_email"`
+	Date           time.Time `json:"date"` // author date
+	Committer      string    `json:"committer"`
+	CommitterEmail string    `json:"committer_email"`
+	CommitDate     time.Time `json:"commit_date"`
+	Message        string    `json:"message"` // full message without trailing newlines
+}
+
+// Subject returns the first line of the commit message
+func (c CommitInfo) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// ParseLog parses the output of `git log` run with LogFormat
+func ParseLog(output string) ([]CommitInfo, error) {
+	var commits []CommitInfo
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x00", 9)
+		if len(fields) != 9 {
+			return nil, fmt.Errorf("unexpected log entry: %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected author date %q: %w", fields[4], err)
+		}
+		commitDate, err := time.Parse(time.RFC3339, fields[7])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected committer date %q: %w", fields[7], err)
+		}
+
+		commits = append(commits, CommitInfo{
+			Hash:           fields[0],
+			Parents:        strings.Fields(fields[1]),
+			Author:         fields[2],
+			AuthorEmail:    fields[3],
+			Date:           date,
+			Committer:      fields[5],
+			CommitterEmail: fields[6],
+			CommitDate:     commitDate,
+			Message:        strings.TrimRight(fields[8], "\n"),
+		})
+	}
+	return commits, nil
+}
+
+// LogOptions selects the commits listed by GetLog
+type LogOptions struct {
+	MaxCount      int            // maximum number of commits, 0 for no limit
+	Skip          int            // number of matching commits to skip before listing
+	Revision      string         // revision or range such as "v1.0..main" (default: HEAD)
+	Author        string         // regular expression matched against "name <email>" of the author
+	Committer     string         // regular expression matched against "name <email>" of the committer
+	Since         string         // only commits more recent than this date
+	Until         string         // only commits older than this date
+	Paths         []string       // only commits that change these paths
+	Follow        bool           // follow renames of the single file in Paths
+	Grep          string         // regular expression matched against the commit message
+	PickaxeString string         // only commits that change the number of occurrences of this string (-S)
+	PickaxeRegex  string         // only commits whose added or removed lines match this regular expression (-G)
+	Merges        LogMergeFilter // whether to include merge commits
+}
+
+// ValidateLogOptions checks that the options can be combined and that the
+// revision isn't an option
+func ValidateLogOptions(opts LogOptions) error {
+	if err := ValidateArgument("revision", opts.Revision); err != nil {
+		return err
+	}
+	if opts.Follow && len(opts.Paths) != 1 {
+		return fmt.Errorf("follow requires exactly one path")
+	}
+	switch opts.Merges {
+	case LogMergesInclude, LogMergesOnly, LogMergesExclude:
+	default:
+		return fmt.Errorf("unsupported merge filter: %s", opts.Merges)
+	}
+	return nil
+}
+
+// LogArgs builds the git log arguments for opts
+func LogArgs(opts LogOptions) []string {
+	args := []string{"log", LogFormat}
+	if opts.MaxCount > 0 {
+		args = append(args, fmt.Sprintf("-n%d", opts.MaxCount))
+	}
+	if opts.Skip > 0 {
+		args = append(args, fmt.Sprintf("--skip=%d", opts.Skip))
+	}
+
+	// Interpret the patterns like Go's regexp package does rather than as basic regular expressions
+	args = append(args, "--extended-regexp")
+	if opts.Author != "" {
+		args = append(args, "--author="+opts.Author)
+	}
+	if opts.Committer != "" {
+		args = append(args, "--committer="+opts.Committer)
+	}
+	if opts.Grep != "" {
+		args = append(args, "--grep="+opts.Grep)
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Until != "" {
+		args = append(args, "--until="+opts.Until)
+	}
+	if opts.PickaxeString != "" {
+		args = append(args, "-S"+opts.PickaxeString)
+	}
+	if opts.PickaxeRegex != "" {
+		args = append(args, "-G"+opts.PickaxeRegex)
+	}
+	switch opts.Merges {
+	case LogMergesOnly:
+		args = append(args, "--merges")
+	case LogMergesExclude:
+		args = append(args, "--no-merges")
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+
+	if opts.Revision != "" {
+		args = append(args, opts.Revision)
+	}
+	args = append(args, "--")
+	return append(args, opts.Paths...)
+}
+
+// logDateLayouts are the date formats that can be parsed without git's approxidate
+var logDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseLogDate parses a since/until date in one of the absolute formats git
+// accepts. Relative dates such as "2 weeks ago" are not supported.
+func ParseLogDate(date string) (time.Time, bool) {
+	for _, layout := range logDateLayouts {
+		if t, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
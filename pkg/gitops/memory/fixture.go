@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+const (
+	// DefaultFixtureBranch is the initial branch of a seeded repository
+	DefaultFixtureBranch = "main"
+	// DefaultFixtureAuthor is the author and committer of fixture commits without an author
+	DefaultFixtureAuthor = "Test User"
+	// DefaultFixtureAuthorEmail is the email of DefaultFixtureAuthor
+	DefaultFixtureAuthorEmail = "test@example.com"
+)
+
+// FixtureEpoch is the date of the first fixture commit without a date. Each
+// following commit is a minute later, so that a fixture is seeded with the same
+// commit hashes every time.
+var FixtureEpoch = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// Fixture describes the contents of a repository created by Seed
+type Fixture struct {
+	// Branch is the initial branch, DefaultFixtureBranch if empty
+	Branch string
+	// Commits are created in order
+	Commits []FixtureCommit
+	// Checkout is the branch checked out after the commits, the branch of the
+	// last commit if empty
+	Checkout string
+	// Tags maps the names of lightweight tags to the revisions they point to
+	Tags map[string]string
+	// Staged maps paths to contents that are written and staged after the commits
+	Staged map[string]string
+	// Unstaged maps paths to contents that are written to the working tree last
+	Unstaged map[string]string
+}
+
+// FixtureCommit describes a commit of a Fixture
+type FixtureCommit struct {
+	// Branch is checked out before committing, and created at the current commit
+	// if it doesn't exist. The current branch is used if it is empty.
+	Branch  string
+	Message string
+	// Files maps the paths of added or changed files to their contents
+	Files map[string]string
+	// Deleted lists the paths of deleted files
+	Deleted []string
+	// Author is the author and committer, DefaultFixtureAuthor if empty
+	Author      string
+	AuthorEmail string
+	// Date is the author and committer date, derived from FixtureEpoch if zero
+	Date time.Time
+}
+
+// Seed creates a repository at repoPath with the contents described by fixture.
+// Its user.name and user.email are DefaultFixtureAuthor and DefaultFixtureAuthorEmail.
+func (m *MemoryGitOperations) Seed(repoPath string, fixture Fixture) error {
+	branch := fixture.Branch
+	if branch == "" {
+		branch = DefaultFixtureBranch
+	}
+	repo, err := git.InitWithOptions(memory.NewStorage(), memfs.New(), git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName(branch),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Commits made by tools get the same identity as the fixture commits
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.User.Name, cfg.User.Email = DefaultFixtureAuthor, DefaultFixtureAuthorEmail
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to set config: %w", err)
+	}
+
+	if err := seed(repo, fixture); err != nil {
+		return fmt.Errorf("failed to seed %s: %w", repoPath, err)
+	}
+	if err := m.add(repoPath, repo); err != nil {
+		return fmt.Errorf("failed to seed %s: %w", repoPath, err)
+	}
+	return nil
+}
+
+// seed creates the contents of a fixture in an empty repository
+func seed(repo *git.Repository, fixture Fixture) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	for i, commit := range fixture.Commits {
+		if commit.Branch != "" {
+			if err := checkoutFixtureBranch(repo, wt, commit.Branch); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(commit.Files) {
+			if err := writeFixtureFile(wt, name, commit.Files[name], true); err != nil {
+				return err
+			}
+		}
+		for _, name := range commit.Deleted {
+			if _, err := wt.Remove(name); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", name, err)
+			}
+		}
+
+		signature := &object.Signature{
+			Name:  commit.Author,
+			Email: commit.AuthorEmail,
+			When:  commit.Date,
+		}
+		if signature.Name == "" {
+			signature.Name, signature.Email = DefaultFixtureAuthor, DefaultFixtureAuthorEmail
+		}
+		if signature.When.IsZero() {
+			signature.When = FixtureEpoch.Add(time.Duration(i) * time.Minute)
+		}
+		_, err := wt.Commit(commit.Message, &git.CommitOptions{
+			Author:            signature,
+			Committer:         signature,
+			AllowEmptyCommits: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to commit %q: %w", commit.Message, err)
+		}
+	}
+
+	if fixture.Checkout != "" {
+		if err := checkoutFixtureBranch(repo, wt, fixture.Checkout); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(fixture.Tags) {
+		hash, err := repo.ResolveRevision(plumbing.Revision(fixture.Tags[name]))
+		if err != nil {
+			return fmt.Errorf("failed to resolve revision %s: %w", fixture.Tags[name], err)
+		}
+		if _, err := repo.CreateTag(name, *hash, nil); err != nil {
+			return fmt.Errorf("failed to create tag %s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(fixture.Staged) {
+		if err := writeFixtureFile(wt, name, fixture.Staged[name], true); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(fixture.Unstaged) {
+		if err := writeFixtureFile(wt, name, fixture.Unstaged[name], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkoutFixtureBranch checks out a branch, creating it at the current commit
+// if it doesn't exist
+func checkoutFixtureBranch(repo *git.Repository, wt *git.Worktree, branch string) error {
+	refName := plumbing.NewBranchReferenceName(branch)
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Type() == plumbing.SymbolicReference && head.Target() == refName {
+		return nil
+	}
+
+	_, err = repo.Reference(refName, false)
+	exists := err == nil
+	if _, err := repo.Head(); err == plumbing.ErrReferenceNotFound && !exists {
+		// Before the first commit, the branch is created by committing
+		err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, refName))
+		if err != nil {
+			return fmt.Errorf("failed to switch to branch %s: %w", branch, err)
+		}
+		return nil
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: refName, Create: !exists}); err != nil {
+		return fmt.Errorf("failed to checkout branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// writeFixtureFile writes a file to the working tree, and stages it if stage is set
+func writeFixtureFile(wt *git.Worktree, name string, content string, stage bool) error {
+	if err := util.WriteFile(wt.Filesystem, name, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if !stage {
+		return nil
+	}
+	if _, err := wt.Add(name); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", name, err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order, so that fixtures are seeded
+// the same way every time
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
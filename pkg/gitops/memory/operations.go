@@ -0,0 +1,111 @@
+// Package memory implements GitOperations on repositories that are kept in
+// memory, using go-git's memory storage and an in-memory working tree. It lets
+// the server run without touching disk, in tests and sandboxes.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops/gogit"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// MemoryGitOperations implements GitOperations on repositories kept in memory.
+// Repositories are identified by their path, but nothing is read from or written
+// to disk. They are created with InitRepo, CloneRepo or Seed and are lost when
+// the MemoryGitOperations is.
+//
+// The operations are those of the go-git backend. Operations that the go-git
+// backend runs the git binary for fail with gogit.ErrGitBinaryRequired.
+type MemoryGitOperations struct {
+	*gogit.GoGitOperations
+
+	mu    sync.Mutex
+	repos map[string]*git.Repository
+}
+
+// NewMemoryGitOperations creates a new MemoryGitOperations instance without repositories
+func NewMemoryGitOperations() *MemoryGitOperations {
+	m := &MemoryGitOperations{repos: make(map[string]*git.Repository)}
+	m.GoGitOperations = gogit.NewGoGitOperationsWithOpener(m.Repository)
+	return m
+}
+
+// Repository returns the repository at repoPath, or git.ErrRepositoryNotExists
+func (m *MemoryGitOperations) Repository(repoPath string) (*git.Repository, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	repo, ok := m.repos[filepath.Clean(repoPath)]
+	if !ok {
+		return nil, git.ErrRepositoryNotExists
+	}
+	return repo, nil
+}
+
+// IsRepository checks if there is a repository at repoPath
+func (m *MemoryGitOperations) IsRepository(repoPath string) bool {
+	_, err := m.Repository(repoPath)
+	return err == nil
+}
+
+// add adds a repository at repoPath, or returns git.ErrRepositoryAlreadyExists
+func (m *MemoryGitOperations) add(repoPath string, repo *git.Repository) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	repoPath = filepath.Clean(repoPath)
+	if _, ok := m.repos[repoPath]; ok {
+		return git.ErrRepositoryAlreadyExists
+	}
+	m.repos[repoPath] = repo
+	return nil
+}
+
+// InitRepo creates an empty repository in memory
+func (m *MemoryGitOperations) InitRepo(ctx context.Context, repoPath string) (string, error) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	if err := m.add(repoPath, repo); err != nil {
+		return "", fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	return fmt.Sprintf("Initialized empty Git repository in memory at %s", repoPath), nil
+}
+
+// CloneRepo clones a repository into memory. Partial clones aren't supported.
+func (m *MemoryGitOperations) CloneRepo(ctx context.Context, url string, targetPath string, branch string, depth int, filter string) (string, error) {
+	if filter != "" {
+		// go-git doesn't support partial clones
+		return "", fmt.Errorf("failed to clone repository: %w", gogit.ErrGitBinaryRequired)
+	}
+	if m.IsRepository(targetPath) {
+		return "", fmt.Errorf("failed to clone repository: %w", git.ErrRepositoryAlreadyExists)
+	}
+
+	opts := &git.CloneOptions{
+		URL:   url,
+		Depth: depth,
+	}
+	if branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), memfs.New(), opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+	if err := m.add(targetPath, repo); err != nil {
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	return fmt.Sprintf("Cloned %s into %s", url, targetPath), nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/geropl/git-mcp-go/pkg/gitops/gogit"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var fixture = Fixture{
+	Commits: []FixtureCommit{
+		{Message: "Add a", Files: map[string]string{"a.txt": "one\n", "dir/b.txt": "bee\n"}},
+		{Message: "Change a", Files: map[string]string{"a.txt": "two\n"}},
+		{Branch: "feature", Message: "Add feature", Files: map[string]string{"feature.txt": "feature\n"}, Deleted: []string{"dir/b.txt"}},
+		{Branch: "main", Message: "Add c", Files: map[string]string{"c.txt": "sea\n"}, Author: "Other Author", AuthorEmail: "other@example.com"},
+	},
+	Tags:     map[string]string{"v1": "HEAD~1"},
+	Staged:   map[string]string{"staged.txt": "staged\n"},
+	Unstaged: map[string]string{"a.txt": "unstaged\n", "untracked.txt": "new\n"},
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	ops := NewMemoryGitOperations()
+	require.NoError(t, ops.Seed("/repo", fixture))
+	require.True(t, ops.IsRepository("/repo"))
+	require.False(t, ops.IsRepository("/other"))
+
+	commits, err := ops.GetLog(ctx, "/repo", gitops.LogOptions{})
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	require.Equal(t, "Add c", commits[0].Message)
+	require.Equal(t, "Other Author", commits[0].Author)
+	require.Equal(t, FixtureEpoch.Add(3*time.Minute), commits[0].Date.UTC())
+	require.Equal(t, DefaultFixtureAuthor, commits[1].Author)
+
+	branches, err := ops.ListBranches(ctx, "/repo", false)
+	require.NoError(t, err)
+	require.Len(t, branches, 2)
+	require.Equal(t, "feature", branches[0].Name)
+	require.Equal(t, "main", branches[1].Name)
+	require.True(t, branches[1].Current)
+
+	files, err := ops.ListTree(ctx, "/repo", "feature", "", true, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.txt", "feature.txt"}, []string{files[0].Path, files[1].Path})
+
+	content, err := ops.ReadFileAtRevision(ctx, "/repo", "v1", "a.txt", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, "two\n", content.Content)
+
+	status, err := ops.GetStatus(ctx, "/repo")
+	require.NoError(t, err)
+	require.Equal(t, []gitops.FileStatus{
+		{Path: "a.txt", Index: " ", Worktree: "M"},
+		{Path: "staged.txt", Index: "A", Worktree: " "},
+		{Path: "untracked.txt", Index: "?", Worktree: "?"},
+	}, status)
+
+	// The same fixture always has the same commits
+	other := NewMemoryGitOperations()
+	require.NoError(t, other.Seed("/repo", fixture))
+	otherCommits, err := other.GetLog(ctx, "/repo", gitops.LogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, commits[0].Hash, otherCommits[0].Hash)
+
+	require.ErrorIs(t, ops.Seed("/repo", Fixture{}), git.ErrRepositoryAlreadyExists)
+}
+
+func TestInitRepoAndCommit(t *testing.T) {
+	ctx := context.Background()
+	ops := NewMemoryGitOperations()
+	_, err := ops.InitRepo(ctx, "/new")
+	require.NoError(t, err)
+	_, err = ops.InitRepo(ctx, "/new/")
+	require.ErrorIs(t, err, git.ErrRepositoryAlreadyExists)
+
+	repo, err := ops.Repository("/new")
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, util.WriteFile(wt.Filesystem, "a.txt", []byte("one\n"), 0644))
+
+	_, err = ops.AddFiles(ctx, "/new", []string{"a.txt"}, gitops.AddOptions{})
+	require.NoError(t, err)
+	// Only the repository's own config is read, which doesn't have an identity
+	_, err = ops.CommitChanges(ctx, "/new", "Add a", gitops.CommitOptions{})
+	require.ErrorIs(t, err, gogit.ErrMissingIdentity)
+	committer := gitops.Identity{Name: "Committer", Email: "committer@example.com"}
+	_, err = ops.CommitChanges(ctx, "/new", "Add a", gitops.CommitOptions{Committer: &committer})
+	require.NoError(t, err)
+
+	commits, err := ops.GetLog(ctx, "/new", gitops.LogOptions{})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, "Add a", commits[0].Message)
+	require.Equal(t, "Committer", commits[0].Author)
+
+	_, err = ops.GetStatus(ctx, "/missing")
+	require.ErrorIs(t, err, git.ErrRepositoryNotExists)
+}
+
+func TestOperationsRequiringGit(t *testing.T) {
+	ctx := context.Background()
+	ops := NewMemoryGitOperations()
+	require.NoError(t, ops.Seed("/repo", fixture))
+
+	_, err := ops.StashPush(ctx, "/repo", "", false)
+	require.ErrorIs(t, err, gogit.ErrGitBinaryRequired)
+	_, err = ops.MergeBranch(ctx, "/repo", "feature", gitops.MergeModeNoFastForward, "")
+	require.ErrorIs(t, err, gogit.ErrGitBinaryRequired)
+	_, err = ops.Rebase(ctx, "/repo", "feature", "", false)
+	require.ErrorIs(t, err, gogit.ErrGitBinaryRequired)
+	_, err = ops.CloneRepo(ctx, "https://example.com/repo.git", "/clone", "", 0, "blob:none")
+	require.ErrorIs(t, err, gogit.ErrGitBinaryRequired)
+}
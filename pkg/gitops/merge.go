@@ -0,0 +1,46 @@
+package gitops
+
+import "fmt"
+
+// MergeMode selects how a branch is merged into the current branch
+type MergeMode string
+
+const (
+	// MergeModeDefault fast-forwards when possible and creates a merge commit otherwise
+	MergeModeDefault MergeMode = ""
+	// MergeModeFastForwardOnly refuses to merge unless the current branch can be fast-forwarded
+	MergeModeFastForwardOnly MergeMode = "ff-only"
+	// MergeModeNoFastForward always creates a merge commit
+	MergeModeNoFastForward MergeMode = "no-ff"
+	// MergeModeSquash stages the combined changes without committing or recording a merge
+	MergeModeSquash MergeMode = "squash"
+)
+
+// MergeArgs builds the git merge arguments for merging branch with the given mode
+func MergeArgs(branch string, mode MergeMode, message string) ([]string, error) {
+	if err := ValidateArgument("branch", branch); err != nil {
+		return nil, err
+	}
+
+	args := []string{"merge"}
+	switch mode {
+	case MergeModeDefault:
+	case MergeModeFastForwardOnly:
+		args = append(args, "--ff-only")
+	case MergeModeNoFastForward:
+		args = append(args, "--no-ff")
+	case MergeModeSquash:
+		args = append(args, "--squash")
+	default:
+		return nil, fmt.Errorf("unsupported merge mode: %s", mode)
+	}
+
+	if message != "" {
+		args = append(args, "-m", message)
+	} else if mode != MergeModeSquash {
+		// Never open an editor for the merge commit message
+		args = append(args, "--no-edit")
+	}
+
+	return append(args, branch), nil
+}
@@ -0,0 +1,364 @@
+package gitops
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	// ZeroHash stands for the missing side of an added or deleted file
+	ZeroHash = "0000000000000000000000000000000000000000"
+	// EmptyBlobHash is the hash of an empty file
+	EmptyBlobHash = "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
+
+	// patchContextLines is the number of unchanged lines around each change
+	patchContextLines = 3
+	// funcNameSize is the maximum length of the function name in a hunk header
+	funcNameSize = 80
+
+	modeTypeMask = 0170000
+)
+
+// PatchFile is one side of a changed file
+type PatchFile struct {
+	Path    string
+	Mode    uint32 // file mode as stored by git, like 0100644
+	Hash    string // blob hash
+	Content []byte
+}
+
+// FileChange is a change to a file. From is nil for added files and To is nil
+// for deleted files.
+type FileChange struct {
+	From *PatchFile
+	To   *PatchFile
+}
+
+// FormatPatch renders changes like `git diff` run with DiffArgs, so that a diff
+// computed without the git binary can be parsed with ParseDiff. Deleted and added
+// files with the same content are reported as renamed.
+func FormatPatch(changes []FileChange) string {
+	changes = DetectExactRenames(changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changePath(changes[i]) < changePath(changes[j])
+	})
+
+	var result strings.Builder
+	for _, change := range changes {
+		// A file that changed its type, like a file replaced by a symlink, is deleted and added
+		if change.From != nil && change.To != nil && change.From.Mode&modeTypeMask != change.To.Mode&modeTypeMask {
+			writeFilePatch(&result, change.From, nil)
+			writeFilePatch(&result, nil, change.To)
+			continue
+		}
+		writeFilePatch(&result, change.From, change.To)
+	}
+	return result.String()
+}
+
+// changePath returns the path a change is sorted by
+func changePath(change FileChange) string {
+	if change.To != nil {
+		return change.To.Path
+	}
+	return change.From.Path
+}
+
+// DetectExactRenames pairs deleted and added files with the same content.
+// Deleted files with the same name as the added file are preferred.
+func DetectExactRenames(changes []FileChange) []FileChange {
+	deleted := make(map[string][]int)
+	for i, change := range changes {
+		if change.To == nil && change.From.Hash != EmptyBlobHash {
+			deleted[change.From.Hash] = append(deleted[change.From.Hash], i)
+		}
+	}
+	if len(deleted) == 0 {
+		return changes
+	}
+
+	// Match the added files in the order of their paths
+	added := make([]int, 0, len(changes))
+	for i, change := range changes {
+		if change.From == nil && change.To.Hash != EmptyBlobHash {
+			added = append(added, i)
+		}
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		return changes[added[i]].To.Path < changes[added[j]].To.Path
+	})
+
+	renamedFrom := make(map[int]int)
+	used := make(map[int]bool)
+	for _, i := range added {
+		name := path.Base(changes[i].To.Path)
+		best := -1
+		for _, j := range deleted[changes[i].To.Hash] {
+			if used[j] {
+				continue
+			}
+			if best == -1 || (path.Base(changes[j].From.Path) == name && path.Base(changes[best].From.Path) != name) {
+				best = j
+			}
+		}
+		if best != -1 {
+			used[best] = true
+			renamedFrom[i] = best
+		}
+	}
+
+	var paired []FileChange
+	for i, change := range changes {
+		if used[i] {
+			continue
+		}
+		if j, ok := renamedFrom[i]; ok {
+			change.From = changes[j].From
+		}
+		paired = append(paired, change)
+	}
+	return paired
+}
+
+// writeFilePatch writes the header and hunks of the changes to a single file
+func writeFilePatch(result *strings.Builder, from *PatchFile, to *PatchFile) {
+	oldName, newName := "/dev/null", "/dev/null"
+	oldHash, newHash := ZeroHash, ZeroHash
+	var oldContent, newContent []byte
+	if from != nil {
+		oldName = quotePath("a/" + from.Path)
+		oldHash = from.Hash
+		oldContent = from.Content
+	}
+	if to != nil {
+		newName = quotePath("b/" + to.Path)
+		newHash = to.Hash
+		newContent = to.Content
+	}
+
+	switch {
+	case from == nil:
+		result.WriteString(fmt.Sprintf("diff --git %s %s\n", quotePath("a/"+to.Path), newName))
+		result.WriteString(fmt.Sprintf("new file mode %06o\n", to.Mode))
+		result.WriteString(fmt.Sprintf("index %s..%s\n", oldHash, newHash))
+	case to == nil:
+		result.WriteString(fmt.Sprintf("diff --git %s %s\n", oldName, quotePath("b/"+from.Path)))
+		result.WriteString(fmt.Sprintf("deleted file mode %06o\n", from.Mode))
+		result.WriteString(fmt.Sprintf("index %s..%s\n", oldHash, newHash))
+	default:
+		result.WriteString(fmt.Sprintf("diff --git %s %s\n", oldName, newName))
+		if from.Mode != to.Mode {
+			result.WriteString(fmt.Sprintf("old mode %06o\nnew mode %06o\n", from.Mode, to.Mode))
+		}
+		if from.Path != to.Path {
+			result.WriteString(fmt.Sprintf("similarity index 100%%\nrename from %s\nrename to %s\n", quotePath(from.Path), quotePath(to.Path)))
+		}
+		if oldHash == newHash {
+			return
+		}
+		if from.Mode == to.Mode {
+			result.WriteString(fmt.Sprintf("index %s..%s %06o\n", oldHash, newHash, from.Mode))
+		} else {
+			result.WriteString(fmt.Sprintf("index %s..%s\n", oldHash, newHash))
+		}
+	}
+
+	if IsBinary(oldContent) || IsBinary(newContent) {
+		result.WriteString(fmt.Sprintf("Binary files %s and %s differ\n", oldName, newName))
+		return
+	}
+	if len(oldContent) == 0 && len(newContent) == 0 {
+		return
+	}
+
+	// Like git, names with spaces end with a tab to tell them from trailing text
+	result.WriteString(fmt.Sprintf("--- %s%s\n+++ %s%s\n", oldName, nameTab(from), newName, nameTab(to)))
+	writeHunks(result, splitLines(string(oldContent)), splitLines(string(newContent)))
+}
+
+// nameTab returns the separator after a file name in the ---/+++ lines of a patch
+func nameTab(file *PatchFile) string {
+	if file != nil && strings.Contains(file.Path, " ") {
+		return "\t"
+	}
+	return ""
+}
+
+// lineChange replaces count1 lines starting at index start1 of the old file
+// with count2 lines starting at index start2 of the new file
+type lineChange struct {
+	start1, count1 int
+	start2, count2 int
+}
+
+// writeHunks writes the differences between the lines of two files as hunks
+// with context lines, merging changes that are close to each other like git
+func writeHunks(result *strings.Builder, oldLines []string, newLines []string) {
+	var changes []lineChange
+	line1, line2 := 0, 0
+	for _, d := range diff.Do(strings.Join(oldLines, ""), strings.Join(newLines, "")) {
+		count := len(splitLines(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			line1 += count
+			line2 += count
+			continue
+		case diffmatchpatch.DiffDelete:
+			if n := len(changes); n > 0 && changes[n-1].start1+changes[n-1].count1 == line1 && changes[n-1].start2+changes[n-1].count2 == line2 {
+				changes[n-1].count1 += count
+			} else {
+				changes = append(changes, lineChange{start1: line1, count1: count, start2: line2})
+			}
+			line1 += count
+		case diffmatchpatch.DiffInsert:
+			if n := len(changes); n > 0 && changes[n-1].start1+changes[n-1].count1 == line1 && changes[n-1].start2+changes[n-1].count2 == line2 {
+				changes[n-1].count2 += count
+			} else {
+				changes = append(changes, lineChange{start1: line1, start2: line2, count2: count})
+			}
+			line2 += count
+		}
+	}
+
+	for first := 0; first < len(changes); {
+		// Changes separated by no more than twice the context share a hunk
+		last := first
+		for last+1 < len(changes) && changes[last+1].start1-(changes[last].start1+changes[last].count1) <= 2*patchContextLines {
+			last++
+		}
+
+		start1 := max(changes[first].start1-patchContextLines, 0)
+		start2 := max(changes[first].start2-patchContextLines, 0)
+		end1 := min(changes[last].start1+changes[last].count1+patchContextLines, len(oldLines))
+		end2 := min(changes[last].start2+changes[last].count2+patchContextLines, len(newLines))
+
+		result.WriteString(fmt.Sprintf("@@ -%s +%s @@", hunkRange(start1, end1-start1), hunkRange(start2, end2-start2)))
+		if funcName := hunkFuncName(oldLines, start1); funcName != "" {
+			result.WriteString(" " + funcName)
+		}
+		result.WriteString("\n")
+
+		line := start1
+		for _, change := range changes[first : last+1] {
+			for ; line < change.start1; line++ {
+				writeHunkLine(result, ' ', oldLines[line])
+			}
+			for _, removed := range oldLines[change.start1 : change.start1+change.count1] {
+				writeHunkLine(result, '-', removed)
+			}
+			for _, added := range newLines[change.start2 : change.start2+change.count2] {
+				writeHunkLine(result, '+', added)
+			}
+			line = change.start1 + change.count1
+		}
+		for ; line < end1; line++ {
+			writeHunkLine(result, ' ', oldLines[line])
+		}
+		first = last + 1
+	}
+}
+
+// hunkRange formats the start and length of one side of a hunk
+func hunkRange(start int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// hunkFuncName finds the function a hunk starting at index start belongs to, with
+// the default rule of git: the closest preceding line that starts with a letter,
+// "_" or "$", truncated to funcNameSize bytes
+func hunkFuncName(lines []string, start int) string {
+	for i := start - 1; i >= 0; i-- {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		if c := line[0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$' {
+			if len(line) > funcNameSize {
+				line = line[:funcNameSize]
+			}
+			return strings.TrimRight(line, " \t\n\r")
+		}
+	}
+	return ""
+}
+
+// writeHunkLine writes a line of a hunk, marking a missing newline at the end of the file
+func writeHunkLine(result *strings.Builder, prefix byte, line string) {
+	result.WriteByte(prefix)
+	result.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		result.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines splits text into lines, keeping their line endings
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// quotePath quotes a path like git does for paths with control characters,
+// quotes, backslashes or bytes outside of ASCII
+func quotePath(p string) string {
+	needsQuotes := false
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; c < 0x20 || c == '"' || c == '\\' || c >= 0x7f {
+			needsQuotes = true
+			break
+		}
+	}
+	if !needsQuotes {
+		return p
+	}
+
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '\a':
+			quoted.WriteString(`\a`)
+		case '\b':
+			quoted.WriteString(`\b`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\v':
+			quoted.WriteString(`\v`)
+		case '\f':
+			quoted.WriteString(`\f`)
+		case '\r':
+			quoted.WriteString(`\r`)
+		case '"':
+			quoted.WriteString(`\"`)
+		case '\\':
+			quoted.WriteString(`\\`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				quoted.WriteString(fmt.Sprintf(`\%03o`, c))
+			} else {
+				quoted.WriteByte(c)
+			}
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
@@ -0,0 +1,91 @@
+package gitops
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MatchPathspec reports whether a path relative to the repository root matches
+// a pathspec, see PathspecRegexp
+func MatchPathspec(pathspec string, filePath string) bool {
+	pattern, err := PathspecRegexp(pathspec)
+	return err == nil && pattern.MatchString(filePath)
+}
+
+// PathspecRegexp converts a pathspec into a regular expression matching the
+// paths relative to the repository root that it selects, like git does. A
+// pathspec without wildcards selects a file or everything in a directory, and
+// "." selects every path. A pathspec with the wildcards *, ? and [...] has to
+// match the whole path, where unlike in shell globs * and ? also match slashes.
+func PathspecRegexp(pathspec string) (*regexp.Regexp, error) {
+	pathspec = path.Clean(NormalizeRevisionPath(pathspec))
+	if pathspec == "." {
+		return regexp.Compile("")
+	}
+	if !strings.ContainsAny(pathspec, "*?[") {
+		return regexp.Compile("^" + regexp.QuoteMeta(pathspec) + "(/|$)")
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i := 0; i < len(pathspec); i++ {
+		switch c := pathspec[i]; c {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pathspec[i+1:], ']')
+			if end < 0 {
+				pattern.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pathspec[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			pattern.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
+
+// MatchPathspecs returns the paths that match any of the pathspecs. Like git,
+// it fails if a pathspec doesn't match any of the paths.
+func MatchPathspecs(pathspecs []string, paths []string) ([]string, error) {
+	var matched []string
+	used := make([]bool, len(pathspecs))
+	for _, filePath := range paths {
+		match := false
+		for i, pathspec := range pathspecs {
+			if MatchPathspec(pathspec, filePath) {
+				used[i] = true
+				match = true
+			}
+		}
+		if match {
+			matched = append(matched, filePath)
+		}
+	}
+
+	for i, pathspec := range pathspecs {
+		if !used[i] {
+			return nil, &PathspecError{Pathspec: pathspec}
+		}
+	}
+	return matched, nil
+}
+
+// PathspecError is returned when a pathspec doesn't match any file
+type PathspecError struct {
+	Pathspec string
+}
+
+func (e *PathspecError) Error() string {
+	return "pathspec '" + e.Pathspec + "' did not match any file(s) known to git"
+}
@@ -0,0 +1,144 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RebaseState describes the state of the repository after a rebase step
+type RebaseState struct {
+	InProgress      bool     `json:"in_progress"`
+	HeadName        string   `json:"head_name"`      // branch being rebased, e.g. "refs/heads/feature"
+	Onto            string   `json:"onto"`           // commit the branch is being rebased onto
+	CurrentCommit   string   `json:"current_commit"` // commit being applied when the rebase stopped
+	CurrentSubject  string   `json:"current_subject"`
+	Done            int      `json:"done"`      // number of todo items processed, including the current one
+	Remaining       int      `json:"remaining"` // number of todo items still to be processed
+	ConflictedFiles []string `json:"conflicted_files,omitempty"`
+	Output          string   `json:"output"` // output of the git command that produced this state
+}
+
+// RebaseArgs builds the arguments for a non-interactive rebase of the current
+// branch onto upstream. The todo list and commit messages are accepted as
+// generated, so autosquash can be applied without an editor.
+func RebaseArgs(upstream string, onto string, autosquash bool) ([]string, error) {
+	if err := ValidateArgument("upstream", upstream); err != nil {
+		return nil, err
+	}
+	if err := ValidateArgument("onto", onto); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-c", "sequence.editor=:", "-c", "core.editor=true", "rebase"}
+	if autosquash {
+		args = append(args, "--interactive", "--autosquash")
+	}
+	if onto != "" {
+		args = append(args, "--onto", onto)
+	}
+	return append(args, upstream), nil
+}
+
+// RebaseContinueArgs returns the arguments to continue a rebase without opening an editor
+func RebaseContinueArgs() []string {
+	return []string{"-c", "core.editor=true", "rebase", "--continue"}
+}
+
+// RunRebaseCommand runs a git rebase command and reports the resulting rebase state.
+// An error is only returned if the command failed and no rebase is in progress,
+// stopping at a conflict is reported through the state.
+func RunRebaseCommand(ctx context.Context, repoPath string, args ...string) (*RebaseState, error) {
+	output, cmdErr := RunGitCommand(ctx, repoPath, args...)
+
+	state, err := ReadRebaseState(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmdErr != nil {
+		if !state.InProgress {
+			return nil, cmdErr
+		}
+		output = cmdErr.Error()
+	}
+
+	state.Output = output
+	return state, nil
+}
+
+// ReadRebaseState inspects the repository's git directory for an in-progress rebase
+func ReadRebaseState(ctx context.Context, repoPath string) (*RebaseState, error) {
+	gitDirOutput, err := RunGitCommand(ctx, repoPath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(gitDirOutput)
+
+	state := &RebaseState{}
+
+	// The merge backend (default) uses rebase-merge, the apply backend uses rebase-apply
+	if stateDir := filepath.Join(gitDir, "rebase-merge"); isDir(stateDir) {
+		state.InProgress = true
+		state.HeadName = readStateFile(stateDir, "head-name")
+		state.Onto = readStateFile(stateDir, "onto")
+		state.Done = countTodoLines(readStateFile(stateDir, "done"))
+		state.Remaining = countTodoLines(readStateFile(stateDir, "git-rebase-todo"))
+	} else if stateDir := filepath.Join(gitDir, "rebase-apply"); isDir(stateDir) {
+		state.InProgress = true
+		state.HeadName = readStateFile(stateDir, "head-name")
+		state.Onto = readStateFile(stateDir, "onto")
+		next, _ := strconv.Atoi(readStateFile(stateDir, "next"))
+		last, _ := strconv.Atoi(readStateFile(stateDir, "last"))
+		state.Done = next
+		state.Remaining = last - next
+	}
+
+	if !state.InProgress {
+		return state, nil
+	}
+
+	state.CurrentCommit = readStateFile(gitDir, "REBASE_HEAD")
+	if state.CurrentCommit != "" {
+		subject, err := RunGitCommand(ctx, repoPath, "log", "-1", "--format=%s", state.CurrentCommit)
+		if err == nil {
+			state.CurrentSubject = strings.TrimSpace(subject)
+		}
+	}
+
+	state.ConflictedFiles, err = ListConflictedFiles(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// readStateFile returns the trimmed content of a rebase state file, or "" if it doesn't exist
+func readStateFile(dir string, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// countTodoLines counts the commands in a rebase todo list, ignoring comments and blank lines
+func countTodoLines(todo string) int {
+	count := 0
+	for _, line := range strings.Split(todo, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			count++
+		}
+	}
+	return count
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,10 +1,12 @@
 package shell
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/geropl/git-mcp-go/pkg/gitops"
 )
@@ -17,29 +19,74 @@ func NewShellGitOperations() *ShellGitOperations {
 	return &ShellGitOperations{}
 }
 
-// GetStatus returns the status of the working tree
-func (s *ShellGitOperations) GetStatus(repoPath string) (string, error) {
-	return gitops.RunGitCommand(repoPath, "status")
+// GetStatus returns the status of each changed file
+func (s *ShellGitOperations) GetStatus(ctx context.Context, repoPath string) ([]gitops.FileStatus, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, gitops.StatusArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	return gitops.ParseStatusPorcelain(output)
 }
 
 // GetDiffUnstaged returns the diff of unstaged changes
-func (s *ShellGitOperations) GetDiffUnstaged(repoPath string) (string, error) {
-	return gitops.RunGitCommand(repoPath, "diff")
+func (s *ShellGitOperations) GetDiffUnstaged(ctx context.Context, repoPath string) ([]gitops.DiffFile, error) {
+	return runDiff(ctx, repoPath, gitops.DiffArgs([]string{"diff"}))
 }
 
 // GetDiffStaged returns the diff of staged changes
-func (s *ShellGitOperations) GetDiffStaged(repoPath string) (string, error) {
-	return gitops.RunGitCommand(repoPath, "diff", "--cached")
+func (s *ShellGitOperations) GetDiffStaged(ctx context.Context, repoPath string) ([]gitops.DiffFile, error) {
+	return runDiff(ctx, repoPath, gitops.DiffArgs([]string{"diff", "--cached"}))
 }
 
 // GetDiff returns the diff between the current state and a target
-func (s *ShellGitOperations) GetDiff(repoPath string, target string) (string, error) {
-	return gitops.RunGitCommand(repoPath, "diff", target)
+func (s *ShellGitOperations) GetDiff(ctx context.Context, repoPath string, target string) ([]gitops.DiffFile, error) {
+	return runDiff(ctx, repoPath, gitops.DiffArgs([]string{"diff"}, target))
+}
+
+// runDiff runs a git command that prints a diff and parses its output
+func runDiff(ctx context.Context, repoPath string, args []string) ([]gitops.DiffFile, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	return gitops.ParseDiff(output)
 }
 
 // CommitChanges commits the staged changes
-func (s *ShellGitOperations) CommitChanges(repoPath string, message string) (string, error) {
-	output, err := gitops.RunGitCommand(repoPath, "commit", "-m", message)
+func (s *ShellGitOperations) CommitChanges(ctx context.Context, repoPath string, message string, opts gitops.CommitOptions) (string, error) {
+	var args []string
+	if opts.Committer != nil {
+		args = append(args, "-c", "user.name="+opts.Committer.Name, "-c", "user.email="+opts.Committer.Email)
+	}
+	args = append(args, "commit")
+	if opts.Amend && message == "" {
+		args = append(args, "--no-edit")
+	} else {
+		args = append(args, "-m", message)
+	}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	if opts.All {
+		args = append(args, "--all")
+	}
+	if opts.Author != nil {
+		args = append(args, "--author="+opts.Author.String())
+	}
+	if !opts.Date.IsZero() {
+		args = append(args, "--date="+opts.Date.Format(time.RFC3339))
+	}
+	if opts.Sign {
+		args = append(args, "-S")
+	}
+	for _, trailer := range opts.Trailers {
+		args = append(args, "--trailer", trailer)
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to commit: %w", err)
 	}
@@ -47,9 +94,11 @@ func (s *ShellGitOperations) CommitChanges(repoPath string, message string) (str
 }
 
 // AddFiles adds files to the staging area
-func (s *ShellGitOperations) AddFiles(repoPath string, files []string) (string, error) {
-	args := append([]string{"add"}, files...)
-	_, err := gitops.RunGitCommand(repoPath, args...)
+func (s *ShellGitOperations) AddFiles(ctx context.Context, repoPath string, files []string, opts gitops.AddOptions) (string, error) {
+	if err := gitops.ValidateAddOptions(files, opts); err != nil {
+		return "", err
+	}
+	_, err := gitops.RunGitCommand(ctx, repoPath, gitops.AddArgs(files, opts)...)
 	if err != nil {
 		return "", fmt.Errorf("failed to add files: %w", err)
 	}
@@ -57,39 +106,102 @@ func (s *ShellGitOperations) AddFiles(repoPath string, files []string) (string,
 }
 
 // ResetStaged unstages all staged changes
-func (s *ShellGitOperations) ResetStaged(repoPath string) (string, error) {
-	_, err := gitops.RunGitCommand(repoPath, "reset")
+func (s *ShellGitOperations) ResetStaged(ctx context.Context, repoPath string) (string, error) {
+	_, err := gitops.RunGitCommand(ctx, repoPath, "reset")
 	if err != nil {
 		return "", fmt.Errorf("failed to reset staged changes: %w", err)
 	}
 	return "All staged changes reset", nil
 }
 
+// Restore restores paths in the working tree or the index
+func (s *ShellGitOperations) Restore(ctx context.Context, repoPath string, paths []string, opts gitops.RestoreOptions) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no paths to restore")
+	}
+	args := gitops.RestoreArgs(paths, opts)
+	if opts.Staged && !opts.Worktree && opts.Source == "" {
+		// git restore can't unstage before the first commit, there is no HEAD to restore from
+		if _, err := gitops.RunGitCommand(ctx, repoPath, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+			args = append([]string{"rm", "--cached", "-r", "--quiet", "--"}, paths...)
+		}
+	}
+
+	_, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore files: %w", err)
+	}
+	return "Files restored successfully", nil
+}
+
+// StageHunks stages hunks of the unstaged changes, or unstages hunks of the
+// staged changes, selected by the IDs returned by gitops.ParseHunks
+func (s *ShellGitOperations) StageHunks(ctx context.Context, repoPath string, ids []string, unstage bool) (string, error) {
+	var files []gitops.DiffFile
+	var err error
+	if unstage {
+		files, err = s.GetDiffStaged(ctx, repoPath)
+	} else {
+		files, err = s.GetDiffUnstaged(ctx, repoPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	hunks, err := gitops.ParseHunks(files)
+	if err != nil {
+		return "", err
+	}
+	selected, err := gitops.SelectHunks(hunks, ids)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"apply", "--cached", "--whitespace=nowarn"}
+	message := "Hunks staged successfully"
+	if unstage {
+		args = append(args, "--reverse")
+		message = "Hunks unstaged successfully"
+	}
+	_, err = gitops.RunGitCommandWithInput(ctx, repoPath, gitops.FormatHunkPatch(selected, unstage), args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply hunks: %w", err)
+	}
+	return message, nil
+}
+
+// ApplyPatch applies a patch to the working tree or the index and reports
+// which files it applies to
+func (s *ShellGitOperations) ApplyPatch(ctx context.Context, repoPath string, patch string, opts gitops.ApplyOptions) (*gitops.ApplyResult, error) {
+	return gitops.RunApply(ctx, repoPath, patch, opts)
+}
+
+// ApplyMailbox commits the patches of a mailbox, leaving the repository
+// unchanged if one of them doesn't apply
+func (s *ShellGitOperations) ApplyMailbox(ctx context.Context, repoPath string, mailbox string, opts gitops.AmOptions) (string, error) {
+	return gitops.RunAm(ctx, repoPath, mailbox, opts)
+}
+
 // GetLog returns the commit history
-func (s *ShellGitOperations) GetLog(repoPath string, maxCount int) ([]string, error) {
-	args := []string{"log", "--pretty=format:Commit: %H%nAuthor: %an <%ae>%nDate: %ad%nMessage: %s%n"}
-	if maxCount > 0 {
-		args = append(args, fmt.Sprintf("-n%d", maxCount))
+func (s *ShellGitOperations) GetLog(ctx context.Context, repoPath string, opts gitops.LogOptions) ([]gitops.CommitInfo, error) {
+	if err := gitops.ValidateLogOptions(opts); err != nil {
+		return nil, err
 	}
-	
-	output, err := gitops.RunGitCommand(repoPath, args...)
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, gitops.LogArgs(opts)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get log: %w", err)
 	}
-	
-	// Split the output into individual commit entries
-	logs := strings.Split(strings.TrimSpace(output), "\n\n")
-	return logs, nil
+	return gitops.ParseLog(output)
 }
 
 // CreateBranch creates a new branch
-func (s *ShellGitOperations) CreateBranch(repoPath string, branchName string, baseBranch string) (string, error) {
+func (s *ShellGitOperations) CreateBranch(ctx context.Context, repoPath string, branchName string, baseBranch string) (string, error) {
 	args := []string{"branch", branchName}
 	if baseBranch != "" {
 		args = append(args, baseBranch)
 	}
 	
-	_, err := gitops.RunGitCommand(repoPath, args...)
+	_, err := gitops.RunGitCommand(ctx, repoPath, args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to create branch: %w", err)
 	}
@@ -97,7 +209,7 @@ func (s *ShellGitOperations) CreateBranch(repoPath string, branchName string, ba
 	baseRef := baseBranch
 	if baseRef == "" {
 		// Get the current branch name
-		currentBranch, err := gitops.RunGitCommand(repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+		currentBranch, err := gitops.RunGitCommand(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD")
 		if err != nil {
 			baseRef = "HEAD"
 		} else {
@@ -109,8 +221,8 @@ func (s *ShellGitOperations) CreateBranch(repoPath string, branchName string, ba
 }
 
 // CheckoutBranch switches to a branch
-func (s *ShellGitOperations) CheckoutBranch(repoPath string, branchName string) (string, error) {
-	_, err := gitops.RunGitCommand(repoPath, "checkout", branchName)
+func (s *ShellGitOperations) CheckoutBranch(ctx context.Context, repoPath string, branchName string) (string, error) {
+	_, err := gitops.RunGitCommand(ctx, repoPath, "checkout", branchName)
 	if err != nil {
 		return "", fmt.Errorf("failed to checkout branch: %w", err)
 	}
@@ -119,14 +231,14 @@ func (s *ShellGitOperations) CheckoutBranch(repoPath string, branchName string)
 }
 
 // InitRepo initializes a new Git repository
-func (s *ShellGitOperations) InitRepo(repoPath string) (string, error) {
+func (s *ShellGitOperations) InitRepo(ctx context.Context, repoPath string) (string, error) {
 	// Create directory if it doesn't exist
 	err := os.MkdirAll(repoPath, 0755)
 	if err != nil {
 		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
 	
-	_, err = gitops.RunGitCommand(repoPath, "init")
+	_, err = gitops.RunGitCommand(ctx, repoPath, "init")
 	if err != nil {
 		return "", fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -136,12 +248,12 @@ func (s *ShellGitOperations) InitRepo(repoPath string) (string, error) {
 }
 
 // ShowCommit shows the contents of a commit
-func (s *ShellGitOperations) ShowCommit(repoPath string, revision string) (string, error) {
-	return gitops.RunGitCommand(repoPath, "show", revision)
+func (s *ShellGitOperations) ShowCommit(ctx context.Context, repoPath string, revision string) (string, error) {
+	return gitops.RunGitCommand(ctx, repoPath, gitops.ShowArgs(revision)...)
 }
 
 // PushChanges pushes local commits to a remote repository
-func (s *ShellGitOperations) PushChanges(repoPath string, remote string, branch string) (string, error) {
+func (s *ShellGitOperations) PushChanges(ctx context.Context, repoPath string, remote string, branch string) (string, error) {
 	args := []string{"push"}
 	if remote != "" {
 		args = append(args, remote)
@@ -150,7 +262,7 @@ func (s *ShellGitOperations) PushChanges(repoPath string, remote string, branch
 		args = append(args, branch)
 	}
 	
-	output, err := gitops.RunGitCommand(repoPath, args...)
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to push changes: %w", err)
 	}
@@ -166,3 +278,509 @@ func (s *ShellGitOperations) PushChanges(repoPath string, remote string, branch
 		branch, 
 		output), nil
 }
+
+// StashPush saves local modifications to a new stash entry
+func (s *ShellGitOperations) StashPush(ctx context.Context, repoPath string, message string, includeUntracked bool) (string, error) {
+	args := []string{"stash", "push"}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to stash changes: %w", err)
+	}
+	return output, nil
+}
+
+// StashList returns the entries of the stash list
+func (s *ShellGitOperations) StashList(ctx context.Context, repoPath string) ([]gitops.StashEntry, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, "stash", "list", gitops.StashListFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	return gitops.ParseStashList(output)
+}
+
+// StashShow shows the changes recorded in a stash entry as a patch
+func (s *ShellGitOperations) StashShow(ctx context.Context, repoPath string, index int) ([]gitops.DiffFile, error) {
+	files, err := runDiff(ctx, repoPath, gitops.DiffArgs([]string{"stash", "show", "-p"}, gitops.StashRef(index)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to show stash: %w", err)
+	}
+	return files, nil
+}
+
+// StashApply applies a stash entry on top of the working tree
+func (s *ShellGitOperations) StashApply(ctx context.Context, repoPath string, index int) (string, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, "stash", "apply", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to apply stash: %w", err)
+	}
+	return output, nil
+}
+
+// StashPop applies a stash entry and removes it from the stash list
+func (s *ShellGitOperations) StashPop(ctx context.Context, repoPath string, index int) (string, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, "stash", "pop", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to pop stash: %w", err)
+	}
+	return output, nil
+}
+
+// StashDrop removes a stash entry from the stash list
+func (s *ShellGitOperations) StashDrop(ctx context.Context, repoPath string, index int) (string, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, "stash", "drop", gitops.StashRef(index))
+	if err != nil {
+		return "", fmt.Errorf("failed to drop stash: %w", err)
+	}
+	return output, nil
+}
+
+// MergeBranch merges a branch into the current branch.
+// If the merge stops with conflicts, a *gitops.ConflictError is returned.
+func (s *ShellGitOperations) MergeBranch(ctx context.Context, repoPath string, branch string, mode gitops.MergeMode, message string) (string, error) {
+	args, err := gitops.MergeArgs(branch, mode, message)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := gitops.NewConflictError(ctx, repoPath, "merge", err.Error()); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to merge: %w", err)
+	}
+	return output, nil
+}
+
+// MergeAbort aborts an in-progress merge and restores the pre-merge state
+func (s *ShellGitOperations) MergeAbort(ctx context.Context, repoPath string) (string, error) {
+	_, err := gitops.RunGitCommand(ctx, repoPath, "merge", "--abort")
+	if err != nil {
+		return "", fmt.Errorf("failed to abort merge: %w", err)
+	}
+	return "Merge aborted", nil
+}
+
+// Rebase rebases the current branch onto upstream, or onto a different base if onto is set
+func (s *ShellGitOperations) Rebase(ctx context.Context, repoPath string, upstream string, onto string, autosquash bool) (*gitops.RebaseState, error) {
+	args, err := gitops.RebaseArgs(upstream, onto, autosquash)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebase: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseContinue continues an in-progress rebase after conflicts have been resolved
+func (s *ShellGitOperations) RebaseContinue(ctx context.Context, repoPath string) (*gitops.RebaseState, error) {
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, gitops.RebaseContinueArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to continue rebase: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseSkip skips the commit currently being applied and continues the rebase
+func (s *ShellGitOperations) RebaseSkip(ctx context.Context, repoPath string) (*gitops.RebaseState, error) {
+	state, err := gitops.RunRebaseCommand(ctx, repoPath, "rebase", "--skip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to skip commit: %w", err)
+	}
+	return state, nil
+}
+
+// RebaseAbort aborts an in-progress rebase and restores the original branch
+func (s *ShellGitOperations) RebaseAbort(ctx context.Context, repoPath string) (string, error) {
+	_, err := gitops.RunGitCommand(ctx, repoPath, "rebase", "--abort")
+	if err != nil {
+		return "", fmt.Errorf("failed to abort rebase: %w", err)
+	}
+	return "Rebase aborted", nil
+}
+
+// CherryPick applies the changes introduced by the given revisions on top of HEAD.
+// If a pick stops with conflicts, a *gitops.ConflictError is returned.
+func (s *ShellGitOperations) CherryPick(ctx context.Context, repoPath string, revisions []string, recordOrigin bool, noCommit bool) (string, error) {
+	args, err := gitops.CherryPickArgs(revisions, recordOrigin, noCommit)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := gitops.NewConflictError(ctx, repoPath, "cherry-pick", err.Error()); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to cherry-pick: %w", err)
+	}
+	return output, nil
+}
+
+// Revert records new commits that undo the changes introduced by the given revisions.
+// If a revert stops with conflicts, a *gitops.ConflictError is returned.
+func (s *ShellGitOperations) Revert(ctx context.Context, repoPath string, revisions []string, noCommit bool) (string, error) {
+	args, err := gitops.RevertArgs(revisions, noCommit)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := gitops.NewConflictError(ctx, repoPath, "revert", err.Error()); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to revert: %w", err)
+	}
+	return output, nil
+}
+
+// ListTags lists the tags matching pattern (all tags if empty) in the given order
+func (s *ShellGitOperations) ListTags(ctx context.Context, repoPath string, pattern string, sortBy gitops.TagSort) ([]gitops.TagInfo, error) {
+	sortArg, err := gitops.TagSortArg(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"tag", "--list", gitops.TagListFormat, sortArg}
+	if pattern != "" {
+		args = append(args, pattern)
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return gitops.ParseTagList(output)
+}
+
+// CreateTag creates a tag on revision (HEAD if empty).
+// The tag is annotated if a message is given, lightweight otherwise.
+func (s *ShellGitOperations) CreateTag(ctx context.Context, repoPath string, name string, revision string, message string) (string, error) {
+	if err := gitops.ValidateArgument("tag name", name); err != nil {
+		return "", err
+	}
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return "", err
+	}
+
+	args := []string{"tag"}
+	if message != "" {
+		args = append(args, "-a", "-m", message)
+	}
+	args = append(args, name)
+	if revision != "" {
+		args = append(args, revision)
+	}
+
+	_, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	kind := "lightweight"
+	if message != "" {
+		kind = "annotated"
+	}
+	return fmt.Sprintf("Created %s tag '%s' on '%s'", kind, name, revision), nil
+}
+
+// DeleteTag deletes a tag
+func (s *ShellGitOperations) DeleteTag(ctx context.Context, repoPath string, name string) (string, error) {
+	if err := gitops.ValidateArgument("tag name", name); err != nil {
+		return "", err
+	}
+	_, err := gitops.RunGitCommand(ctx, repoPath, "tag", "-d", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return fmt.Sprintf("Deleted tag '%s'", name), nil
+}
+
+// PushTags pushes tags to a remote repository. All tags are pushed if none are given.
+func (s *ShellGitOperations) PushTags(ctx context.Context, repoPath string, remote string, tags []string) (string, error) {
+	if err := gitops.ValidateArgument("remote", remote); err != nil {
+		return "", err
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+
+	args := []string{"push", remote}
+	if len(tags) == 0 {
+		args = append(args, "--tags")
+	}
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag)
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to push tags: %w", err)
+	}
+
+	if strings.Contains(output, "up-to-date") {
+		return output, nil
+	}
+
+	return fmt.Sprintf("Successfully pushed tags to %s\n%s", remote, output), nil
+}
+
+// ListBranches lists local branches, and remote-tracking branches if includeRemote is set
+func (s *ShellGitOperations) ListBranches(ctx context.Context, repoPath string, includeRemote bool) ([]gitops.BranchInfo, error) {
+	args := []string{"for-each-ref", gitops.BranchListFormat, "refs/heads"}
+	if includeRemote {
+		args = append(args, "refs/remotes")
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return gitops.ParseBranchList(output)
+}
+
+// DeleteBranch deletes a local branch. Branches that are not fully merged are
+// only deleted if force is set.
+func (s *ShellGitOperations) DeleteBranch(ctx context.Context, repoPath string, branchName string, force bool) (string, error) {
+	if err := gitops.ValidateArgument("branch name", branchName); err != nil {
+		return "", err
+	}
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+
+	_, err := gitops.RunGitCommand(ctx, repoPath, "branch", flag, branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	return fmt.Sprintf("Deleted branch '%s'", branchName), nil
+}
+
+// RenameBranch renames a local branch, moving its configuration and reflog
+func (s *ShellGitOperations) RenameBranch(ctx context.Context, repoPath string, oldName string, newName string) (string, error) {
+	for _, name := range []string{oldName, newName} {
+		if err := gitops.ValidateArgument("branch name", name); err != nil {
+			return "", err
+		}
+	}
+	_, err := gitops.RunGitCommand(ctx, repoPath, "branch", "-m", oldName, newName)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename branch: %w", err)
+	}
+
+	return fmt.Sprintf("Renamed branch '%s' to '%s'", oldName, newName), nil
+}
+
+// SetUpstream sets the upstream of a local branch (the current branch if empty)
+func (s *ShellGitOperations) SetUpstream(ctx context.Context, repoPath string, branchName string, upstream string) (string, error) {
+	if err := gitops.ValidateArgument("branch name", branchName); err != nil {
+		return "", err
+	}
+	if err := gitops.ValidateArgument("upstream", upstream); err != nil {
+		return "", err
+	}
+	args := []string{"branch", "--set-upstream-to=" + upstream}
+	if branchName != "" {
+		args = append(args, branchName)
+	}
+
+	_, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to set upstream: %w", err)
+	}
+
+	if branchName == "" {
+		currentBranch, err := gitops.RunGitCommand(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+		if err == nil {
+			branchName = strings.TrimSpace(currentBranch)
+		}
+	}
+	return fmt.Sprintf("Branch '%s' set up to track '%s'", branchName, upstream), nil
+}
+
+// Fetch downloads objects and refs from a remote repository
+func (s *ShellGitOperations) Fetch(ctx context.Context, repoPath string, remote string, refspec string, prune bool, tags bool) (string, error) {
+	args, err := gitops.FetchArgs(remote, refspec, prune, tags)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	if strings.TrimSpace(output) == "" {
+		return "Already up to date", nil
+	}
+	return fmt.Sprintf("Successfully fetched\n%s", output), nil
+}
+
+// Pull fetches from a remote repository and integrates the changes into the current branch.
+// If the integration stops with conflicts, a *gitops.ConflictError is returned.
+func (s *ShellGitOperations) Pull(ctx context.Context, repoPath string, remote string, branch string, strategy gitops.PullStrategy, ffOnly bool) (string, error) {
+	args, err := gitops.PullArgs(remote, branch, strategy, ffOnly)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		if conflictErr := gitops.NewConflictError(ctx, repoPath, "pull", err.Error()); conflictErr != nil {
+			return "", conflictErr
+		}
+		return "", fmt.Errorf("failed to pull: %w", err)
+	}
+	return output, nil
+}
+
+// CloneRepo clones a repository into targetPath
+func (s *ShellGitOperations) CloneRepo(ctx context.Context, url string, targetPath string, branch string, depth int, filter string) (string, error) {
+	// Create the parent directory if it doesn't exist
+	parentDir := filepath.Dir(targetPath)
+	err := os.MkdirAll(parentDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	_, err = gitops.RunGitCommand(ctx, parentDir, gitops.CloneArgs(url, targetPath, branch, depth, filter)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	return fmt.Sprintf("Cloned %s into %s", url, targetPath), nil
+}
+
+// Blame returns the commit that last changed each line of a file, optionally
+// limited to a line range. A startLine or endLine of 0 leaves that end of the range open.
+func (s *ShellGitOperations) Blame(ctx context.Context, repoPath string, filePath string, revision string, startLine int, endLine int) ([]gitops.BlameLine, error) {
+	if err := gitops.ValidateLineRange(startLine, endLine); err != nil {
+		return nil, err
+	}
+
+	args, err := gitops.BlameArgs(filePath, revision, startLine, endLine)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := gitops.RunGitCommand(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file: %w", err)
+	}
+
+	return gitops.ParseBlamePorcelain(output)
+}
+
+// ReadFileAtRevision reads a file as it exists at a revision, optionally limited
+// to a line range. A startLine or endLine of 0 leaves that end of the range open.
+func (s *ShellGitOperations) ReadFileAtRevision(ctx context.Context, repoPath string, revision string, filePath string, startLine int, endLine int) (*gitops.FileContent, error) {
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+	filePath = gitops.NormalizeRevisionPath(filePath)
+
+	commit, err := gitops.RunGitCommand(ctx, repoPath, "rev-parse", "--verify", revision+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+
+	// Paths in rev:path are relative to the repository root
+	object := revision + ":" + filePath
+	hash, err := gitops.RunGitCommand(ctx, repoPath, "rev-parse", "--verify", object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s at %s: %w", filePath, revision, err)
+	}
+	objectType, err := gitops.RunGitCommand(ctx, repoPath, "cat-file", "-t", strings.TrimSpace(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, revision, err)
+	}
+	if strings.TrimSpace(objectType) != "blob" {
+		return nil, fmt.Errorf("%s is not a file at %s", filePath, revision)
+	}
+
+	content, err := gitops.RunGitCommand(ctx, repoPath, "show", object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, revision, err)
+	}
+
+	file := &gitops.FileContent{
+		Path:     filePath,
+		Revision: strings.TrimSpace(commit),
+		Hash:     strings.TrimSpace(hash),
+	}
+	if err := gitops.SetFileContent(file, []byte(content), startLine, endLine); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ListTree lists the entries of a tree at a revision. The contents of the directory
+// pathPrefix are listed, or the root if it is empty. Recursive listings contain
+// files and submodules but no directories.
+func (s *ShellGitOperations) ListTree(ctx context.Context, repoPath string, revision string, pathPrefix string, recursive bool, pattern string) ([]gitops.TreeEntry, error) {
+	if err := gitops.ValidateArgument("revision", revision); err != nil {
+		return nil, err
+	}
+	output, err := gitops.RunGitCommand(ctx, repoPath, gitops.LsTreeArgs(revision, pathPrefix, recursive)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree: %w", err)
+	}
+
+	entries, err := gitops.ParseLsTree(output)
+	if err != nil {
+		return nil, err
+	}
+	return gitops.FilterTreeEntries(entries, pattern)
+}
+
+// ListFiles lists the files in the index, optionally with untracked and ignored files
+func (s *ShellGitOperations) ListFiles(ctx context.Context, repoPath string, pattern string, includeUntracked bool, includeIgnored bool) ([]gitops.IndexFile, error) {
+	output, err := gitops.RunGitCommand(ctx, repoPath, "ls-files", "-z", "--cached")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	files := gitops.ParseLsFiles(output, gitops.IndexFileTracked)
+
+	if includeUntracked {
+		output, err := gitops.RunGitCommand(ctx, repoPath, "ls-files", "-z", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list untracked files: %w", err)
+		}
+		files = append(files, gitops.ParseLsFiles(output, gitops.IndexFileUntracked)...)
+	}
+
+	if includeIgnored {
+		// Ignored directories are listed once instead of file by file
+		output, err := gitops.RunGitCommand(ctx, repoPath, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ignored files: %w", err)
+		}
+		files = append(files, gitops.ParseLsFiles(output, gitops.IndexFileIgnored)...)
+	}
+
+	return gitops.FilterIndexFiles(files, pattern)
+}
+
+// Grep searches the tracked files in the working tree, or the files at a revision
+func (s *ShellGitOperations) Grep(ctx context.Context, repoPath string, opts gitops.GrepOptions) (*gitops.GrepResult, error) {
+	return gitops.RunGrepCommand(ctx, repoPath, opts)
+}
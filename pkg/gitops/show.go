@@ -0,0 +1,72 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// showDateFormat is the default date format of git
+const showDateFormat = "Mon Jan 2 15:04:05 2006 -0700"
+
+// ShowArgs are the arguments for `git show` of a revision. The output matches
+// the one rendered by FormatShowCommit, FormatShowTag and FormatShowTree: merge
+// commits are shown with the changes to their first parent.
+func ShowArgs(revision string) []string {
+	return DiffArgs([]string{"show", "--format=medium", "--date=default", "--no-abbrev", "--no-decorate",
+		"--no-notes", "--no-show-signature", "--diff-merges=first-parent"}, revision)
+}
+
+// TagObject describes an annotated tag shown by FormatShowTag
+type TagObject struct {
+	Name        string
+	Tagger      string
+	TaggerEmail string
+	Date        time.Time
+	Message     string // message as stored, ending with a newline
+}
+
+// FormatShowCommit renders a commit and its changes, formatted with FormatPatch,
+// like `git show` run with ShowArgs
+func FormatShowCommit(commit CommitInfo, patch string) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("commit %s\n", commit.Hash))
+	if len(commit.Parents) > 1 {
+		result.WriteString(fmt.Sprintf("Merge: %s\n", strings.Join(commit.Parents, " ")))
+	}
+	result.WriteString(fmt.Sprintf("Author: %s <%s>\n", commit.Author, commit.AuthorEmail))
+	result.WriteString(fmt.Sprintf("Date:   %s\n\n", commit.Date.Format(showDateFormat)))
+	for _, line := range strings.Split(commit.Message, "\n") {
+		result.WriteString("    " + line + "\n")
+	}
+	if patch != "" {
+		result.WriteString("\n" + patch)
+	}
+	return result.String()
+}
+
+// FormatShowTag renders an annotated tag like `git show` run with ShowArgs,
+// followed by target, the rendered object the tag points to
+func FormatShowTag(tag TagObject, target string) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("tag %s\n", tag.Name))
+	result.WriteString(fmt.Sprintf("Tagger: %s <%s>\n", tag.Tagger, tag.TaggerEmail))
+	result.WriteString(fmt.Sprintf("Date:   %s\n\n", tag.Date.Format(showDateFormat)))
+	result.WriteString(tag.Message)
+	if tag.Message != "" && !strings.HasSuffix(tag.Message, "\n") {
+		result.WriteString("\n")
+	}
+	result.WriteString("\n" + target)
+	return result.String()
+}
+
+// FormatShowTree renders the entries of a tree like `git show` run with ShowArgs.
+// Names of subtrees end with "/".
+func FormatShowTree(revision string, names []string) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("tree %s\n\n", revision))
+	for _, name := range names {
+		result.WriteString(name + "\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,80 @@
+package gitops
+
+import "fmt"
+
+// AddMode selects which changes AddFiles stages
+type AddMode string
+
+const (
+	// AddModePaths stages all changes of the given paths, including new files.
+	// It is the default, an empty mode selects it as well.
+	AddModePaths AddMode = "paths"
+	// AddModeUpdate stages the changes of tracked files only, like `git add -u`
+	AddModeUpdate AddMode = "update"
+	// AddModeAll stages all changes, including new files, like `git add -A`
+	AddModeAll AddMode = "all"
+)
+
+// AddOptions controls how AddFiles stages changes. The paths given to AddFiles
+// are pathspecs, which may contain wildcards (see MatchPathspec). In the update
+// and all modes they are optional and limit the changes to stage.
+type AddOptions struct {
+	Mode        AddMode
+	IntentToAdd bool // only record that new files will be added later, like `git add -N`
+}
+
+// ValidateAddOptions checks that the options of AddFiles can be combined
+func ValidateAddOptions(paths []string, opts AddOptions) error {
+	switch opts.Mode {
+	case "", AddModePaths:
+		if len(paths) == 0 {
+			return fmt.Errorf("no paths to add")
+		}
+	case AddModeUpdate, AddModeAll:
+		if opts.IntentToAdd {
+			return fmt.Errorf("intent-to-add can only be used with paths")
+		}
+	default:
+		return fmt.Errorf("unknown add mode %q", opts.Mode)
+	}
+	return nil
+}
+
+// AddArgs returns the arguments of `git add`
+func AddArgs(paths []string, opts AddOptions) []string {
+	args := []string{"add"}
+	switch opts.Mode {
+	case AddModeUpdate:
+		args = append(args, "--update")
+	case AddModeAll:
+		args = append(args, "--all")
+	}
+	if opts.IntentToAdd {
+		args = append(args, "--intent-to-add")
+	}
+	args = append(args, "--")
+	return append(args, paths...)
+}
+
+// RestoreOptions selects what Restore restores paths in, and from where
+type RestoreOptions struct {
+	Source   string // revision to restore from, the index for the working tree and HEAD for the index if empty
+	Staged   bool   // restore the index
+	Worktree bool   // restore the working tree, the default if neither Staged nor Worktree is set
+}
+
+// RestoreArgs returns the arguments of `git restore`
+func RestoreArgs(paths []string, opts RestoreOptions) []string {
+	args := []string{"restore"}
+	if opts.Source != "" {
+		args = append(args, "--source="+opts.Source)
+	}
+	if opts.Staged {
+		args = append(args, "--staged")
+	}
+	if opts.Worktree {
+		args = append(args, "--worktree")
+	}
+	args = append(args, "--")
+	return append(args, paths...)
+}
@@ -0,0 +1,80 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StashListFormat is the pretty format used to list stash entries.
+// Fields are separated by NUL bytes: reflog selector, then reflog subject.
+const StashListFormat = "--format=%gd%x00%gs"
+
+// StashEntry represents a single entry of the stash list
+type StashEntry struct {
+	Index   int    `json:"index"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+}
+
+// StashRef returns the reflog selector for the stash entry at index
+func StashRef(index int) string {
+	return fmt.Sprintf("stash@{%d}", index)
+}
+
+// ParseStashList parses the output of `git stash list` run with StashListFormat
+func ParseStashList(output string) ([]StashEntry, error) {
+	var entries []StashEntry
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		selector, subject, found := strings.Cut(line, "\x00")
+		if !found {
+			return nil, fmt.Errorf("unexpected stash list line: %q", line)
+		}
+
+		index, err := parseStashSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		branch, message := parseStashSubject(subject)
+		entries = append(entries, StashEntry{
+			Index:   index,
+			Branch:  branch,
+			Message: message,
+		})
+	}
+	return entries, nil
+}
+
+// parseStashSelector extracts N from a "stash@{N}" selector
+func parseStashSelector(selector string) (int, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(selector, "stash@{"), "}")
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected stash selector: %q", selector)
+	}
+	return index, nil
+}
+
+// parseStashSubject splits a stash reflog subject such as
+// "WIP on main: 1234abc subject" or "On main: message" into branch and message
+func parseStashSubject(subject string) (string, string) {
+	rest := subject
+	if after, ok := strings.CutPrefix(rest, "WIP on "); ok {
+		rest = after
+	} else if after, ok := strings.CutPrefix(rest, "On "); ok {
+		rest = after
+	} else {
+		return "", subject
+	}
+
+	branch, message, found := strings.Cut(rest, ": ")
+	if !found {
+		return "", subject
+	}
+	return branch, message
+}
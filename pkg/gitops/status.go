@@ -0,0 +1,56 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StatusArgs are the arguments for `git status`, parsed by ParseStatusPorcelain.
+// Untracked directories are expanded to the files they contain.
+var StatusArgs = []string{"status", "--porcelain=v1", "-z", "--untracked-files=all"}
+
+// FileStatus is the status of a changed file, using the codes of `git status --porcelain`:
+// ' ' unmodified, M modified, T type changed, A added, D deleted, R renamed,
+// C copied, U unmerged and ? untracked
+type FileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"` // source of a rename or copy
+	Index    string `json:"index"`               // status in the index
+	Worktree string `json:"worktree"`            // status in the working tree
+}
+
+// ParseStatusPorcelain parses the output of `git status` run with StatusArgs.
+// Files are sorted by path, untracked files are not listed separately.
+func ParseStatusPorcelain(output string) ([]FileStatus, error) {
+	var files []FileStatus
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+		if len(record) < 4 || record[2] != ' ' {
+			return nil, fmt.Errorf("unexpected status entry: %q", record)
+		}
+
+		file := FileStatus{
+			Path:     record[3:],
+			Index:    record[0:1],
+			Worktree: record[1:2],
+		}
+		// Renames and copies are followed by a record with the source path
+		if file.Index == "R" || file.Index == "C" {
+			i++
+			if i >= len(records) {
+				return nil, fmt.Errorf("missing source path of status entry: %q", record)
+			}
+			file.OrigPath = records[i]
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
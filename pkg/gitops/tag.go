@@ -0,0 +1,143 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TagSort selects the order in which tags are listed
+type TagSort string
+
+const (
+	// TagSortName sorts tags alphabetically by name
+	TagSortName TagSort = "name"
+	// TagSortVersion sorts tags treating numeric parts as version numbers (v1.10 after v1.9)
+	TagSortVersion TagSort = "version"
+	// TagSortDate sorts tags by tagger date for annotated tags, commit date otherwise (oldest first)
+	TagSortDate TagSort = "date"
+)
+
+// TagListFormat is the format used to list tags with `git tag --list`.
+// Fields are separated by NUL bytes and parsed by ParseTagList.
+const TagListFormat = "--format=%(refname:strip=2)%00%(objecttype)%00%(objectname)%00%(*objectname)%00%(creatordate:iso-strict)%00%(taggername) %(taggeremail)%00%(contents:subject)"
+
+// TagInfo describes a single tag
+type TagInfo struct {
+	Name      string    `json:"name"`
+	Target    string    `json:"target"` // commit the tag points to
+	Annotated bool      `json:"annotated"`
+	Tagger    string    `json:"tagger,omitempty"` // only set for annotated tags
+	Date      time.Time `json:"date"`
+	Message   string    `json:"message,omitempty"` // subject of the tag message, only set for annotated tags
+}
+
+// TagSortArg returns the `git tag --sort` argument for sortBy
+func TagSortArg(sortBy TagSort) (string, error) {
+	switch sortBy {
+	case "", TagSortName:
+		return "--sort=refname", nil
+	case TagSortVersion:
+		return "--sort=version:refname", nil
+	case TagSortDate:
+		return "--sort=creatordate", nil
+	default:
+		return "", fmt.Errorf("unsupported tag sort order: %s", sortBy)
+	}
+}
+
+// ParseTagList parses the output of `git tag --list` run with TagListFormat
+func ParseTagList(output string) ([]TagInfo, error) {
+	var tags []TagInfo
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unexpected tag list line: %q", line)
+		}
+
+		tag := TagInfo{
+			Name:      fields[0],
+			Target:    fields[2],
+			Annotated: fields[1] == "tag",
+		}
+		if tag.Annotated {
+			tag.Target = fields[3]
+			tag.Tagger = strings.TrimSpace(fields[5])
+			tag.Message = fields[6]
+		}
+		if date, err := time.Parse(time.RFC3339, fields[4]); err == nil {
+			tag.Date = date
+		}
+
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// SortTags sorts tags in place in the given order
+func SortTags(tags []TagInfo, sortBy TagSort) error {
+	switch sortBy {
+	case "", TagSortName:
+		sort.SliceStable(tags, func(i, j int) bool {
+			return tags[i].Name < tags[j].Name
+		})
+	case TagSortVersion:
+		sort.SliceStable(tags, func(i, j int) bool {
+			return CompareVersions(tags[i].Name, tags[j].Name) < 0
+		})
+	case TagSortDate:
+		sort.SliceStable(tags, func(i, j int) bool {
+			return tags[i].Date.Before(tags[j].Date)
+		})
+	default:
+		return fmt.Errorf("unsupported tag sort order: %s", sortBy)
+	}
+	return nil
+}
+
+// CompareVersions compares two version strings, treating runs of digits as numbers.
+// It returns -1, 0 or 1 like strings.Compare.
+func CompareVersions(a string, b string) int {
+	for a != "" && b != "" {
+		aChunk, aRest, aNumeric := nextVersionChunk(a)
+		bChunk, bRest, bNumeric := nextVersionChunk(b)
+
+		if aNumeric && bNumeric {
+			aTrimmed := strings.TrimLeft(aChunk, "0")
+			bTrimmed := strings.TrimLeft(bChunk, "0")
+			if len(aTrimmed) != len(bTrimmed) {
+				if len(aTrimmed) < len(bTrimmed) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(aTrimmed, bTrimmed); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(aChunk, bChunk); c != 0 {
+			return c
+		}
+
+		a, b = aRest, bRest
+	}
+	return strings.Compare(a, b)
+}
+
+// nextVersionChunk splits off the leading run of digits or non-digits of s
+func nextVersionChunk(s string) (string, string, bool) {
+	numeric := isDigit(s[0])
+	end := 1
+	for end < len(s) && isDigit(s[end]) == numeric {
+		end++
+	}
+	return s[:end], s[end:], numeric
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
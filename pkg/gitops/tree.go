@@ -0,0 +1,145 @@
+package gitops
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TreeEntry describes an entry of a tree at a revision
+type TreeEntry struct {
+	Path string `json:"path"` // path relative to the repository root
+	Mode string `json:"mode"` // octal file mode, e.g. "100644"
+	Type string `json:"type"` // "blob", "tree" or "commit" (submodule)
+	Hash string `json:"hash"`
+	Size int64  `json:"size"` // size of the blob in bytes, -1 for trees and submodules
+}
+
+// IndexFileStatus describes how a file listed from the working tree relates to the index
+type IndexFileStatus string
+
+const (
+	// IndexFileTracked is a file in the index
+	IndexFileTracked IndexFileStatus = "tracked"
+	// IndexFileUntracked is a file that is neither in the index nor ignored
+	IndexFileUntracked IndexFileStatus = "untracked"
+	// IndexFileIgnored is a file or directory (with a trailing slash) matched by an ignore rule
+	IndexFileIgnored IndexFileStatus = "ignored"
+)
+
+// IndexFile describes a file listed by ls-files
+type IndexFile struct {
+	Path   string          `json:"path"`
+	Status IndexFileStatus `json:"status"`
+}
+
+// LsTreeArgs builds the arguments for `git ls-tree`, parsed by ParseLsTree.
+// The contents of the directory pathPrefix are listed, or the root if it is empty.
+func LsTreeArgs(revision string, pathPrefix string, recursive bool) []string {
+	args := []string{"ls-tree", "-z", "-l"}
+	if recursive {
+		args = append(args, "-r")
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+	args = append(args, revision)
+
+	pathPrefix = NormalizeRevisionPath(pathPrefix)
+	if pathPrefix != "" {
+		// The trailing slash lists the directory's contents instead of the directory itself
+		args = append(args, "--", pathPrefix+"/")
+	}
+	return args
+}
+
+// ParseLsTree parses the output of `git ls-tree -z -l`
+func ParseLsTree(output string) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for _, record := range strings.Split(output, "\x00") {
+		if record == "" {
+			continue
+		}
+
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		info, entryPath, found := strings.Cut(record, "\t")
+		fields := strings.Fields(info)
+		if !found || len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected ls-tree record: %q", record)
+		}
+
+		entry := TreeEntry{
+			Path: entryPath,
+			Mode: fields[0],
+			Type: fields[1],
+			Hash: fields[2],
+			Size: -1,
+		}
+		if fields[3] != "-" {
+			size, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected ls-tree record: %q", record)
+			}
+			entry.Size = size
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ParseLsFiles parses the NUL-separated output of `git ls-files -z`
+func ParseLsFiles(output string, status IndexFileStatus) []IndexFile {
+	var files []IndexFile
+	for _, filePath := range strings.Split(output, "\x00") {
+		if filePath != "" {
+			files = append(files, IndexFile{Path: filePath, Status: status})
+		}
+	}
+	return files
+}
+
+// MatchPathPattern reports whether a repository path matches a glob pattern.
+// The pattern is matched against both the full path and the file name, so
+// "*.go" matches Go files in any directory. An empty pattern matches everything.
+func MatchPathPattern(pattern string, filePath string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+
+	matched, err := path.Match(pattern, strings.TrimSuffix(filePath, "/"))
+	if err != nil || matched {
+		return matched, err
+	}
+	return path.Match(pattern, path.Base(filePath))
+}
+
+// FilterTreeEntries returns the entries whose path matches pattern, see MatchPathPattern
+func FilterTreeEntries(entries []TreeEntry, pattern string) ([]TreeEntry, error) {
+	var result []TreeEntry
+	for _, entry := range entries {
+		matched, err := MatchPathPattern(pattern, entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if matched {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// FilterIndexFiles returns the files whose path matches pattern, see MatchPathPattern
+func FilterIndexFiles(files []IndexFile, pattern string) ([]IndexFile, error) {
+	var result []IndexFile
+	for _, file := range files {
+		matched, err := MatchPathPattern(pattern, file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if matched {
+			result = append(result, file)
+		}
+	}
+	return result, nil
+}
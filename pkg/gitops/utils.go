@@ -1,17 +1,40 @@
 package gitops
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
+	"strings"
+	"time"
 )
 
-// RunGitCommand runs a git command and returns its output
-func RunGitCommand(repoPath string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
+// RunGitCommand runs a git command and returns its output. The command is killed
+// when ctx is done.
+func RunGitCommand(ctx context.Context, repoPath string, args ...string) (string, error) {
+	return RunGitCommandWithInput(ctx, repoPath, "", args...)
+}
+
+// RunGitCommandWithInput runs a git command that reads input from stdin, like
+// `git apply`, and returns its output
+func RunGitCommandWithInput(ctx context.Context, repoPath string, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(input)
+	// Processes started by git, like ssh, may keep the output open after git was killed
+	cmd.WaitDelay = time.Second
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git command failed: %w\nOutput: %s", err, string(output))
 	}
 	return string(output), nil
 }
+
+// ValidateArgument checks that a value given by the user, like a revision or the
+// name of a remote, can't be mistaken by git for an option. name describes the
+// value in the error.
+func ValidateArgument(name string, value string) error {
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("invalid %s %q: must not start with '-'", name, value)
+	}
+	return nil
+}
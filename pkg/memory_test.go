@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/geropl/git-mcp-go/pkg/gitops/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	gitOps := memory.NewMemoryGitOperations()
+	require.NoError(t, gitOps.Seed("/repo", memory.Fixture{
+		Commits: []memory.FixtureCommit{
+			{Message: "Add a", Files: map[string]string{"a.txt": "one\n"}},
+			{Message: "Change a", Files: map[string]string{"a.txt": "two\n"}},
+		},
+		Unstaged: map[string]string{"b.txt": "bee\n"},
+	}))
+
+	server := NewGitServer([]string{"/repo", "/missing"}, gitOps, false)
+	server.RegisterTools()
+	require.Equal(t, []string{"/repo"}, server.repoPaths)
+
+	callTool := func(name string, arguments map[string]interface{}) (bool, string) {
+		result := callServer(t, server, "tools/call", map[string]interface{}{
+			"name":      name,
+			"arguments": arguments,
+		})
+		content := result["content"].([]interface{})
+		return result["isError"] == true, content[0].(map[string]interface{})["text"].(string)
+	}
+
+	isError, text := callTool("git_status", map[string]interface{}{"repo_path": "/repo"})
+	require.False(t, isError, text)
+	require.Contains(t, text, "b.txt")
+
+	isError, text = callTool("git_add", map[string]interface{}{"repo_path": "/repo", "files": []interface{}{"*.txt"}})
+	require.False(t, isError, text)
+	isError, text = callTool("git_restore", map[string]interface{}{"repo_path": "/repo", "paths": []interface{}{"b.txt"}, "staged": true})
+	require.False(t, isError, text)
+	isError, text = callTool("git_status", map[string]interface{}{"repo_path": "/repo"})
+	require.False(t, isError, text)
+	require.Contains(t, text, "?? b.txt")
+	isError, text = callTool("git_add", map[string]interface{}{"repo_path": "/repo", "files": "b.txt"})
+	require.False(t, isError, text)
+	isError, text = callTool("git_commit", map[string]interface{}{"repo_path": "/repo", "message": "Add b", "author": "Test"})
+	require.True(t, isError, text)
+	require.Contains(t, text, "Invalid author")
+	isError, text = callTool("git_commit", map[string]interface{}{"repo_path": "/repo", "message": "Add b", "author": "Other Author <other@example.com>"})
+	require.False(t, isError, text)
+	isError, text = callTool("git_commit", map[string]interface{}{"repo_path": "/repo"})
+	require.True(t, isError, text)
+	require.Contains(t, text, "message is required")
+	isError, text = callTool("git_commit", map[string]interface{}{"repo_path": "/repo", "amend": true, "date": "yesterday"})
+	require.True(t, isError, text)
+	require.Contains(t, text, "Invalid date")
+	isError, text = callTool("git_commit", map[string]interface{}{
+		"repo_path": "/repo",
+		"amend":     true,
+		"date":      "2024-02-01T10:00:00Z",
+		"trailers":  []interface{}{"Refs: #1", "Signed-off-by:Other Author <other@example.com>"},
+	})
+	require.False(t, isError, text)
+	isError, text = callTool("git_create_branch", map[string]interface{}{"repo_path": "/repo", "branch_name": "old", "base_branch": "HEAD~2"})
+	require.False(t, isError, text)
+	isError, text = callTool("git_checkout", map[string]interface{}{"repo_path": "/repo", "branch_name": "old"})
+	require.False(t, isError, text)
+
+	isError, text = callTool("git_log", map[string]interface{}{"repo_path": "/repo", "revision": "main", "output_format": "json"})
+	require.False(t, isError, text)
+	var log struct {
+		Result LogResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(text), &log))
+	require.Len(t, log.Result.Commits, 3)
+	require.Equal(t, "Add b\n\nRefs: #1\nSigned-off-by: Other Author <other@example.com>", log.Result.Commits[0].Message)
+	require.Equal(t, "Other Author", log.Result.Commits[0].Author)
+	require.Equal(t, "2024-02-01T10:00:00Z", log.Result.Commits[0].Date.UTC().Format(time.RFC3339))
+
+	repo, err := gitOps.Repository("/repo")
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, "old", head.Name().Short())
+	isError, text = callTool("git_status", map[string]interface{}{"repo_path": "/repo", "output_format": "json"})
+	require.False(t, isError, text)
+	require.Contains(t, text, `"clean":true`)
+
+	// Repositories created by tools are kept in memory as well
+	isError, text = callTool("git_init", map[string]interface{}{"repo_path": "/new"})
+	require.False(t, isError, text)
+	isError, text = callTool("git_list_repositories", map[string]interface{}{})
+	require.False(t, isError, text)
+	require.Contains(t, text, "/new")
+
+	isError, text = callTool("git_stash_push", map[string]interface{}{"repo_path": "/repo"})
+	require.True(t, isError, text)
+	require.Contains(t, text, "requires the git binary")
+
+	isError, text = callTool("git_log", map[string]interface{}{"repo_path": "/missing"})
+	require.True(t, isError, text)
+	require.Contains(t, text, "access denied")
+}
+
+func TestMemoryBackendStageHunks(t *testing.T) {
+	gitOps := memory.NewMemoryGitOperations()
+	require.NoError(t, gitOps.Seed("/repo", memory.Fixture{
+		Commits: []memory.FixtureCommit{
+			{Message: "Add a", Files: map[string]string{"a.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"}},
+		},
+		Unstaged: map[string]string{"a.txt": "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"},
+	}))
+
+	server := NewGitServer([]string{"/repo"}, gitOps, false)
+	server.RegisterTools()
+	stageHunks := func(arguments map[string]interface{}) HunksResult {
+		arguments["repo_path"] = "/repo"
+		arguments["output_format"] = "json"
+		result := callServer(t, server, "tools/call", map[string]interface{}{"name": "git_stage_hunks", "arguments": arguments})
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		require.NotEqual(t, true, result["isError"], text)
+		var output struct {
+			Result HunksResult `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(text), &output))
+		return output.Result
+	}
+
+	listed := stageHunks(map[string]interface{}{})
+	require.Empty(t, listed.Message)
+	require.Len(t, listed.Hunks, 2)
+
+	staged := stageHunks(map[string]interface{}{"hunk_ids": []interface{}{listed.Hunks[1].ID}})
+	require.Equal(t, "Hunks staged successfully", staged.Message)
+	require.Equal(t, []gitops.Hunk{listed.Hunks[0]}, staged.Hunks)
+
+	unstaged := stageHunks(map[string]interface{}{"unstage": true})
+	require.True(t, unstaged.Staged)
+	require.Len(t, unstaged.Hunks, 1)
+	require.Equal(t, "@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n", unstaged.Hunks[0].Patch)
+	unstaged = stageHunks(map[string]interface{}{"hunk_ids": unstaged.Hunks[0].ID, "unstage": true})
+	require.Empty(t, unstaged.Hunks)
+}
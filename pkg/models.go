@@ -42,8 +42,19 @@ type GitReset struct {
 
 // GitLog represents the input for git log operation
 type GitLog struct {
-	RepoPath string `json:"repo_path"`
-	MaxCount int    `json:"max_count,omitempty"`
+	RepoPath     string `json:"repo_path"`
+	MaxCount     int    `json:"max_count,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+	Author       string `json:"author,omitempty"`
+	Committer    string `json:"committer,omitempty"`
+	Since        string `json:"since,omitempty"`
+	Until        string `json:"until,omitempty"`
+	Paths        string `json:"paths,omitempty"`
+	Follow       bool   `json:"follow,omitempty"`
+	Grep         string `json:"grep,omitempty"`
+	Pickaxe      string `json:"pickaxe,omitempty"`
+	PickaxeRegex string `json:"pickaxe_regex,omitempty"`
+	Merges       string `json:"merges,omitempty"`
 }
 
 // GitCreateBranch represents the input for git branch creation operation
@@ -69,3 +80,194 @@ type GitShow struct {
 type GitInit struct {
 	RepoPath string `json:"repo_path"`
 }
+
+// GitStashPush represents the input for git stash push operation
+type GitStashPush struct {
+	RepoPath         string `json:"repo_path"`
+	Message          string `json:"message,omitempty"`
+	IncludeUntracked bool   `json:"include_untracked,omitempty"`
+}
+
+// GitStashList represents the input for git stash list operation
+type GitStashList struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// GitStashEntry represents the input for operations on a single stash entry
+// (show, apply, pop, drop)
+type GitStashEntry struct {
+	RepoPath string `json:"repo_path"`
+	Index    int    `json:"index,omitempty"`
+}
+
+// GitMerge represents the input for git merge operation
+type GitMerge struct {
+	RepoPath string `json:"repo_path"`
+	Branch   string `json:"branch"`
+	Mode     string `json:"mode,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// GitMergeAbort represents the input for git merge --abort operation
+type GitMergeAbort struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// GitRebase represents the input for git rebase operation
+type GitRebase struct {
+	RepoPath   string `json:"repo_path"`
+	Upstream   string `json:"upstream"`
+	Onto       string `json:"onto,omitempty"`
+	Autosquash bool   `json:"autosquash,omitempty"`
+}
+
+// GitRebaseStep represents the input for operations on an in-progress rebase
+// (continue, skip, abort)
+type GitRebaseStep struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// GitCherryPick represents the input for git cherry-pick operation
+type GitCherryPick struct {
+	RepoPath     string   `json:"repo_path"`
+	Revisions    []string `json:"revisions"`
+	RecordOrigin bool     `json:"record_origin,omitempty"`
+	NoCommit     bool     `json:"no_commit,omitempty"`
+}
+
+// GitRevert represents the input for git revert operation
+type GitRevert struct {
+	RepoPath  string   `json:"repo_path"`
+	Revisions []string `json:"revisions"`
+	NoCommit  bool     `json:"no_commit,omitempty"`
+}
+
+// GitTagList represents the input for git tag list operation
+type GitTagList struct {
+	RepoPath string `json:"repo_path"`
+	Pattern  string `json:"pattern,omitempty"`
+	Sort     string `json:"sort,omitempty"`
+}
+
+// GitTagCreate represents the input for git tag creation operation
+type GitTagCreate struct {
+	RepoPath string `json:"repo_path"`
+	Name     string `json:"name"`
+	Revision string `json:"revision,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// GitTagDelete represents the input for git tag deletion operation
+type GitTagDelete struct {
+	RepoPath string `json:"repo_path"`
+	Name     string `json:"name"`
+}
+
+// GitPushTags represents the input for pushing tags to a remote
+type GitPushTags struct {
+	RepoPath string   `json:"repo_path"`
+	Remote   string   `json:"remote,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// GitBranchList represents the input for git branch list operation
+type GitBranchList struct {
+	RepoPath      string `json:"repo_path"`
+	IncludeRemote bool   `json:"include_remote,omitempty"`
+}
+
+// GitBranchDelete represents the input for git branch deletion operation
+type GitBranchDelete struct {
+	RepoPath   string `json:"repo_path"`
+	BranchName string `json:"branch_name"`
+	Force      bool   `json:"force,omitempty"`
+}
+
+// GitBranchRename represents the input for git branch rename operation
+type GitBranchRename struct {
+	RepoPath string `json:"repo_path"`
+	OldName  string `json:"old_name"`
+	NewName  string `json:"new_name"`
+}
+
+// GitBranchSetUpstream represents the input for setting a branch's upstream
+type GitBranchSetUpstream struct {
+	RepoPath   string `json:"repo_path"`
+	BranchName string `json:"branch_name,omitempty"`
+	Upstream   string `json:"upstream"`
+}
+
+// GitFetch represents the input for fetching from a remote repository
+type GitFetch struct {
+	RepoPath string `json:"repo_path"`
+	Remote   string `json:"remote,omitempty"`
+	Refspec  string `json:"refspec,omitempty"`
+	Prune    bool   `json:"prune,omitempty"`
+	Tags     bool   `json:"tags,omitempty"`
+}
+
+// GitPull represents the input for pulling from a remote repository
+type GitPull struct {
+	RepoPath string `json:"repo_path"`
+	Remote   string `json:"remote,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+	FFOnly   bool   `json:"ff_only,omitempty"`
+}
+
+// GitClone represents the input for cloning a repository
+type GitClone struct {
+	URL        string `json:"url"`
+	TargetPath string `json:"target_path"`
+	Branch     string `json:"branch,omitempty"`
+	Depth      int    `json:"depth,omitempty"`
+	Filter     string `json:"filter,omitempty"`
+}
+
+// GitBlame represents the input for blaming a file
+type GitBlame struct {
+	RepoPath  string `json:"repo_path"`
+	FilePath  string `json:"file_path"`
+	Revision  string `json:"revision,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+// GitReadFile represents the input for reading a file at a revision
+type GitReadFile struct {
+	RepoPath  string `json:"repo_path"`
+	FilePath  string `json:"file_path"`
+	Revision  string `json:"revision,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+// GitLsTree represents the input for listing a tree at a revision
+type GitLsTree struct {
+	RepoPath  string `json:"repo_path"`
+	Revision  string `json:"revision,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Recursive bool   `json:"recursive,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Long      bool   `json:"long,omitempty"`
+}
+
+// GitLsFiles represents the input for listing the files in the index
+type GitLsFiles struct {
+	RepoPath         string `json:"repo_path"`
+	Pattern          string `json:"pattern,omitempty"`
+	IncludeUntracked bool   `json:"include_untracked,omitempty"`
+	IncludeIgnored   bool   `json:"include_ignored,omitempty"`
+}
+
+// GitGrep represents the input for searching file contents
+type GitGrep struct {
+	RepoPath     string `json:"repo_path"`
+	Pattern      string `json:"pattern"`
+	FixedStrings bool   `json:"fixed_strings,omitempty"`
+	IgnoreCase   bool   `json:"ignore_case,omitempty"`
+	Pathspecs    string `json:"pathspecs,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+	ContextLines int    `json:"context_lines,omitempty"`
+	MaxResults   int    `json:"max_results,omitempty"`
+}
@@ -2,6 +2,8 @@ package pkg
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -21,13 +23,13 @@ func TestMultiRepositorySupport(t *testing.T) {
 	gitOps := shell.NewShellGitOperations()
 	
 	// Initialize the repos
-	_, err := gitOps.InitRepo(repo1Dir)
+	_, err := gitOps.InitRepo(context.Background(), repo1Dir)
 	require.NoError(t, err, "Failed to initialize repo1")
 	
-	_, err = gitOps.InitRepo(repo2Dir)
+	_, err = gitOps.InitRepo(context.Background(), repo2Dir)
 	require.NoError(t, err, "Failed to initialize repo2")
 	
-	_, err = gitOps.InitRepo(repo3Dir)
+	_, err = gitOps.InitRepo(context.Background(), repo3Dir)
 	require.NoError(t, err, "Failed to initialize repo3")
 
 	t.Run("TestGitListRepositories", func(t *testing.T) {
@@ -141,4 +143,76 @@ func TestMultiRepositorySupport(t *testing.T) {
 			           "Output should indicate repository path error")
 		}
 	})
+
+	t.Run("TestCloneAddsRepository", func(t *testing.T) {
+		cloneDir := t.TempDir()
+		repoPaths := []string{repo1Dir}
+		server := NewGitServer(repoPaths, gitOps, false)
+		server.SetCloneRestrictions([]string{cloneDir}, []string{"/tmp/*", repo1Dir, "file://*"}, true)
+		server.RegisterTools()
+
+		callClone := func(url string, targetPath string) string {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "git_clone"
+			request.Params.Arguments = map[string]interface{}{
+				"url":         url,
+				"target_path": targetPath,
+			}
+
+			result, err := server.gitCloneHandler(context.Background(), request)
+			require.NoError(t, err, "Clone handler should not return error")
+			require.NotEmpty(t, result.Content, "Result should have content")
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok, "Result should be text")
+			return textContent.Text
+		}
+
+		// Cloning outside the allowed directories is rejected
+		text := callClone(repo1Dir, filepath.Join(repo2Dir, "clone"))
+		assert.Contains(t, text, "access denied", "Clone outside the clone directories should be denied")
+
+		// Symbolic links can't lead outside the allowed directories
+		require.NoError(t, os.Symlink(repo2Dir, filepath.Join(cloneDir, "link")))
+		text = callClone(repo1Dir, filepath.Join(cloneDir, "link", "clone"))
+		assert.Contains(t, text, "access denied", "Clone through a symbolic link should be denied")
+		assert.NoDirExists(t, filepath.Join(repo2Dir, "clone"))
+
+		// Cloning from a URL that doesn't match the patterns is rejected
+		text = callClone("https://example.com/repo.git", filepath.Join(cloneDir, "example"))
+		assert.Contains(t, text, "not allowed", "Clone from an unlisted URL should be denied")
+
+		// A successful clone becomes an available repository
+		targetPath := filepath.Join(cloneDir, "repo1")
+		text = callClone(repo1Dir, targetPath)
+		assert.Contains(t, text, "Cloned", "Clone should succeed")
+
+		selectedPath, err := server.getRepoPathForOperation(targetPath)
+		require.NoError(t, err, "Cloned repository should be available")
+		assert.Equal(t, targetPath, selectedPath)
+
+		// Local repositories can only be cloned if that is allowed, even if the patterns match
+		server.SetCloneRestrictions([]string{cloneDir}, []string{"*"}, false)
+		for _, url := range []string{repo1Dir, "file://" + repo1Dir, "ext::sh -c touch% pwned"} {
+			text = callClone(url, filepath.Join(cloneDir, "local"))
+			assert.Contains(t, text, "not allowed", "Clone from %s should be denied", url)
+		}
+		assert.NoDirExists(t, filepath.Join(cloneDir, "local"))
+	})
+
+	t.Run("TestRemoteCloneURLs", func(t *testing.T) {
+		for url, remote := range map[string]bool{
+			"https://github.com/org/repo.git": true,
+			"ssh://git@github.com/org/repo":   true,
+			"git@github.com:org/repo.git":     true,
+			"git://example.com/repo":          true,
+			"file:///tmp/repo":                false,
+			"/tmp/repo":                       false,
+			"../repo":                         false,
+			"./host:repo":                     false,
+			"C:\\repo":                       false,
+			"ext::sh -c true":                 false,
+		} {
+			assert.Equal(t, remote, isRemoteCloneURL(url), url)
+		}
+	})
 }
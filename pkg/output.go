@@ -0,0 +1,410 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Every tool accepts an output_format argument. With "json", the result is a
+// JSON document that wraps the result of the tool in an envelope naming its
+// schema. The schema of every tool is published as a resource. Its version is
+// incremented whenever a change could break clients that validate against it,
+// such as removing or renaming a field or changing its type. Adding fields
+// keeps the version.
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// outputSchemaURIPrefix is the prefix of the resource URIs the output schemas are published at
+const outputSchemaURIPrefix = "git-mcp://schemas/"
+
+// outputSchema describes the JSON output of a tool
+type outputSchema struct {
+	version int
+	result  interface{} // zero value of the type of the result
+}
+
+// outputSchemas lists the output schema of every tool
+var outputSchemas = map[string]outputSchema{
+	"git_status":              {1, StatusResult{}},
+	"git_diff_unstaged":       {1, DiffResult{}},
+	"git_diff_staged":         {1, DiffResult{}},
+	"git_diff":                {1, DiffResult{}},
+	"git_commit":              {1, MessageResult{}},
+	"git_add":                 {1, MessageResult{}},
+	"git_reset":               {1, MessageResult{}},
+	"git_restore":             {1, MessageResult{}},
+	"git_stage_hunks":         {1, HunksResult{}},
+	"git_log":                 {2, LogResult{}},
+	"git_create_branch":       {1, MessageResult{}},
+	"git_checkout":            {1, MessageResult{}},
+	"git_show":                {1, ShowResult{}},
+	"git_init":                {1, MessageResult{}},
+	"git_push":                {1, MessageResult{}},
+	"git_stash_push":          {1, MessageResult{}},
+	"git_stash_list":          {1, StashListResult{}},
+	"git_stash_show":          {1, DiffResult{}},
+	"git_stash_apply":         {1, MessageResult{}},
+	"git_stash_pop":           {1, MessageResult{}},
+	"git_stash_drop":          {1, MessageResult{}},
+	"git_merge":               {1, OperationResult{}},
+	"git_merge_abort":         {1, MessageResult{}},
+	"git_rebase":              {1, RebaseResult{}},
+	"git_rebase_continue":     {1, RebaseResult{}},
+	"git_rebase_skip":         {1, RebaseResult{}},
+	"git_rebase_abort":        {1, MessageResult{}},
+	"git_cherry_pick":         {1, OperationResult{}},
+	"git_revert":              {1, OperationResult{}},
+	"git_apply":               {1, PatchResult{}},
+	"git_am":                  {1, MessageResult{}},
+	"git_tag_list":            {1, TagListResult{}},
+	"git_tag_create":          {1, MessageResult{}},
+	"git_tag_delete":          {1, MessageResult{}},
+	"git_push_tags":           {1, MessageResult{}},
+	"git_branch_list":         {1, BranchListResult{}},
+	"git_branch_delete":       {1, MessageResult{}},
+	"git_branch_rename":       {1, MessageResult{}},
+	"git_branch_set_upstream": {1, MessageResult{}},
+	"git_blame":               {1, BlameResult{}},
+	"git_read_file":           {1, ReadFileResult{}},
+	"git_ls_tree":             {1, TreeResult{}},
+	"git_ls_files":            {1, FilesResult{}},
+	"git_grep":                {1, SearchResult{}},
+	"git_fetch":               {1, MessageResult{}},
+	"git_pull":                {1, OperationResult{}},
+	"git_clone":               {1, MessageResult{}},
+	"git_list_repositories":   {1, RepositoriesResult{}},
+}
+
+// MessageResult is the JSON result of tools that report what they did
+type MessageResult struct {
+	RepoPath string `json:"repo_path"`
+	Message  string `json:"message"`
+}
+
+// OperationResult is the JSON result of tools that can stop with conflicts.
+// Conflicts is empty if the operation completed.
+type OperationResult struct {
+	RepoPath  string                `json:"repo_path"`
+	Message   string                `json:"message"`
+	Conflicts []gitops.ConflictFile `json:"conflicts,omitempty"`
+	Hint      string                `json:"hint,omitempty"` // how to proceed after conflicts
+}
+
+// StatusResult is the JSON result of git_status
+type StatusResult struct {
+	RepoPath string              `json:"repo_path"`
+	Clean    bool                `json:"clean"`
+	Files    []gitops.FileStatus `json:"files"`
+}
+
+// DiffResult is the JSON result of the diff tools and git_stash_show
+type DiffResult struct {
+	RepoPath   string            `json:"repo_path"`
+	Target     string            `json:"target,omitempty"` // revision or stash entry the changes are shown for
+	Diff       string            `json:"diff"`
+	Files      []gitops.DiffFile `json:"files"` // every changed file, also if the diff is paginated
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// HunksResult is the JSON result of git_stage_hunks. Hunks are the unstaged
+// hunks, or the staged ones when unstaging, after the selected hunks were applied.
+type HunksResult struct {
+	RepoPath string        `json:"repo_path"`
+	Message  string        `json:"message,omitempty"` // empty if hunks were only listed
+	Staged   bool          `json:"staged"`            // whether the hunks are staged
+	Hunks    []gitops.Hunk `json:"hunks"`
+}
+
+// LogResult is the JSON result of git_log
+type LogResult struct {
+	RepoPath   string              `json:"repo_path"`
+	Commits    []gitops.CommitInfo `json:"commits"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// ShowResult is the JSON result of git_show
+type ShowResult struct {
+	RepoPath   string `json:"repo_path"`
+	Revision   string `json:"revision"`
+	Output     string `json:"output"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// StashListResult is the JSON result of git_stash_list
+type StashListResult struct {
+	RepoPath string              `json:"repo_path"`
+	Entries  []gitops.StashEntry `json:"entries"`
+}
+
+// RebaseResult is the JSON result of the rebase tools that report the rebase state
+type RebaseResult struct {
+	RepoPath string `json:"repo_path"`
+	gitops.RebaseState
+}
+
+// PatchResult is the JSON result of git_apply
+type PatchResult struct {
+	RepoPath string `json:"repo_path"`
+	gitops.ApplyResult
+}
+
+// TagListResult is the JSON result of git_tag_list
+type TagListResult struct {
+	RepoPath string           `json:"repo_path"`
+	Tags     []gitops.TagInfo `json:"tags"`
+}
+
+// BranchListResult is the JSON result of git_branch_list
+type BranchListResult struct {
+	RepoPath string              `json:"repo_path"`
+	Branches []gitops.BranchInfo `json:"branches"`
+}
+
+// BlameResult is the JSON result of git_blame
+type BlameResult struct {
+	RepoPath string             `json:"repo_path"`
+	FilePath string             `json:"file_path"`
+	Revision string             `json:"revision"`
+	Lines    []gitops.BlameLine `json:"lines"`
+}
+
+// ReadFileResult is the JSON result of git_read_file
+type ReadFileResult struct {
+	RepoPath string `json:"repo_path"`
+	gitops.FileContent
+}
+
+// TreeResult is the JSON result of git_ls_tree
+type TreeResult struct {
+	RepoPath string             `json:"repo_path"`
+	Revision string             `json:"revision"`
+	Path     string             `json:"path"`
+	Entries  []gitops.TreeEntry `json:"entries"`
+}
+
+// FilesResult is the JSON result of git_ls_files
+type FilesResult struct {
+	RepoPath string             `json:"repo_path"`
+	Files    []gitops.IndexFile `json:"files"`
+}
+
+// SearchResult is the JSON result of git_grep
+type SearchResult struct {
+	RepoPath  string             `json:"repo_path"`
+	Pattern   string             `json:"pattern"`
+	Revision  string             `json:"revision,omitempty"` // empty when the working tree was searched
+	Matches   []gitops.GrepMatch `json:"matches"`
+	Truncated bool               `json:"truncated"` // whether matches were dropped to honor max_results
+}
+
+// RepositoriesResult is the JSON result of git_list_repositories
+type RepositoriesResult struct {
+	Repositories []string `json:"repositories"`
+}
+
+// outputEnvelope wraps the JSON result of a tool
+type outputEnvelope struct {
+	Schema  string      `json:"schema"`
+	Tool    string      `json:"tool"`
+	Version int         `json:"version"`
+	Result  interface{} `json:"result"`
+}
+
+// outputSchemaURI returns the URI the output schema of a tool is published at
+func outputSchemaURI(toolName string, version int) string {
+	return fmt.Sprintf("%s%s/v%d", outputSchemaURIPrefix, toolName, version)
+}
+
+// withOutputFormat adds the output_format argument to a tool
+func withOutputFormat(toolName string, version int) mcp.ToolOption {
+	return mcp.WithString("output_format",
+		mcp.Description(fmt.Sprintf("Format of the result: 'text' (default) or 'json', which follows the schema at %s", outputSchemaURI(toolName, version))),
+		mcp.Enum(outputFormatText, outputFormatJSON),
+	)
+}
+
+// addTool registers a tool with the output_format argument and publishes the
+// schema of its JSON output
+func (s *GitServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	schema, ok := outputSchemas[tool.Name]
+	if !ok {
+		panic(fmt.Sprintf("no output schema registered for tool %s", tool.Name))
+	}
+	withOutputFormat(tool.Name, schema.version)(&tool)
+
+	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Reject unknown formats before the tool changes anything
+		switch format := getStringArgument(request, "output_format"); format {
+		case "", outputFormatText, outputFormatJSON:
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("output_format must be '%s' or '%s', got '%s'", outputFormatText, outputFormatJSON, format)), nil
+		}
+
+		timeout := s.toolTimeout(tool.Name)
+		if timeout == 0 {
+			return handler(ctx, request)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		result, err := handler(ctx, request)
+		// The git operation failed because it was canceled, report why
+		if err == nil && result != nil && result.IsError && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %s", tool.Name, timeout)), nil
+		}
+		return result, err
+	})
+
+	uri := outputSchemaURI(tool.Name, schema.version)
+	s.server.AddResource(mcp.NewResource(uri, fmt.Sprintf("%s output schema", tool.Name),
+		mcp.WithResourceDescription(fmt.Sprintf("JSON schema of the result of %s with output_format=json", tool.Name)),
+		mcp.WithMIMEType("application/schema+json"),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+		data, err := json.MarshalIndent(outputSchemaDocument(tool.Name, schema), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema: %w", err)
+		}
+		return []interface{}{
+			mcp.TextResourceContents{
+				ResourceContents: mcp.ResourceContents{URI: uri, MIMEType: "application/schema+json"},
+				Text:             string(data),
+			},
+		}, nil
+	})
+}
+
+// wantsJSON reports whether a request asks for JSON output
+func wantsJSON(request mcp.CallToolRequest) bool {
+	return getStringArgument(request, "output_format") == outputFormatJSON
+}
+
+// toolResult returns text, or result wrapped in the JSON envelope if the request asks for JSON output
+func toolResult(request mcp.CallToolRequest, text string, result interface{}) *mcp.CallToolResult {
+	if !wantsJSON(request) {
+		return mcp.NewToolResultText(text)
+	}
+	return jsonResult(request, result)
+}
+
+// jsonResult returns result wrapped in the JSON envelope of the requested tool
+func jsonResult(request mcp.CallToolRequest, result interface{}) *mcp.CallToolResult {
+	schema, ok := outputSchemas[request.Params.Name]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("No output schema for tool %s", request.Params.Name))
+	}
+	data, err := json.Marshal(outputEnvelope{
+		Schema:  outputSchemaURI(request.Params.Name, schema.version),
+		Tool:    request.Params.Name,
+		Version: schema.version,
+		Result:  result,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode result: %v", err))
+	}
+	return mcp.NewToolResultText(string(data))
+}
+
+// nonNil returns an empty slice instead of nil, so lists are encoded as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// outputSchemaDocument returns the JSON schema of the envelope of a tool's JSON output
+func outputSchemaDocument(toolName string, schema outputSchema) map[string]interface{} {
+	uri := outputSchemaURI(toolName, schema.version)
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     uri,
+		"title":   fmt.Sprintf("%s output, version %d", toolName, schema.version),
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"schema":  map[string]interface{}{"const": uri},
+			"tool":    map[string]interface{}{"const": toolName},
+			"version": map[string]interface{}{"const": schema.version},
+			"result":  jsonSchema(reflect.TypeOf(schema.result)),
+		},
+		"required": []string{"schema", "tool", "version", "result"},
+	}
+}
+
+// jsonSchema derives the JSON schema of the encoding of values of type t
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		var required []string
+		addStructProperties(t, properties, &required)
+		objectSchema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			sort.Strings(required)
+			objectSchema["required"] = required
+		}
+		return objectSchema
+	}
+	panic(fmt.Sprintf("unsupported type %s in output schema", t))
+}
+
+// addStructProperties adds the encoded fields of struct type t to properties,
+// inlining embedded structs like encoding/json does
+func addStructProperties(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			addStructProperties(field.Type, properties, required)
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchema(field.Type)
+		if options != "omitempty" {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// conflictResult returns the JSON result of an operation that stopped with conflicts
+func conflictResult(repoPath string, conflictErr *gitops.ConflictError, hint string) OperationResult {
+	return OperationResult{
+		RepoPath:  repoPath,
+		Message:   conflictErr.Error(),
+		Conflicts: conflictErr.Conflicts,
+		Hint:      hint,
+	}
+}
@@ -0,0 +1,181 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops/shell"
+	"github.com/stretchr/testify/require"
+)
+
+// publishedSchemasFile records every output schema version that has been released
+const publishedSchemasFile = "testdata/output_schemas.json"
+
+var updateSchemas = flag.Bool("update-schemas", false, "record new and extended output schemas in "+publishedSchemasFile)
+
+// callServer sends a JSON-RPC request to the server and returns the decoded result
+func callServer(t *testing.T, server *GitServer, method string, params interface{}) map[string]interface{} {
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(server.server.HandleMessage(context.Background(), message))
+	require.NoError(t, err)
+	var response struct {
+		Result map[string]interface{} `json:"result"`
+		Error  interface{}            `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	require.Nil(t, response.Error, "%s failed: %s", method, data)
+	return response.Result
+}
+
+func TestToolsPublishOutputSchemas(t *testing.T) {
+	repoDir := t.TempDir()
+	gitOps := shell.NewShellGitOperations()
+	_, err := gitOps.InitRepo(context.Background(), repoDir)
+	require.NoError(t, err)
+
+	// Enable every tool
+	server := NewGitServer([]string{repoDir}, gitOps, true)
+	server.SetCloneRestrictions([]string{t.TempDir()}, nil, false)
+	server.RegisterTools()
+
+	tools := callServer(t, server, "tools/list", map[string]interface{}{})["tools"].([]interface{})
+	registered := make(map[string]bool)
+	for _, tool := range tools {
+		tool := tool.(map[string]interface{})
+		name := tool["name"].(string)
+		registered[name] = true
+
+		properties := tool["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})
+		require.Contains(t, properties, "output_format", "%s should accept output_format", name)
+	}
+	for name := range outputSchemas {
+		require.True(t, registered[name], "output schema registered for unknown tool %s", name)
+	}
+
+	// The schema of each tool can be read as a resource
+	resources := callServer(t, server, "resources/list", map[string]interface{}{})["resources"].([]interface{})
+	require.Len(t, resources, len(registered))
+
+	uri := outputSchemaURI("git_status", outputSchemas["git_status"].version)
+	contents := callServer(t, server, "resources/read", map[string]interface{}{"uri": uri})["contents"].([]interface{})
+	require.Len(t, contents, 1)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].(map[string]interface{})["text"].(string)), &schema))
+	require.Equal(t, uri, schema["$id"])
+
+	// Unknown formats are rejected before the tool runs
+	result := callServer(t, server, "tools/call", map[string]interface{}{
+		"name":      "git_status",
+		"arguments": map[string]interface{}{"output_format": "xml"},
+	})
+	require.Equal(t, true, result["isError"])
+	require.Contains(t, fmt.Sprint(result["content"]), "output_format must be 'text' or 'json'")
+}
+
+func TestOutputSchemasCompatible(t *testing.T) {
+	published := make(map[string]map[string]interface{})
+	data, err := os.ReadFile(publishedSchemasFile)
+	if !os.IsNotExist(err) {
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &published))
+	}
+
+	changed := false
+	for name, schema := range outputSchemas {
+		uri := outputSchemaURI(name, schema.version)
+
+		// Compare the schemas in their decoded form, as they are published
+		data, err := json.Marshal(outputSchemaDocument(name, schema))
+		require.NoError(t, err)
+		var current map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &current))
+
+		previous, ok := published[uri]
+		switch {
+		case !ok && !*updateSchemas:
+			t.Errorf("%s is not recorded in %s, run the test with -update-schemas to record it", uri, publishedSchemasFile)
+		case !ok:
+			published[uri] = current
+			changed = true
+		default:
+			if breaking := schemaBreakingChanges("$", previous, current); len(breaking) > 0 {
+				t.Errorf("breaking changes to %s, increment the version of the schema of %s:\n%s", uri, name, strings.Join(breaking, "\n"))
+			} else if !reflect.DeepEqual(previous, current) {
+				if !*updateSchemas {
+					t.Errorf("%s was extended, run the test with -update-schemas to record it", uri)
+				}
+				published[uri] = current
+				changed = true
+			}
+		}
+	}
+
+	if changed && *updateSchemas {
+		data, err := json.MarshalIndent(published, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(publishedSchemasFile, append(data, '\n'), 0644))
+	}
+}
+
+// schemaBreakingChanges lists the changes from the previous to the current
+// schema that could make documents valid for the current schema invalid for the
+// previous one. Adding optional or required properties is compatible.
+func schemaBreakingChanges(path string, previous, current map[string]interface{}) []string {
+	var changes []string
+	for _, keyword := range []string{"type", "format", "const"} {
+		if !reflect.DeepEqual(previous[keyword], current[keyword]) {
+			changes = append(changes, fmt.Sprintf("%s: %s changed from %v to %v", path, keyword, previous[keyword], current[keyword]))
+		}
+	}
+
+	if previousItems, ok := previous["items"].(map[string]interface{}); ok {
+		currentItems, _ := current["items"].(map[string]interface{})
+		changes = append(changes, schemaBreakingChanges(path+"[]", previousItems, currentItems)...)
+	}
+
+	previousProperties, _ := previous["properties"].(map[string]interface{})
+	currentProperties, _ := current["properties"].(map[string]interface{})
+	names := make([]string, 0, len(previousProperties))
+	for name := range previousProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		currentProperty, ok := currentProperties[name].(map[string]interface{})
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s.%s: removed", path, name))
+			continue
+		}
+		changes = append(changes, schemaBreakingChanges(path+"."+name, previousProperties[name].(map[string]interface{}), currentProperty)...)
+	}
+
+	currentRequired := make(map[string]bool)
+	if required, ok := current["required"].([]interface{}); ok {
+		for _, name := range required {
+			currentRequired[name.(string)] = true
+		}
+	}
+	if required, ok := previous["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, kept := currentProperties[name.(string)]; kept && !currentRequired[name.(string)] {
+				changes = append(changes, fmt.Sprintf("%s.%s: no longer required", path, name))
+			}
+		}
+	}
+	return changes
+}
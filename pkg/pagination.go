@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Paginated tools accept a page_size argument and return an opaque cursor with
+// every page that isn't the last one. Passing the cursor back with otherwise
+// identical arguments returns the next page.
+
+// defaultLinePageSize is the number of lines per page for text output such as diffs
+const defaultLinePageSize = 500
+
+// paginationArguments are the arguments that select the page or its format rather
+// than the output being paginated. git_log's max_count predates page_size and is an alias for it.
+var paginationArguments = map[string]bool{
+	"cursor":        true,
+	"page_size":     true,
+	"max_count":     true,
+	"output_format": true,
+}
+
+// pageCursor is the decoded form of a pagination cursor
+type pageCursor struct {
+	Offset int    `json:"offset"`
+	Query  string `json:"query"` // fingerprint of the arguments the cursor belongs to
+}
+
+// pagination describes the page requested by a tool call
+type pagination struct {
+	toolName string
+	query    string
+	pageSize int
+	offset   int
+}
+
+// withPageSize adds the page_size argument to a paginated tool
+func withPageSize(unit string, defaultPageSize int) mcp.ToolOption {
+	return mcp.WithNumber("page_size",
+		mcp.Description(fmt.Sprintf("Maximum number of %s to return (default: %d)", unit, defaultPageSize)),
+	)
+}
+
+// withCursor adds the cursor argument to a paginated tool
+func withCursor() mcp.ToolOption {
+	return mcp.WithString("cursor",
+		mcp.Description("Cursor returned by a previous call with the same arguments, to get the next page"),
+	)
+}
+
+// getPagination reads the page_size and cursor arguments of a request. The
+// cursor must have been returned for a call with the same other arguments.
+func getPagination(request mcp.CallToolRequest, defaultPageSize int) (*pagination, error) {
+	page := &pagination{
+		toolName: request.Params.Name,
+		query:    queryFingerprint(request),
+		pageSize: getIntArgument(request, "page_size", defaultPageSize),
+	}
+	if page.pageSize <= 0 {
+		return nil, fmt.Errorf("page_size must be positive")
+	}
+
+	cursor := getStringArgument(request, "cursor")
+	if cursor == "" {
+		return page, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var decoded pageCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Offset < 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if decoded.Query != page.query {
+		return nil, fmt.Errorf("cursor doesn't belong to a call with these arguments")
+	}
+
+	page.offset = decoded.Offset
+	return page, nil
+}
+
+// queryFingerprint identifies the arguments of a request other than the pagination arguments
+func queryFingerprint(request mcp.CallToolRequest) string {
+	query := make(map[string]interface{}, len(request.Params.Arguments)+1)
+	for name, value := range request.Params.Arguments {
+		if !paginationArguments[name] {
+			query[name] = value
+		}
+	}
+	query["\x00tool"] = request.Params.Name
+
+	// Map keys are marshalled in sorted order, so equal arguments have equal fingerprints
+	data, _ := json.Marshal(query)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// nextCursor returns the cursor for the page following the current one, which contained count items
+func (p *pagination) nextCursor(count int) string {
+	data, _ := json.Marshal(pageCursor{Offset: p.offset + count, Query: p.query})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// cursorIfMore returns the cursor for the next page if there is one, and an empty string otherwise
+func (p *pagination) cursorIfMore(count int, hasMore bool) string {
+	if !hasMore {
+		return ""
+	}
+	return p.nextCursor(count)
+}
+
+// footer describes where the current page, which contained count items, ends
+// and how to get the next page if there is one
+func (p *pagination) footer(unit string, count int, hasMore bool) string {
+	if !hasMore {
+		if p.offset == 0 {
+			return ""
+		}
+		if count == 0 {
+			return fmt.Sprintf("\n[No more %s]\n", unit)
+		}
+		return fmt.Sprintf("\n[End of output, showing %s %d-%d]\n", unit, p.offset+1, p.offset+count)
+	}
+	return fmt.Sprintf("\n[Showing %s %d-%d. More available: call %s again with cursor=%q]\n",
+		unit, p.offset+1, p.offset+count, p.toolName, p.nextCursor(count))
+}
+
+// paginateLines returns the requested page of the lines of text, followed by a pagination footer
+func (p *pagination) paginateLines(text string) string {
+	page, count, hasMore := p.pageOfLines(text)
+	return page + p.footer("lines", count, hasMore)
+}
+
+// pageOfLines returns the requested page of the lines of text, the number of
+// lines on it and whether more lines follow
+func (p *pagination) pageOfLines(text string) (string, int, bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	start := p.offset
+	if start > len(lines) {
+		start = len(lines)
+	}
+	end := start + p.pageSize
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	page := strings.Join(lines[start:end], "")
+	if end > start && !strings.HasSuffix(page, "\n") {
+		page += "\n"
+	}
+	return page, end - start, end < len(lines)
+}
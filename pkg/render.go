@@ -0,0 +1,409 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+)
+
+// The text output of the tools is rendered from the results of the git
+// operations here, so that it is the same for every backend.
+
+// gitDateFormat is the default date format of git log
+const gitDateFormat = "Mon Jan 2 15:04:05 2006 -0700"
+
+// formatStatus renders the changed files of a repository like `git status --short`
+func formatStatus(repoPath string, files []gitops.FileStatus) string {
+	if len(files) == 0 {
+		return fmt.Sprintf("Repository status for %s:\nNothing to commit, working tree clean\n", repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Repository status for %s:\n", repoPath))
+	for _, file := range files {
+		path := file.Path
+		if file.OrigPath != "" {
+			path = fmt.Sprintf("%s -> %s", file.OrigPath, file.Path)
+		}
+		result.WriteString(fmt.Sprintf("%s%s %s\n", file.Index, file.Worktree, path))
+	}
+	return result.String()
+}
+
+// formatDiff renders the changes to files as a unified diff
+func formatDiff(files []gitops.DiffFile) string {
+	var result strings.Builder
+	for _, file := range files {
+		result.WriteString(file.Patch)
+	}
+	return result.String()
+}
+
+// formatHunks renders the hunks of the unstaged or staged changes with their IDs
+func formatHunks(repoPath string, hunks []gitops.Hunk, staged bool) string {
+	kind := "Unstaged"
+	if staged {
+		kind = "Staged"
+	}
+	if len(hunks) == 0 {
+		return fmt.Sprintf("No %s hunks in %s\n", strings.ToLower(kind), repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s hunks in %s (%d):\n", kind, repoPath, len(hunks)))
+	for _, hunk := range hunks {
+		result.WriteString(fmt.Sprintf("\nHunk %s in %s:\n%s", hunk.ID, hunk.Path, hunk.Patch))
+	}
+	return result.String()
+}
+
+// formatCommits renders commits like the log entries of git log
+func formatCommits(commits []gitops.CommitInfo) string {
+	entries := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		entries = append(entries, fmt.Sprintf("Commit: %s\nAuthor: %s <%s>\nDate: %s\nMessage: %s\n",
+			commit.Hash, commit.Author, commit.AuthorEmail, commit.Date.Format(gitDateFormat), commit.Subject()))
+	}
+	return strings.Join(entries, "\n")
+}
+
+// formatStashList renders the entries of the stash
+func formatStashList(repoPath string, entries []gitops.StashEntry) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("No stash entries for %s", repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Stash entries for %s (%d):\n", repoPath, len(entries)))
+	for _, entry := range entries {
+		result.WriteString(fmt.Sprintf("%s (branch: %s): %s\n", gitops.StashRef(entry.Index), entry.Branch, entry.Message))
+	}
+	return result.String()
+}
+
+// formatTags renders a list of tags with their targets
+func formatTags(repoPath string, tags []gitops.TagInfo) string {
+	if len(tags) == 0 {
+		return fmt.Sprintf("No tags found for %s", repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Tags for %s (%d):\n", repoPath, len(tags)))
+	for _, tag := range tags {
+		date := tag.Date.UTC().Format(time.RFC3339)
+		if tag.Annotated {
+			result.WriteString(fmt.Sprintf("%s -> %s (annotated by %s, %s): %s\n", tag.Name, tag.Target, tag.Tagger, date, tag.Message))
+		} else {
+			result.WriteString(fmt.Sprintf("%s -> %s (lightweight, %s)\n", tag.Name, tag.Target, date))
+		}
+	}
+	return result.String()
+}
+
+// formatBranches renders a list of branches like `git branch -vv`
+func formatBranches(repoPath string, branches []gitops.BranchInfo) string {
+	if len(branches) == 0 {
+		return fmt.Sprintf("No branches found for %s", repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Branches for %s (%d):\n", repoPath, len(branches)))
+	for _, branch := range branches {
+		marker := " "
+		if branch.Current {
+			marker = "*"
+		}
+
+		name := branch.Name
+		if branch.Remote {
+			name = "remotes/" + name
+		}
+
+		tracking := ""
+		switch {
+		case branch.Upstream != "" && branch.UpstreamGone:
+			tracking = fmt.Sprintf(" [%s: gone]", branch.Upstream)
+		case branch.Upstream != "":
+			tracking = fmt.Sprintf(" [%s: ahead %d, behind %d]", branch.Upstream, branch.Ahead, branch.Behind)
+		}
+
+		result.WriteString(fmt.Sprintf("%s %s %s%s (%s) %s\n",
+			marker,
+			name,
+			shortHash(branch.Commit),
+			tracking,
+			branch.LastCommitDate.UTC().Format(time.RFC3339),
+			branch.Subject))
+	}
+	return result.String()
+}
+
+// formatBlame renders the blamed lines of a file, then the commits they come from
+func formatBlame(filePath string, revision string, lines []gitops.BlameLine) string {
+	if len(lines) == 0 {
+		return fmt.Sprintf("No lines to blame in %s at %s", filePath, revision)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Blame for %s at %s (%d lines):\n", filePath, revision, len(lines)))
+
+	// List each line with its commit, then the commits once with their summary
+	var commits []gitops.BlameLine
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		origin := ""
+		if line.OriginalLineNumber > 0 && line.OriginalLineNumber != line.LineNumber {
+			origin = fmt.Sprintf(" from line %d", line.OriginalLineNumber)
+		}
+		result.WriteString(fmt.Sprintf("%d: %s (%s %s%s) %s\n",
+			line.LineNumber, shortHash(line.Commit), line.Author, line.Date.UTC().Format(time.RFC3339), origin, line.Content))
+
+		if !seen[line.Commit] {
+			seen[line.Commit] = true
+			commits = append(commits, line)
+		}
+	}
+
+	result.WriteString("\nCommits:\n")
+	for _, commit := range commits {
+		result.WriteString(fmt.Sprintf("%s %s <%s>: %s\n", shortHash(commit.Commit), commit.Author, commit.AuthorEmail, commit.Summary))
+	}
+	return result.String()
+}
+
+// formatFileContent renders a file read at revision, with a header describing it
+func formatFileContent(file *gitops.FileContent, revision string) string {
+	if file.Binary {
+		return fmt.Sprintf("Binary file %s at %s (%s): blob %s, %d bytes",
+			file.Path, revision, shortHash(file.Revision), file.Hash, file.Size)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("File %s at %s (%s): blob %s, %d bytes, ",
+		file.Path, revision, shortHash(file.Revision), file.Hash, file.Size))
+	if file.TotalLines == 0 {
+		result.WriteString("empty\n")
+	} else {
+		result.WriteString(fmt.Sprintf("lines %d-%d of %d\n", file.StartLine, file.EndLine, file.TotalLines))
+	}
+	result.WriteString(file.Content)
+	return result.String()
+}
+
+// formatTree renders the entries of a tree, with their mode, type, hash and size if long is set
+func formatTree(location string, entries []gitops.TreeEntry, long bool) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("No entries found in %s", location)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Tree %s (%d entries):\n", location, len(entries)))
+	for _, entry := range entries {
+		name := entry.Path
+		if entry.Type == "tree" {
+			name += "/"
+		}
+		if !long {
+			result.WriteString(name + "\n")
+			continue
+		}
+
+		size := "-"
+		if entry.Size >= 0 {
+			size = fmt.Sprintf("%d", entry.Size)
+		}
+		result.WriteString(fmt.Sprintf("%s %s %s %8s %s\n", entry.Mode, entry.Type, entry.Hash, size, name))
+	}
+	return result.String()
+}
+
+// formatFiles renders files of the working tree, marking those that aren't tracked
+func formatFiles(repoPath string, files []gitops.IndexFile) string {
+	if len(files) == 0 {
+		return fmt.Sprintf("No files found for %s", repoPath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Files for %s (%d):\n", repoPath, len(files)))
+	for _, file := range files {
+		if file.Status == gitops.IndexFileTracked {
+			result.WriteString(file.Path + "\n")
+		} else {
+			result.WriteString(fmt.Sprintf("%s (%s)\n", file.Path, file.Status))
+		}
+	}
+	return result.String()
+}
+
+// formatGrep renders the matches of a search in location like git grep
+func formatGrep(opts gitops.GrepOptions, location string, grepResult *gitops.GrepResult) string {
+	matchCount := 0
+	for _, match := range grepResult.Matches {
+		if !match.Context {
+			matchCount++
+		}
+	}
+	if matchCount == 0 {
+		return fmt.Sprintf("No matches for %q in %s", opts.Pattern, location)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Matches for %q in %s (%d):\n", opts.Pattern, location, matchCount))
+
+	// Like git grep, matches are marked with ":" and context lines with "-",
+	// and non-adjacent groups of lines are separated by "--"
+	for i, match := range grepResult.Matches {
+		if opts.ContextLines > 0 && i > 0 {
+			previous := grepResult.Matches[i-1]
+			if previous.Path != match.Path || previous.LineNumber+1 != match.LineNumber {
+				result.WriteString("--\n")
+			}
+		}
+
+		separator := ":"
+		if match.Context {
+			separator = "-"
+		}
+		result.WriteString(fmt.Sprintf("%s%s%d%s%s\n", match.Path, separator, match.LineNumber, separator, match.Text))
+	}
+
+	if grepResult.Truncated {
+		result.WriteString(fmt.Sprintf("\nResults truncated after %d matches, increase max_results or narrow the search to see more\n", opts.MaxResults))
+	}
+	return result.String()
+}
+
+// formatRepositories renders the list of managed repositories
+func formatRepositories(repoPaths []string) string {
+	if len(repoPaths) == 0 {
+		return "No repositories configured"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Available repositories (%d):\n\n", len(repoPaths)))
+
+	for i, repoPath := range repoPaths {
+		// Get the repository name (last part of the path)
+		repoName := filepath.Base(repoPath)
+		result.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, repoName, repoPath))
+	}
+	return result.String()
+}
+
+// formatRebaseState renders the state of a rebase after a rebase step
+func formatRebaseState(repoPath string, state *gitops.RebaseState) string {
+	var result strings.Builder
+	if !state.InProgress {
+		result.WriteString(fmt.Sprintf("Rebase completed for %s\n%s", repoPath, state.Output))
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("Rebase in progress for %s\n", repoPath))
+	if state.HeadName != "" {
+		result.WriteString(fmt.Sprintf("Branch: %s\n", strings.TrimPrefix(state.HeadName, "refs/heads/")))
+	}
+	if state.Onto != "" {
+		result.WriteString(fmt.Sprintf("Onto: %s\n", state.Onto))
+	}
+	if state.CurrentCommit != "" {
+		result.WriteString(fmt.Sprintf("Stopped at: %s %s\n", state.CurrentCommit, state.CurrentSubject))
+	}
+	result.WriteString(fmt.Sprintf("Progress: %d done, %d remaining\n", state.Done, state.Remaining))
+
+	if len(state.ConflictedFiles) > 0 {
+		result.WriteString(fmt.Sprintf("Conflicted files (%d):\n", len(state.ConflictedFiles)))
+		for _, path := range state.ConflictedFiles {
+			result.WriteString(fmt.Sprintf("  %s\n", path))
+		}
+		result.WriteString("\nResolve the conflicts and stage the files with git_add, then use git_rebase_continue. Use git_rebase_skip to drop the current commit or git_rebase_abort to back out.\n")
+	} else {
+		result.WriteString("\nNo conflicted files. Use git_rebase_continue to proceed.\n")
+	}
+
+	if state.Output != "" {
+		result.WriteString(fmt.Sprintf("\nOutput:\n%s\n", state.Output))
+	}
+	return result.String()
+}
+
+// formatApplyResult renders the result of applying a patch with the status of every file
+func formatApplyResult(repoPath string, applied *gitops.ApplyResult, check bool) string {
+	rejected, conflicts := false, false
+	for _, file := range applied.Files {
+		rejected = rejected || file.Status == gitops.ApplyRejected
+		conflicts = conflicts || file.Status == gitops.ApplyConflict
+	}
+
+	var result strings.Builder
+	switch {
+	case rejected && check:
+		result.WriteString(fmt.Sprintf("Patch does not apply to %s:\n", repoPath))
+	case rejected:
+		result.WriteString(fmt.Sprintf("Patch not applied to %s, the changes to some files were rejected:\n", repoPath))
+	case check:
+		result.WriteString(fmt.Sprintf("Patch applies to %s:\n", repoPath))
+	default:
+		result.WriteString(fmt.Sprintf("Patch applied to %s:\n", repoPath))
+	}
+	for _, file := range applied.Files {
+		path := file.Path
+		if file.OldPath != "" {
+			path = fmt.Sprintf("%s -> %s", file.OldPath, file.Path)
+		}
+		result.WriteString(fmt.Sprintf("%-8s %s", file.Status, path))
+		if file.Error != "" {
+			result.WriteString(": " + file.Error)
+		}
+		result.WriteString("\n")
+	}
+	if conflicts && !rejected && !check {
+		result.WriteString("\nResolve the conflicts and stage the files with git_add, then commit with git_commit.\n")
+	}
+	return result.String()
+}
+
+// formatConflicts renders the conflicted files and hunks of a stopped operation,
+// followed by a hint on how to proceed
+func formatConflicts(conflictErr *gitops.ConflictError, hint string) string {
+	var result strings.Builder
+	operation := conflictErr.Operation
+	if operation != "" {
+		operation = strings.ToUpper(operation[:1]) + operation[1:]
+	}
+	result.WriteString(fmt.Sprintf("%s stopped with conflicts in %d file(s):\n", operation, len(conflictErr.Conflicts)))
+	for _, conflict := range conflictErr.Conflicts {
+		result.WriteString(fmt.Sprintf("\n%s (%d hunk(s))\n", conflict.Path, len(conflict.Hunks)))
+		for i, hunk := range conflict.Hunks {
+			result.WriteString(fmt.Sprintf("  Hunk %d (lines %d-%d):\n", i+1, hunk.StartLine, hunk.EndLine))
+			result.WriteString(fmt.Sprintf("    Ours:\n%s\n", indent(hunk.Ours, "      ")))
+			if hunk.Base != "" {
+				result.WriteString(fmt.Sprintf("    Base:\n%s\n", indent(hunk.Base, "      ")))
+			}
+			result.WriteString(fmt.Sprintf("    Theirs:\n%s\n", indent(hunk.Theirs, "      ")))
+		}
+	}
+	if hint != "" {
+		result.WriteString("\n" + hint + "\n")
+	}
+	return result.String()
+}
+
+// indent prefixes every line of text with prefix
+func indent(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
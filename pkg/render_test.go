@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/geropl/git-mcp-go/pkg/gitops"
+	"github.com/geropl/git-mcp-go/pkg/gitops/gogit"
+	"github.com/geropl/git-mcp-go/pkg/gitops/shell"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendsRenderSameOutput(t *testing.T) {
+	remoteDir := t.TempDir()
+	localDir := t.TempDir()
+	initRepos(t, remoteDir, localDir)
+	writeFile := func(name string, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, name), []byte(content), 0644))
+	}
+
+	var program strings.Builder
+	for i := 1; i <= 30; i++ {
+		if i%10 == 1 {
+			program.WriteString(fmt.Sprintf("func f%d() {\n", i))
+		} else {
+			program.WriteString(fmt.Sprintf("\tline(%d)\n", i))
+		}
+	}
+	createCommit(t, localDir, "a.txt", "one\ntwo\nthree\n", "Add a")
+	writeFile("program.go", program.String())
+	writeFile("no newline", "last")
+	writeFile("binary.dat", "\x00\x01")
+	writeFile("sp\u00e4cial \"name\".txt", "special\n")
+	writeFile("moved.txt", "moved\n")
+	writeFile("script.sh", "echo\n")
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(localDir, "link")))
+	runGit(t, localDir, "add", ".")
+	runGit(t, localDir, "commit", "-q", "-m", "Add files\n\nWith a body\n\nof two paragraphs")
+	runGit(t, localDir, "tag", "-a", "v1", "-m", "Release 1")
+
+	runGit(t, localDir, "branch", "side")
+	createCommit(t, localDir, "b.txt", "bee\n", "Add b")
+	runGit(t, localDir, "mv", "moved.txt", "renamed.txt")
+	createCommit(t, localDir, "a.txt", "one\n2\nthree\nfour\n", "Change a")
+
+	// Leave staged, unstaged and untracked changes
+	writeFile("c.txt", "sea\n")
+	runGit(t, localDir, "add", "c.txt")
+	runGit(t, localDir, "rm", "-q", "b.txt")
+	writeFile("a.txt", "one\nthree\nfour\n")
+	writeFile("program.go", strings.Replace(strings.Replace(program.String(), "line(3)", "changed(3)", 1), "line(25)", "changed(25)", 1))
+	writeFile("no newline", "last line")
+	writeFile("binary.dat", "\x00\x02")
+	writeFile("sp\u00e4cial \"name\".txt", "more special\n")
+	require.NoError(t, os.Chmod(filepath.Join(localDir, "script.sh"), 0755))
+	require.NoError(t, os.Remove(filepath.Join(localDir, "link")))
+	require.NoError(t, os.Symlink("c.txt", filepath.Join(localDir, "link")))
+	writeFile("untracked.txt", "new\n")
+
+	calls := []struct {
+		name      string
+		arguments map[string]interface{}
+		handler   func(*GitServer) server.ToolHandlerFunc
+	}{
+		{"git_status", nil, func(s *GitServer) server.ToolHandlerFunc { return s.gitStatusHandler }},
+		{"git_diff_unstaged", nil, func(s *GitServer) server.ToolHandlerFunc { return s.gitDiffUnstagedHandler }},
+		{"git_diff_staged", nil, func(s *GitServer) server.ToolHandlerFunc { return s.gitDiffStagedHandler }},
+		{"git_diff", map[string]interface{}{"target": "HEAD~2"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitDiffHandler }},
+		{"git_diff", map[string]interface{}{"target": "side..v1~1"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitDiffHandler }},
+		{"git_diff", map[string]interface{}{"target": "side...HEAD"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitDiffHandler }},
+		{"git_log", nil, func(s *GitServer) server.ToolHandlerFunc { return s.gitLogHandler }},
+		{"git_show", map[string]interface{}{"revision": "HEAD"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+		{"git_show", map[string]interface{}{"revision": "HEAD~1"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+		{"git_show", map[string]interface{}{"revision": "v1"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+		{"git_show", map[string]interface{}{"revision": "v1~1"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+		{"git_show", map[string]interface{}{"revision": "HEAD:"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+		{"git_show", map[string]interface{}{"revision": "HEAD:a.txt"}, func(s *GitServer) server.ToolHandlerFunc { return s.gitShowHandler }},
+	}
+
+	servers := map[string]*GitServer{
+		"shell":  NewGitServer([]string{localDir}, shell.NewShellGitOperations(), false),
+		"go-git": NewGitServer([]string{localDir}, gogit.NewGoGitOperations(), false),
+	}
+	for _, call := range calls {
+		for _, format := range []string{outputFormatText, outputFormatJSON} {
+			t.Run(fmt.Sprintf("%s_%v_%s", call.name, call.arguments, format), func(t *testing.T) {
+				outputs := make(map[string]string)
+				for mode, server := range servers {
+					request := mcp.CallToolRequest{}
+					request.Params.Name = call.name
+					request.Params.Arguments = map[string]interface{}{"repo_path": localDir, "output_format": format}
+					for k, v := range call.arguments {
+						request.Params.Arguments[k] = v
+					}
+
+					result, err := call.handler(server)(context.Background(), request)
+					require.NoError(t, err)
+					textContent, ok := mcp.AsTextContent(result.Content[0])
+					require.True(t, ok)
+					require.False(t, result.IsError, "%s failed with %s: %s", call.name, mode, textContent.Text)
+					outputs[mode] = textContent.Text
+				}
+				require.Equal(t, outputs["shell"], outputs["go-git"])
+			})
+		}
+	}
+}
+
+func TestBackendsResetSameIndex(t *testing.T) {
+	for _, unborn := range []bool{false, true} {
+		statuses := make(map[string]string)
+		for _, mode := range []string{"shell", "go-git"} {
+			t.Run(fmt.Sprintf("%s_unborn_%v", mode, unborn), func(t *testing.T) {
+				remoteDir := t.TempDir()
+				localDir := t.TempDir()
+				initRepos(t, remoteDir, localDir)
+				if !unborn {
+					createCommit(t, localDir, "a.txt", "one\n", "Add a")
+					require.NoError(t, os.WriteFile(filepath.Join(localDir, "a.txt"), []byte("two\n"), 0644))
+				}
+				require.NoError(t, os.WriteFile(filepath.Join(localDir, "b.txt"), []byte("new\n"), 0644))
+				runGit(t, localDir, "add", ".")
+
+				var gitOps gitops.GitOperations = shell.NewShellGitOperations()
+				if mode == "go-git" {
+					gitOps = gogit.NewGoGitOperations()
+				}
+				server := NewGitServer([]string{localDir}, gitOps, false)
+				request := mcp.CallToolRequest{}
+				request.Params.Name = "git_reset"
+				request.Params.Arguments = map[string]interface{}{"repo_path": localDir}
+				result, err := server.gitResetHandler(context.Background(), request)
+				require.NoError(t, err)
+				require.False(t, result.IsError)
+
+				statuses[mode] = runGit(t, localDir, "status", "--porcelain")
+				require.NotContains(t, runGit(t, localDir, "diff", "--cached", "--name-only"), "b.txt")
+			})
+		}
+		require.Equal(t, statuses["shell"], statuses["go-git"])
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	files := []gitops.FileStatus{
+		{Path: "new.txt", OrigPath: "old.txt", Index: "R", Worktree: " "},
+		{Path: "untracked.txt", Index: "?", Worktree: "?"},
+	}
+	require.Equal(t, "Repository status for /repo:\nR  old.txt -> new.txt\n?? untracked.txt\n", formatStatus("/repo", files))
+	require.Equal(t, "Repository status for /repo:\nNothing to commit, working tree clean\n", formatStatus("/repo", nil))
+}
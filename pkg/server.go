@@ -2,22 +2,37 @@ package pkg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/geropl/git-mcp-go/pkg/gitops"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// RepositoryListChangedNotification is sent to the client when a repository
+// is added to the list of managed repositories
+const RepositoryListChangedNotification = "notifications/repositories/list_changed"
+
 // GitServer represents the Git MCP server
 type GitServer struct {
-	server      *server.MCPServer
-	repoPaths   []string // Changed from single string to array of strings
-	gitOps      gitops.GitOperations
-	writeAccess bool
+	server           *server.MCPServer
+	repoPathsMu      sync.RWMutex // guards repoPaths, which git_init and git_clone add to
+	repoPaths        []string     // Changed from single string to array of strings
+	gitOps           gitops.GitOperations
+	writeAccess      bool
+	cloneDirs        []string // parent directories that repositories may be cloned into, with symlinks resolved
+	cloneURLPatterns []string // URL patterns that may be cloned from, any URL if empty
+	cloneAllowLocal  bool     // whether repositories may be cloned from local paths and file:// URLs
+	timeout          time.Duration            // time limit of a tool call, none if 0
+	toolTimeouts     map[string]time.Duration // time limits of specific tools, overriding timeout
+	commitAuthor     *gitops.Identity         // identity of commits, from git config if nil
 }
 
 // NewGitServer creates a new Git MCP server
@@ -25,6 +40,8 @@ func NewGitServer(repoPaths []string, gitOps gitops.GitOperations, writeAccess b
 	s := server.NewMCPServer(
 		"Git MCP Server",
 		"1.0.0",
+		// The output schemas of the tools are published as resources
+		server.WithResourceCapabilities(false, false),
 	)
 
 	// Normalize repository paths
@@ -41,8 +58,15 @@ func NewGitServer(repoPaths []string, gitOps gitops.GitOperations, writeAccess b
 		}
 		
 		// Check if it's a git repository
-		gitDirPath := filepath.Join(absPath, ".git")
-		if info, err := os.Stat(gitDirPath); err == nil && info.IsDir() {
+		isRepo := false
+		if checker, ok := gitOps.(gitops.RepositoryChecker); ok {
+			isRepo = checker.IsRepository(absPath)
+		} else {
+			gitDirPath := filepath.Join(absPath, ".git")
+			info, err := os.Stat(gitDirPath)
+			isRepo = err == nil && info.IsDir()
+		}
+		if isRepo {
 			normalizedPaths = append(normalizedPaths, absPath)
 		} else {
 			fmt.Fprintf(os.Stderr, "Warning: not a git repository: %s\n", absPath)
@@ -66,7 +90,7 @@ func (s *GitServer) isPathInAllowedRepos(path string) bool {
 	}
 
 	// Check if the path is within any of the allowed repositories
-	for _, repoPath := range s.repoPaths {
+	for _, repoPath := range s.repositories() {
 		if strings.HasPrefix(absPath, repoPath) {
 			return true
 		}
@@ -78,9 +102,9 @@ func (s *GitServer) isPathInAllowedRepos(path string) bool {
 func (s *GitServer) validateRepoPath(requestedPath string) (string, error) {
 	// If no specific path is provided, but we have repositories configured
 	if requestedPath == "" {
-		if len(s.repoPaths) > 0 {
+		if repoPaths := s.repositories(); len(repoPaths) > 0 {
 			// Use the first repository as default
-			return s.repoPaths[0], nil
+			return repoPaths[0], nil
 		}
 		return "", fmt.Errorf("no repository specified and no defaults configured")
 	}
@@ -101,18 +125,320 @@ func (s *GitServer) validateRepoPath(requestedPath string) (string, error) {
 
 	// Ensure it's a valid git repository
 	gitDirPath := filepath.Join(absPath, ".git")
-	if _, err := os.Stat(gitDirPath); os.IsNotExist(err) {
+	if checker, ok := s.gitOps.(gitops.RepositoryChecker); ok {
+		if !checker.IsRepository(absPath) {
+			return "", fmt.Errorf("not a git repository: %s", absPath)
+		}
+	} else if _, err := os.Stat(gitDirPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("not a git repository: %s", absPath)
 	}
 
 	return absPath, nil
 }
 
+// validatePatchPaths checks that a patch only changes files inside the
+// repository, like validateRepoPath checks the paths of repositories
+func validatePatchPaths(repoPath string, patch string) error {
+	files, err := gitops.ParsePatchFiles(patch)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no changes found in patch")
+	}
+
+	for _, file := range files {
+		for _, patchPath := range []string{file.Path, file.OldPath} {
+			if patchPath == "" {
+				continue
+			}
+			absPath := filepath.Join(repoPath, filepath.FromSlash(patchPath))
+			relPath, err := filepath.Rel(repoPath, absPath)
+			if err != nil || filepath.IsAbs(filepath.FromSlash(patchPath)) || strings.HasPrefix(patchPath, "/") ||
+				relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("access denied - path outside repository: %s", patchPath)
+			}
+			if first, _, _ := strings.Cut(filepath.ToSlash(relPath), "/"); strings.EqualFold(first, ".git") {
+				return fmt.Errorf("access denied - path inside the git directory: %s", patchPath)
+			}
+		}
+	}
+	return nil
+}
+
 // getRepoPathForOperation determines which repo path to use for an operation
 func (s *GitServer) getRepoPathForOperation(requestedPath string) (string, error) {
 	return s.validateRepoPath(requestedPath)
 }
 
+// repositories returns a copy of the list of managed repositories, which
+// tool calls may extend concurrently
+func (s *GitServer) repositories() []string {
+	s.repoPathsMu.RLock()
+	defer s.repoPathsMu.RUnlock()
+	return append([]string(nil), s.repoPaths...)
+}
+
+// addRepository adds a repository to the list of managed repositories and
+// notifies the client that the list changed
+func (s *GitServer) addRepository(repoPath string) {
+	s.repoPathsMu.Lock()
+	s.repoPaths = append(s.repoPaths, repoPath)
+	repoPaths := append([]string(nil), s.repoPaths...)
+	s.repoPathsMu.Unlock()
+
+	// The notification can only fail if the client can't receive it, in which
+	// case it will see the new repository the next time it lists them
+	_ = s.server.SendNotificationToClient(RepositoryListChangedNotification, map[string]interface{}{
+		"repositories": repoPaths,
+	})
+}
+
+// SetTimeouts limits how long a tool call may take, after which the git
+// operation is canceled. toolTimeouts overrides the limit for individual tools by
+// name, a limit of 0 means no limit. It must be called before RegisterTools.
+func (s *GitServer) SetTimeouts(timeout time.Duration, toolTimeouts map[string]time.Duration) error {
+	for name, toolTimeout := range toolTimeouts {
+		if _, ok := outputSchemas[name]; !ok {
+			return fmt.Errorf("unknown tool: %s", name)
+		}
+		if toolTimeout < 0 {
+			return fmt.Errorf("negative timeout for %s: %s", name, toolTimeout)
+		}
+	}
+	if timeout < 0 {
+		return fmt.Errorf("negative timeout: %s", timeout)
+	}
+
+	s.timeout = timeout
+	s.toolTimeouts = toolTimeouts
+	return nil
+}
+
+// toolTimeout returns the time limit of a tool, 0 if there is none
+func (s *GitServer) toolTimeout(name string) time.Duration {
+	if timeout, ok := s.toolTimeouts[name]; ok {
+		return timeout
+	}
+	return s.timeout
+}
+
+// SetCommitAuthor sets the identity of the commits made by the server, given as
+// "Name <email>", in place of user.name and user.email from git config. The
+// author argument of git_commit still overrides the author of a commit.
+func (s *GitServer) SetCommitAuthor(author string) error {
+	if author == "" {
+		s.commitAuthor = nil
+		return nil
+	}
+
+	identity, err := gitops.ParseIdentity(author)
+	if err != nil {
+		return err
+	}
+	s.commitAuthor = &identity
+	return nil
+}
+
+// SetCloneRestrictions enables the git_clone tool. Repositories may only be cloned
+// into subdirectories of dirs, and only from URLs matching one of urlPatterns, in
+// which "*" matches any sequence of characters. An empty urlPatterns allows any URL.
+// Local paths and file:// URLs are only allowed if allowLocal is set, since they
+// give access to any repository on the machine. It must be called before RegisterTools.
+func (s *GitServer) SetCloneRestrictions(dirs []string, urlPatterns []string, allowLocal bool) {
+	s.cloneDirs = nil
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err == nil {
+			absDir, err = resolvePath(absDir)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to resolve path %s: %v\n", dir, err)
+			continue
+		}
+		s.cloneDirs = append(s.cloneDirs, absDir)
+	}
+	s.cloneURLPatterns = urlPatterns
+	s.cloneAllowLocal = allowLocal
+}
+
+// resolvePath resolves the symbolic links in an absolute path, whose last
+// elements may not exist yet
+func resolvePath(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return "", fmt.Errorf("dangling symbolic link: %s", path)
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	resolvedParent, err := resolvePath(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
+
+// validateCloneTarget checks that a clone target is inside one of the allowed
+// clone directories, also after resolving symbolic links, and doesn't contain
+// anything yet
+func (s *GitServer) validateCloneTarget(targetPath string) error {
+	resolvedPath, err := resolvePath(targetPath)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	allowed := false
+	for _, dir := range s.cloneDirs {
+		rel, err := filepath.Rel(dir, resolvedPath)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("access denied - path outside allowed clone directories: %s", targetPath)
+	}
+
+	entries, err := os.ReadDir(targetPath)
+	if err == nil && len(entries) > 0 {
+		return fmt.Errorf("destination path already exists and is not an empty directory: %s", targetPath)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	return nil
+}
+
+// validateCloneURL checks a URL against the allowed clone URL patterns, and
+// rejects local repositories unless they are allowed
+func (s *GitServer) validateCloneURL(url string) error {
+	if !s.cloneAllowLocal && !isRemoteCloneURL(url) {
+		return fmt.Errorf("cloning from %s is not allowed, only remote URLs may be cloned unless local clones are enabled with --clone-allow-local", url)
+	}
+	if len(s.cloneURLPatterns) == 0 {
+		return nil
+	}
+
+	for _, pattern := range s.cloneURLPatterns {
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		if regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(url) {
+			return nil
+		}
+	}
+	return fmt.Errorf("cloning from %s is not allowed by the configured URL patterns", url)
+}
+
+// isRemoteCloneURL reports whether git clones url over the network, rather than
+// from the local file system or through a transport helper like "ext::"
+func isRemoteCloneURL(url string) bool {
+	if scheme, _, found := strings.Cut(url, "://"); found {
+		switch strings.ToLower(scheme) {
+		case "http", "https", "ssh", "git", "git+ssh", "ssh+git":
+			return true
+		}
+		return false
+	}
+	if strings.Contains(url, "::") {
+		return false
+	}
+
+	// The scp-like syntax "[user@]host:path", where a colon after a slash is part
+	// of a local path and a single letter before the colon is a Windows drive
+	colon := strings.Index(url, ":")
+	slash := strings.Index(url, "/")
+	return colon > 1 && (slash < 0 || colon < slash)
+}
+
+// getStringArgument returns an optional string argument, or "" if it is missing
+func getStringArgument(request mcp.CallToolRequest, name string) string {
+	if value, ok := request.Params.Arguments[name].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// getBoolArgument returns an optional boolean argument, or false if it is missing
+func getBoolArgument(request mcp.CallToolRequest, name string) bool {
+	if value, ok := request.Params.Arguments[name].(bool); ok {
+		return value
+	}
+	return false
+}
+
+// getIntArgument returns an optional numeric argument, or defaultValue if it is missing
+func getIntArgument(request mcp.CallToolRequest, name string, defaultValue int) int {
+	if value, ok := request.Params.Arguments[name].(float64); ok {
+		return int(value)
+	}
+	return defaultValue
+}
+
+// withStringArray adds an array of strings argument to a tool. mcp-go doesn't
+// have WithStringArray, so the property is built like mcp.WithString does.
+func withStringArray(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		schema := map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}
+		for _, opt := range opts {
+			opt(schema)
+		}
+
+		if required, ok := schema["required"].(bool); ok && required {
+			delete(schema, "required")
+			t.InputSchema.Required = append(t.InputSchema.Required, name)
+		}
+		t.InputSchema.Properties[name] = schema
+	}
+}
+
+// getStringListArgument returns an optional list argument. Lists are accepted
+// both as JSON arrays, whose items are used as they are, and as comma-separated
+// strings of older clients.
+func getStringListArgument(request mcp.CallToolRequest, name string) ([]string, error) {
+	switch value := request.Params.Arguments[name].(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array of strings", name)
+			}
+			result = append(result, str)
+		}
+		return result, nil
+	case string:
+		var result []string
+		for _, item := range strings.Split(value, ",") {
+			if trimmed := strings.TrimSpace(item); trimmed != "" {
+				result = append(result, trimmed)
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+}
+
 func GetReadOnlyToolNames() map[string]bool {
 	return map[string]bool{
 		"git_status":        true,
@@ -121,18 +447,48 @@ func GetReadOnlyToolNames() map[string]bool {
 		"git_diff":          true,
 		"git_log":           true,
 		"git_show":          true,
+		"git_stash_list":    true,
+		"git_stash_show":    true,
+		"git_tag_list":      true,
+		"git_branch_list":   true,
+		"git_blame":         true,
+		"git_read_file":     true,
+		"git_ls_tree":       true,
+		"git_ls_files":      true,
+		"git_grep":          true,
 	}
 }
 
 func GetLocalOnlyToolNames() map[string]bool {
 	// local tools that alter state, complementing the read-only tools
 	result := map[string]bool{
-		"git_init":          true,
-		"git_create_branch": true,
-		"git_checkout":      true,
-		"git_commit":        true,
-		"git_add":           true,
-		"git_reset":         true,
+		"git_init":                true,
+		"git_create_branch":       true,
+		"git_checkout":            true,
+		"git_commit":              true,
+		"git_add":                 true,
+		"git_reset":               true,
+		"git_restore":             true,
+		"git_stage_hunks":         true,
+		"git_stash_push":          true,
+		"git_stash_apply":         true,
+		"git_stash_pop":           true,
+		"git_stash_drop":          true,
+		"git_merge":               true,
+		"git_merge_abort":         true,
+		"git_rebase":              true,
+		"git_rebase_continue":     true,
+		"git_rebase_skip":         true,
+		"git_rebase_abort":        true,
+		"git_cherry_pick":         true,
+		"git_revert":              true,
+		"git_apply":               true,
+		"git_am":                  true,
+		"git_tag_create":          true,
+		"git_tag_delete":          true,
+		"git_branch_delete":       true,
+		"git_branch_rename":       true,
+		"git_branch_set_upstream": true,
 	}
 
 	for toolName := range GetReadOnlyToolNames() {
@@ -141,6 +497,17 @@ func GetLocalOnlyToolNames() map[string]bool {
 	return result
 }
 
+// GetNetworkReadToolNames returns the tools that contact remotes to download
+// changes into the repositories without publishing anything, in contrast to the
+// write-access push tools. git_clone isn't one of them, since it creates new
+// repositories on disk.
+func GetNetworkReadToolNames() map[string]bool {
+	return map[string]bool{
+		"git_fetch": true,
+		"git_pull":  true,
+	}
+}
+
 // RegisterTools registers all Git tools with the MCP server
 func (s *GitServer) RegisterTools() {
 	// Register git_status tool
@@ -148,7 +515,7 @@ func (s *GitServer) RegisterTools() {
 
 	if len(s.repoPaths) == 0 {
 		repoPathDesc = "Path to Git repository"
-		s.server.AddTool(mcp.NewTool("git_status",
+		s.addTool(mcp.NewTool("git_status",
 			mcp.WithDescription("Shows the working tree status"),
 			mcp.WithString("repo_path",
 				mcp.Required(),
@@ -162,7 +529,7 @@ func (s *GitServer) RegisterTools() {
 		} else {
 			repoPathDesc = fmt.Sprintf("Path to Git repository (default: %s, %d repositories available)", defaultRepo, len(s.repoPaths))
 		}
-		s.server.AddTool(mcp.NewTool("git_status",
+		s.addTool(mcp.NewTool("git_status",
 			mcp.WithDescription("Shows the working tree status"),
 			mcp.WithString("repo_path",
 				mcp.Description(repoPathDesc),
@@ -172,7 +539,7 @@ func (s *GitServer) RegisterTools() {
 
 	// Register git_diff_unstaged tool
 	if len(s.repoPaths) == 0 {
-		s.server.AddTool(mcp.NewTool("git_diff_unstaged",
+		s.addTool(mcp.NewTool("git_diff_unstaged",
 			mcp.WithDescription("Shows changes in the working directory that are not yet staged"),
 			mcp.WithString("repo_path",
 				mcp.Required(),
@@ -180,7 +547,7 @@ func (s *GitServer) RegisterTools() {
 			),
 		), s.gitDiffUnstagedHandler)
 	} else {
-		s.server.AddTool(mcp.NewTool("git_diff_unstaged",
+		s.addTool(mcp.NewTool("git_diff_unstaged",
 			mcp.WithDescription("Shows changes in the working directory that are not yet staged"),
 			mcp.WithString("repo_path",
 				mcp.Description(repoPathDesc),
@@ -190,7 +557,7 @@ func (s *GitServer) RegisterTools() {
 
 	// Register git_diff_staged tool
 	if len(s.repoPaths) == 0 {
-		s.server.AddTool(mcp.NewTool("git_diff_staged",
+		s.addTool(mcp.NewTool("git_diff_staged",
 			mcp.WithDescription("Shows changes that are staged for commit"),
 			mcp.WithString("repo_path",
 				mcp.Required(),
@@ -198,7 +565,7 @@ func (s *GitServer) RegisterTools() {
 			),
 		), s.gitDiffStagedHandler)
 	} else {
-		s.server.AddTool(mcp.NewTool("git_diff_staged",
+		s.addTool(mcp.NewTool("git_diff_staged",
 			mcp.WithDescription("Shows changes that are staged for commit"),
 			mcp.WithString("repo_path",
 				mcp.Description(repoPathDesc),
@@ -217,8 +584,10 @@ func (s *GitServer) RegisterTools() {
 			mcp.Required(),
 			mcp.Description("Target branch or commit to compare with"),
 		),
+		withPageSize("lines", defaultLinePageSize),
+		withCursor(),
 	)
-	s.server.AddTool(diffTool, s.gitDiffHandler)
+	s.addTool(diffTool, s.gitDiffHandler)
 
 	// Register git_commit tool
 	commitTool := mcp.NewTool("git_commit",
@@ -228,11 +597,31 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Path to Git repository"),
 		),
 		mcp.WithString("message",
-			mcp.Required(),
-			mcp.Description("Commit message"),
+			mcp.Description("Commit message (required unless amending, which keeps the message if it is empty)"),
+		),
+		mcp.WithString("author",
+			mcp.Description("Author of the commit as \"Name <email>\", if it isn't the committer"),
+		),
+		mcp.WithString("date",
+			mcp.Description("Author date, e.g. '2024-01-31T12:00:00+01:00' (default: now, or the date of the amended commit)"),
+		),
+		withStringArray("trailers",
+			mcp.Description("Trailers appended to the message, e.g. 'Signed-off-by: Name <email>' or 'Refs: #123'"),
+		),
+		mcp.WithBoolean("amend",
+			mcp.Description("Replace the current commit instead of adding a new one, keeping its author unless 'author' is given (default: false)"),
+		),
+		mcp.WithBoolean("allow_empty",
+			mcp.Description("Allow a commit that doesn't change anything (default: false)"),
+		),
+		mcp.WithBoolean("stage_all",
+			mcp.Description("Stage the changes of all tracked files before committing, like 'git commit -a' (default: false)"),
+		),
+		mcp.WithBoolean("sign",
+			mcp.Description("Sign the commit with the configured user.signingkey, even if commit.gpgsign isn't set"),
 		),
 	)
-	s.server.AddTool(commitTool, s.gitCommitHandler)
+	s.addTool(commitTool, s.gitCommitHandler)
 
 	// Register git_add tool
 	addTool := mcp.NewTool("git_add",
@@ -241,13 +630,57 @@ func (s *GitServer) RegisterTools() {
 			mcp.Required(),
 			mcp.Description("Path to Git repository"),
 		),
-		// Note: mcp-go doesn't have WithStringArray, so we'll use a string and parse it
-		mcp.WithString("files",
+		withStringArray("files",
+			mcp.Description("Paths to stage, which may contain wildcards like '*.go' (required unless mode is 'update' or 'all', where they limit the changes to stage)"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Which changes to stage: 'paths' (default) stages all changes of the given paths, 'update' only changes of tracked files like 'git add -u', 'all' all changes including new files like 'git add -A'"),
+			mcp.Enum(string(gitops.AddModePaths), string(gitops.AddModeUpdate), string(gitops.AddModeAll)),
+		),
+		mcp.WithBoolean("intent_to_add",
+			mcp.Description("Only record that new files will be added later, so that they show up in diffs, like 'git add -N' (default: false)"),
+		),
+	)
+	s.addTool(addTool, s.gitAddHandler)
+
+	// Register git_restore tool
+	restoreTool := mcp.NewTool("git_restore",
+		mcp.WithDescription("Restores files in the working tree or unstages them, from the index, HEAD or another revision"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		withStringArray("paths",
+			mcp.Required(),
+			mcp.Description("Paths to restore, which may contain wildcards like '*.go'"),
+		),
+		mcp.WithBoolean("staged",
+			mcp.Description("Restore the index, which unstages the changes of the paths (default: false)"),
+		),
+		mcp.WithBoolean("worktree",
+			mcp.Description("Restore the working tree, which discards the changes of the paths (default: true unless staged is set)"),
+		),
+		mcp.WithString("source",
+			mcp.Description("Revision to restore from (default: the index for the working tree, HEAD for the index)"),
+		),
+	)
+	s.addTool(restoreTool, s.gitRestoreHandler)
+
+	// Register git_stage_hunks tool
+	stageHunksTool := mcp.NewTool("git_stage_hunks",
+		mcp.WithDescription("Stages part of the changes to files: lists the hunks of the unstaged changes with their IDs, and stages the hunks selected by ID. With unstage, the hunks of the staged changes are listed and unstaged instead."),
+		mcp.WithString("repo_path",
 			mcp.Required(),
-			mcp.Description("Comma-separated list of file paths to stage"),
+			mcp.Description("Path to Git repository"),
+		),
+		withStringArray("hunk_ids",
+			mcp.Description("IDs of the hunks to stage or unstage, as listed by a previous call. If empty, the hunks are only listed. IDs stay the same while other hunks are staged."),
+		),
+		mcp.WithBoolean("unstage",
+			mcp.Description("Unstage hunks of the staged changes instead (default: false)"),
 		),
 	)
-	s.server.AddTool(addTool, s.gitAddHandler)
+	s.addTool(stageHunksTool, s.gitStageHunksHandler)
 
 	// Register git_reset tool
 	resetTool := mcp.NewTool("git_reset",
@@ -257,7 +690,7 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Path to Git repository"),
 		),
 	)
-	s.server.AddTool(resetTool, s.gitResetHandler)
+	s.addTool(resetTool, s.gitResetHandler)
 
 	// Register git_log tool
 	logTool := mcp.NewTool("git_log",
@@ -267,10 +700,46 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Path to Git repository"),
 		),
 		mcp.WithNumber("max_count",
-			mcp.Description("Maximum number of commits to show (default: 10)"),
+			mcp.Description("Maximum number of commits to show, same as page_size (default: 10)"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision or range to list, e.g. 'main' or 'v1.0..HEAD' (default: HEAD)"),
+		),
+		mcp.WithString("author",
+			mcp.Description("Only commits whose author ('name <email>') matches this regular expression"),
+		),
+		mcp.WithString("committer",
+			mcp.Description("Only commits whose committer ('name <email>') matches this regular expression"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only commits more recent than this date, e.g. '2024-01-31' or '2 weeks ago'"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only commits older than this date, e.g. '2024-01-31' or 'yesterday'"),
+		),
+		withStringArray("paths",
+			mcp.Description("Only commits that change these paths are shown"),
+		),
+		mcp.WithBoolean("follow",
+			mcp.Description("Continue listing the history of a single file beyond renames (requires exactly one path) (default: false)"),
+		),
+		mcp.WithString("grep",
+			mcp.Description("Only commits whose message matches this regular expression"),
+		),
+		mcp.WithString("pickaxe",
+			mcp.Description("Only commits that add or remove occurrences of this string, e.g. a function name (git log -S)"),
+		),
+		mcp.WithString("pickaxe_regex",
+			mcp.Description("Only commits whose added or removed lines match this regular expression (git log -G)"),
 		),
+		mcp.WithString("merges",
+			mcp.Description("Merge commit filter: 'only' or 'exclude' (default: include merge commits)"),
+			mcp.Enum(string(gitops.LogMergesOnly), string(gitops.LogMergesExclude)),
+		),
+		withPageSize("commits", 10),
+		withCursor(),
 	)
-	s.server.AddTool(logTool, s.gitLogHandler)
+	s.addTool(logTool, s.gitLogHandler)
 
 	// Register git_create_branch tool
 	createBranchTool := mcp.NewTool("git_create_branch",
@@ -287,7 +756,7 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Starting point for the new branch"),
 		),
 	)
-	s.server.AddTool(createBranchTool, s.gitCreateBranchHandler)
+	s.addTool(createBranchTool, s.gitCreateBranchHandler)
 
 	// Register git_checkout tool
 	checkoutTool := mcp.NewTool("git_checkout",
@@ -301,7 +770,7 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Name of branch to checkout"),
 		),
 	)
-	s.server.AddTool(checkoutTool, s.gitCheckoutHandler)
+	s.addTool(checkoutTool, s.gitCheckoutHandler)
 
 	// Register git_show tool
 	showTool := mcp.NewTool("git_show",
@@ -314,8 +783,10 @@ func (s *GitServer) RegisterTools() {
 			mcp.Required(),
 			mcp.Description("The revision (commit hash, branch name, tag) to show"),
 		),
+		withPageSize("lines", defaultLinePageSize),
+		withCursor(),
 	)
-	s.server.AddTool(showTool, s.gitShowHandler)
+	s.addTool(showTool, s.gitShowHandler)
 
 	// Register git_init tool
 	initTool := mcp.NewTool("git_init",
@@ -325,199 +796,1506 @@ func (s *GitServer) RegisterTools() {
 			mcp.Description("Path to directory to initialize git repo"),
 		),
 	)
-	s.server.AddTool(initTool, s.gitInitHandler)
-
-	// Register git_list_repositories tool
-	s.server.AddTool(mcp.NewTool("git_list_repositories",
-		mcp.WithDescription("Lists all available Git repositories"),
-	), s.gitListRepositoriesHandler)
+	s.addTool(initTool, s.gitInitHandler)
 
-	if s.writeAccess {
-		// Register git_push tool
-		pushTool := mcp.NewTool("git_push",
-			mcp.WithDescription("Pushes local commits to a remote repository (requires --write-access flag)"),
-			mcp.WithString("repo_path",
-				mcp.Required(),
-				mcp.Description("Path to Git repository"),
-			),
-			mcp.WithString("remote",
-				mcp.Description("Remote name (default: origin)"),
-			),
-			mcp.WithString("branch",
-				mcp.Description("Branch name to push (default: current branch)"),
-			),
-		)
-		s.server.AddTool(pushTool, s.gitPushHandler)
-	}
-}
+	// Register git_stash_push tool
+	stashPushTool := mcp.NewTool("git_stash_push",
+		mcp.WithDescription("Stashes the changes in the working directory and index"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("message",
+			mcp.Description("Description of the stash entry"),
+		),
+		mcp.WithBoolean("include_untracked",
+			mcp.Description("Also stash untracked files (default: false)"),
+		),
+	)
+	s.addTool(stashPushTool, s.gitStashPushHandler)
 
-// Serve starts the MCP server
-func (s *GitServer) Serve() error {
-	return server.ServeStdio(s.server)
-}
+	// Register git_stash_list tool
+	stashListTool := mcp.NewTool("git_stash_list",
+		mcp.WithDescription("Lists the stash entries with index, branch and message"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+	)
+	s.addTool(stashListTool, s.gitStashListHandler)
 
-// Tool handlers
+	// Register git_stash_show tool
+	stashShowTool := mcp.NewTool("git_stash_show",
+		mcp.WithDescription("Shows the changes recorded in a stash entry"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Index of the stash entry (default: 0)"),
+		),
+	)
+	s.addTool(stashShowTool, s.gitStashShowHandler)
 
-func (s *GitServer) gitStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
-	repoPath, err := s.getRepoPathForOperation(requestedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
-	}
+	// Register git_stash_apply tool
+	stashApplyTool := mcp.NewTool("git_stash_apply",
+		mcp.WithDescription("Applies a stash entry on top of the working directory, keeping it in the stash list"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Index of the stash entry (default: 0)"),
+		),
+	)
+	s.addTool(stashApplyTool, s.gitStashApplyHandler)
 
-	status, err := s.gitOps.GetStatus(repoPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get status: %v", err)), nil
-	}
+	// Register git_stash_pop tool
+	stashPopTool := mcp.NewTool("git_stash_pop",
+		mcp.WithDescription("Applies a stash entry and removes it from the stash list"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Index of the stash entry (default: 0)"),
+		),
+	)
+	s.addTool(stashPopTool, s.gitStashPopHandler)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Repository status for %s:\n%s", repoPath, status)), nil
-}
+	// Register git_stash_drop tool
+	stashDropTool := mcp.NewTool("git_stash_drop",
+		mcp.WithDescription("Removes a stash entry from the stash list"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Index of the stash entry (default: 0)"),
+		),
+	)
+	s.addTool(stashDropTool, s.gitStashDropHandler)
 
-func (s *GitServer) gitDiffUnstagedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
-	repoPath, err := s.getRepoPathForOperation(requestedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
-	}
+	// Register git_merge tool
+	mergeTool := mcp.NewTool("git_merge",
+		mcp.WithDescription("Merges a branch into the current branch, reporting conflicted files and hunks if the merge stops"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("Branch or commit to merge into the current branch"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Merge mode: 'ff-only', 'no-ff' or 'squash' (default: fast-forward if possible, merge commit otherwise)"),
+			mcp.Enum(string(gitops.MergeModeFastForwardOnly), string(gitops.MergeModeNoFastForward), string(gitops.MergeModeSquash)),
+		),
+		mcp.WithString("message",
+			mcp.Description("Message for the merge commit"),
+		),
+	)
+	s.addTool(mergeTool, s.gitMergeHandler)
 
-	diff, err := s.gitOps.GetDiffUnstaged(repoPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get unstaged diff: %v", err)), nil
-	}
+	// Register git_merge_abort tool
+	mergeAbortTool := mcp.NewTool("git_merge_abort",
+		mcp.WithDescription("Aborts an in-progress merge and restores the pre-merge state"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+	)
+	s.addTool(mergeAbortTool, s.gitMergeAbortHandler)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Unstaged changes for %s:\n%s", repoPath, diff)), nil
-}
+	// Register git_rebase tool
+	rebaseTool := mcp.NewTool("git_rebase",
+		mcp.WithDescription("Rebases the current branch onto another branch without user interaction, reporting the rebase state if it stops on conflicts"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("upstream",
+			mcp.Required(),
+			mcp.Description("Upstream branch or commit to rebase onto"),
+		),
+		mcp.WithString("onto",
+			mcp.Description("Starting point for the new commits, if different from upstream"),
+		),
+		mcp.WithBoolean("autosquash",
+			mcp.Description("Squash fixup!/squash! commits into their targets (default: false)"),
+		),
+	)
+	s.addTool(rebaseTool, s.gitRebaseHandler)
 
-func (s *GitServer) gitDiffStagedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	// Register git_rebase_continue tool
+	rebaseContinueTool := mcp.NewTool("git_rebase_continue",
+		mcp.WithDescription("Continues an in-progress rebase after resolving and staging conflicted files"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+	)
+	s.addTool(rebaseContinueTool, s.gitRebaseContinueHandler)
+
+	// Register git_rebase_skip tool
+	rebaseSkipTool := mcp.NewTool("git_rebase_skip",
+		mcp.WithDescription("Skips the commit currently being applied and continues the rebase"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+	)
+	s.addTool(rebaseSkipTool, s.gitRebaseSkipHandler)
+
+	// Register git_rebase_abort tool
+	rebaseAbortTool := mcp.NewTool("git_rebase_abort",
+		mcp.WithDescription("Aborts an in-progress rebase and restores the original branch"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+	)
+	s.addTool(rebaseAbortTool, s.gitRebaseAbortHandler)
+
+	// Register git_cherry_pick tool
+	cherryPickTool := mcp.NewTool("git_cherry_pick",
+		mcp.WithDescription("Applies the changes introduced by existing commits, reporting conflicted files and hunks if a pick does not apply cleanly"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		withStringArray("revisions",
+			mcp.Required(),
+			mcp.Description("Revisions (commits or ranges) to cherry-pick, applied in order"),
+		),
+		mcp.WithBoolean("record_origin",
+			mcp.Description("Append a \"(cherry picked from commit ...)\" line to the commit message (default: false)"),
+		),
+		mcp.WithBoolean("no_commit",
+			mcp.Description("Apply the changes to the working tree and index without committing (default: false)"),
+		),
+	)
+	s.addTool(cherryPickTool, s.gitCherryPickHandler)
+
+	// Register git_revert tool
+	revertTool := mcp.NewTool("git_revert",
+		mcp.WithDescription("Records new commits that undo the changes of existing commits, reporting conflicted files and hunks if a revert does not apply cleanly"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		withStringArray("revisions",
+			mcp.Required(),
+			mcp.Description("Revisions to revert, applied in order"),
+		),
+		mcp.WithBoolean("no_commit",
+			mcp.Description("Revert the changes in the working tree and index without committing (default: false)"),
+		),
+	)
+	s.addTool(revertTool, s.gitRevertHandler)
+
+	// Register git_apply tool
+	applyTool := mcp.NewTool("git_apply",
+		mcp.WithDescription("Applies a patch in the unified diff format to the working tree or the index, reporting for every file whether its changes apply. Nothing is applied unless the changes to all files apply."),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("patch",
+			mcp.Required(),
+			mcp.Description("Patch to apply, like the output of git diff. Paths must be relative to the repository root."),
+		),
+		mcp.WithBoolean("check",
+			mcp.Description("Only check whether the patch applies, without changing anything (default: false)"),
+		),
+		mcp.WithBoolean("three_way",
+			mcp.Description("Fall back to a three-way merge for changes that don't apply, which may leave conflicts to resolve (default: false)"),
+		),
+		mcp.WithBoolean("cached",
+			mcp.Description("Apply the patch to the index only, without touching the working tree (default: false)"),
+		),
+	)
+	s.addTool(applyTool, s.gitApplyHandler)
+
+	// Register git_am tool
+	amTool := mcp.NewTool("git_am",
+		mcp.WithDescription("Commits patches in the mailbox format, keeping their authors and messages. If a patch doesn't apply, none of them is committed."),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("mailbox",
+			mcp.Required(),
+			mcp.Description("Patches to commit, like the output of git format-patch --stdout. Paths must be relative to the repository root."),
+		),
+		mcp.WithBoolean("three_way",
+			mcp.Description("Fall back to a three-way merge for patches that don't apply (default: false)"),
+		),
+	)
+	s.addTool(amTool, s.gitAmHandler)
+
+	// Register git_tag_list tool
+	tagListTool := mcp.NewTool("git_tag_list",
+		mcp.WithDescription("Lists tags with their target commit, type and message"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Glob pattern to filter tag names (e.g. 'v1.*')"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Sort order: 'name', 'version' or 'date' (oldest first) (default: name)"),
+			mcp.Enum(string(gitops.TagSortName), string(gitops.TagSortVersion), string(gitops.TagSortDate)),
+		),
+	)
+	s.addTool(tagListTool, s.gitTagListHandler)
+
+	// Register git_tag_create tool
+	tagCreateTool := mcp.NewTool("git_tag_create",
+		mcp.WithDescription("Creates an annotated tag if a message is given, a lightweight tag otherwise"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the new tag"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to tag (default: HEAD)"),
+		),
+		mcp.WithString("message",
+			mcp.Description("Tag message, creates an annotated tag"),
+		),
+	)
+	s.addTool(tagCreateTool, s.gitTagCreateHandler)
+
+	// Register git_tag_delete tool
+	tagDeleteTool := mcp.NewTool("git_tag_delete",
+		mcp.WithDescription("Deletes a local tag"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the tag to delete"),
+		),
+	)
+	s.addTool(tagDeleteTool, s.gitTagDeleteHandler)
+
+	// Register git_branch_list tool
+	branchListTool := mcp.NewTool("git_branch_list",
+		mcp.WithDescription("Lists branches with their upstream, ahead/behind counts and last commit"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithBoolean("include_remote",
+			mcp.Description("Also list remote-tracking branches (default: false)"),
+		),
+	)
+	s.addTool(branchListTool, s.gitBranchListHandler)
+
+	// Register git_branch_delete tool
+	branchDeleteTool := mcp.NewTool("git_branch_delete",
+		mcp.WithDescription("Deletes a local branch, refusing branches that are not fully merged unless forced"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("branch_name",
+			mcp.Required(),
+			mcp.Description("Name of the branch to delete"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Delete the branch even if it is not fully merged (default: false)"),
+		),
+	)
+	s.addTool(branchDeleteTool, s.gitBranchDeleteHandler)
+
+	// Register git_branch_rename tool
+	branchRenameTool := mcp.NewTool("git_branch_rename",
+		mcp.WithDescription("Renames a local branch"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("old_name",
+			mcp.Required(),
+			mcp.Description("Current name of the branch"),
+		),
+		mcp.WithString("new_name",
+			mcp.Required(),
+			mcp.Description("New name of the branch"),
+		),
+	)
+	s.addTool(branchRenameTool, s.gitBranchRenameHandler)
+
+	// Register git_branch_set_upstream tool
+	branchSetUpstreamTool := mcp.NewTool("git_branch_set_upstream",
+		mcp.WithDescription("Sets the upstream branch that a local branch tracks"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("branch_name",
+			mcp.Description("Local branch to configure (default: current branch)"),
+		),
+		mcp.WithString("upstream",
+			mcp.Required(),
+			mcp.Description("Upstream branch, e.g. 'origin/main'"),
+		),
+	)
+	s.addTool(branchSetUpstreamTool, s.gitBranchSetUpstreamHandler)
+
+	// Register git_blame tool
+	blameTool := mcp.NewTool("git_blame",
+		mcp.WithDescription("Shows the commit, author, date and summary that last changed each line of a file"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("file_path",
+			mcp.Required(),
+			mcp.Description("Path of the file to blame, relative to the repository root"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to blame the file at (default: HEAD)"),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("First line to blame, starting at 1 (default: first line of the file)"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line to blame (default: last line of the file)"),
+		),
+	)
+	s.addTool(blameTool, s.gitBlameHandler)
+
+	// Register git_read_file tool
+	readFileTool := mcp.NewTool("git_read_file",
+		mcp.WithDescription("Reads a file as it exists at a revision, such as a tag or branch. Binary files are described by size and hash instead of content"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("file_path",
+			mcp.Required(),
+			mcp.Description("Path of the file, relative to the repository root"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to read the file at (default: HEAD)"),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("First line to read, starting at 1 (default: first line of the file)"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line to read (default: last line of the file)"),
+		),
+	)
+	s.addTool(readFileTool, s.gitReadFileHandler)
+
+	// Register git_ls_tree tool
+	lsTreeTool := mcp.NewTool("git_ls_tree",
+		mcp.WithDescription("Lists the files and directories of a repository at a revision"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to list (default: HEAD)"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Directory to list, relative to the repository root (default: root)"),
+		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("List the files in all subdirectories instead of only the direct entries (default: false)"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Glob pattern matched against the path or file name (e.g. '*.go')"),
+		),
+		mcp.WithBoolean("long",
+			mcp.Description("Include modes, object types, hashes and sizes (default: false)"),
+		),
+	)
+	s.addTool(lsTreeTool, s.gitLsTreeHandler)
+
+	// Register git_ls_files tool
+	lsFilesTool := mcp.NewTool("git_ls_files",
+		mcp.WithDescription("Lists the files in the index, optionally including untracked and ignored files"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Glob pattern matched against the path or file name (e.g. '*.go')"),
+		),
+		mcp.WithBoolean("include_untracked",
+			mcp.Description("Include untracked files that aren't ignored (default: false)"),
+		),
+		mcp.WithBoolean("include_ignored",
+			mcp.Description("Include ignored files, listing ignored directories once (default: false)"),
+		),
+	)
+	s.addTool(lsFilesTool, s.gitLsFilesHandler)
+
+	// Register git_grep tool
+	grepTool := mcp.NewTool("git_grep",
+		mcp.WithDescription("Searches the tracked files in the working tree, or the files at a revision, for lines matching a pattern"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("Extended regular expression to search for, or literal text if fixed_strings is set"),
+		),
+		mcp.WithBoolean("fixed_strings",
+			mcp.Description("Match the pattern as literal text (default: false)"),
+		),
+		mcp.WithBoolean("ignore_case",
+			mcp.Description("Match case-insensitively (default: false)"),
+		),
+		withStringArray("pathspecs",
+			mcp.Description("Directories or globs to limit the search to (e.g. 'src/' or '*.go')"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to search (default: the working tree)"),
+		),
+		mcp.WithNumber("context_lines",
+			mcp.Description("Number of lines to show before and after each match (default: 0)"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description("Maximum number of matching lines to return (default: 100)"),
+		),
+	)
+	s.addTool(grepTool, s.gitGrepHandler)
+
+	// Register git_fetch tool
+	fetchTool := mcp.NewTool("git_fetch",
+		mcp.WithDescription("Downloads objects and refs from a remote repository without changing local branches"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("remote",
+			mcp.Description("Remote name (default: origin)"),
+		),
+		mcp.WithString("refspec",
+			mcp.Description("Branch or refspec to fetch (default: the remote's configured refspecs)"),
+		),
+		mcp.WithBoolean("prune",
+			mcp.Description("Remove remote-tracking branches that no longer exist on the remote (default: false)"),
+		),
+		mcp.WithBoolean("tags",
+			mcp.Description("Fetch all tags from the remote (default: false)"),
+		),
+	)
+	s.addTool(fetchTool, s.gitFetchHandler)
+
+	// Register git_pull tool
+	pullTool := mcp.NewTool("git_pull",
+		mcp.WithDescription("Fetches from a remote repository and integrates the changes into the current branch, reporting conflicted files and hunks if it stops"),
+		mcp.WithString("repo_path",
+			mcp.Required(),
+			mcp.Description("Path to Git repository"),
+		),
+		mcp.WithString("remote",
+			mcp.Description("Remote name (default: upstream of the current branch)"),
+		),
+		mcp.WithString("branch",
+			mcp.Description("Remote branch to pull (default: upstream of the current branch)"),
+		),
+		mcp.WithString("strategy",
+			mcp.Description("How to integrate the changes: 'merge' or 'rebase' (default: merge)"),
+			mcp.Enum(string(gitops.PullStrategyMerge), string(gitops.PullStrategyRebase)),
+		),
+		mcp.WithBoolean("ff_only",
+			mcp.Description("Refuse to pull unless the current branch can be fast-forwarded (default: false)"),
+		),
+	)
+	s.addTool(pullTool, s.gitPullHandler)
+
+	// Register git_list_repositories tool
+	s.addTool(mcp.NewTool("git_list_repositories",
+		mcp.WithDescription("Lists all available Git repositories"),
+	), s.gitListRepositoriesHandler)
+
+	if len(s.cloneDirs) > 0 {
+		// Register git_clone tool
+		cloneTool := mcp.NewTool("git_clone",
+			mcp.WithDescription("Clones a repository into an allowed clone directory and adds it to the available repositories (requires --clone-dir flag)"),
+			mcp.WithString("url",
+				mcp.Required(),
+				mcp.Description("URL of the repository to clone"),
+			),
+			mcp.WithString("target_path",
+				mcp.Required(),
+				mcp.Description("Path to clone into, inside one of the allowed clone directories"),
+			),
+			mcp.WithString("branch",
+				mcp.Description("Branch to check out instead of the remote's HEAD"),
+			),
+			mcp.WithNumber("depth",
+				mcp.Description("Create a shallow clone with the given number of commits (default: full history)"),
+			),
+			mcp.WithString("filter",
+				mcp.Description("Partial clone filter, e.g. 'blob:none'"),
+			),
+		)
+		s.addTool(cloneTool, s.gitCloneHandler)
+	}
+
+	if s.writeAccess {
+		// Register git_push tool
+		pushTool := mcp.NewTool("git_push",
+			mcp.WithDescription("Pushes local commits to a remote repository (requires --write-access flag)"),
+			mcp.WithString("repo_path",
+				mcp.Required(),
+				mcp.Description("Path to Git repository"),
+			),
+			mcp.WithString("remote",
+				mcp.Description("Remote name (default: origin)"),
+			),
+			mcp.WithString("branch",
+				mcp.Description("Branch name to push (default: current branch)"),
+			),
+		)
+		s.addTool(pushTool, s.gitPushHandler)
+
+		// Register git_push_tags tool
+		pushTagsTool := mcp.NewTool("git_push_tags",
+			mcp.WithDescription("Pushes tags to a remote repository (requires --write-access flag)"),
+			mcp.WithString("repo_path",
+				mcp.Required(),
+				mcp.Description("Path to Git repository"),
+			),
+			mcp.WithString("remote",
+				mcp.Description("Remote name (default: origin)"),
+			),
+			withStringArray("tags",
+				mcp.Description("Tags to push (default: all tags)"),
+			),
+		)
+		s.addTool(pushTagsTool, s.gitPushTagsHandler)
+	}
+}
+
+// Serve starts the MCP server
+func (s *GitServer) Serve() error {
+	return server.ServeStdio(s.server)
+}
+
+// Tool handlers
+
+func (s *GitServer) gitStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	files, err := s.gitOps.GetStatus(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get status: %v", err)), nil
+	}
+
+	return toolResult(request, formatStatus(repoPath, files), StatusResult{RepoPath: repoPath, Clean: len(files) == 0, Files: nonNil(files)}), nil
+}
+
+func (s *GitServer) gitDiffUnstagedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	files, err := s.gitOps.GetDiffUnstaged(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get unstaged diff: %v", err)), nil
+	}
+
+	diff := formatDiff(files)
+	return toolResult(request, fmt.Sprintf("Unstaged changes for %s:\n%s", repoPath, diff), DiffResult{RepoPath: repoPath, Diff: diff, Files: nonNil(files)}), nil
+}
+
+func (s *GitServer) gitDiffStagedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	files, err := s.gitOps.GetDiffStaged(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get staged diff: %v", err)), nil
+	}
+
+	diff := formatDiff(files)
+	return toolResult(request, fmt.Sprintf("Staged changes for %s:\n%s", repoPath, diff), DiffResult{RepoPath: repoPath, Diff: diff, Files: nonNil(files)}), nil
+}
+
+func (s *GitServer) gitDiffHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	target, ok := request.Params.Arguments["target"].(string)
+	if !ok {
+		return mcp.NewToolResultError("target must be a string"), nil
+	}
+
+	page, err := getPagination(request, defaultLinePageSize)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Pagination error: %v", err)), nil
+	}
+
+	files, err := s.gitOps.GetDiff(ctx, repoPath, target)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get diff: %v", err)), nil
+	}
+
+	diff := formatDiff(files)
+	if wantsJSON(request) {
+		diffPage, count, hasMore := page.pageOfLines(diff)
+		return jsonResult(request, DiffResult{RepoPath: repoPath, Target: target, Diff: diffPage, Files: nonNil(files), NextCursor: page.cursorIfMore(count, hasMore)}), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Diff with %s for %s:\n%s", target, repoPath, page.paginateLines(diff))), nil
+}
+
+func (s *GitServer) gitCommitHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	opts := gitops.CommitOptions{
+		Committer:  s.commitAuthor,
+		Sign:       getBoolArgument(request, "sign"),
+		Amend:      getBoolArgument(request, "amend"),
+		AllowEmpty: getBoolArgument(request, "allow_empty"),
+		All:        getBoolArgument(request, "stage_all"),
+	}
+
+	message := getStringArgument(request, "message")
+	if message == "" && !opts.Amend {
+		return mcp.NewToolResultError("message is required unless amending"), nil
+	}
+
+	if author := getStringArgument(request, "author"); author != "" {
+		identity, err := gitops.ParseIdentity(author)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid author: %v", err)), nil
+		}
+		opts.Author = &identity
+	}
+	if date := getStringArgument(request, "date"); date != "" {
+		var ok bool
+		opts.Date, ok = gitops.ParseLogDate(date)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid date: %s", date)), nil
+		}
+	}
+	trailers, err := getStringListArgument(request, "trailers")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, trailer := range trailers {
+		parsed, err := gitops.ParseTrailer(trailer)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid trailer: %v", err)), nil
+		}
+		opts.Trailers = append(opts.Trailers, parsed)
+	}
+
+	result, err := s.gitOps.CommitChanges(ctx, repoPath, message, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to commit: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitAddHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	opts := gitops.AddOptions{
+		Mode:        gitops.AddMode(getStringArgument(request, "mode")),
+		IntentToAdd: getBoolArgument(request, "intent_to_add"),
+	}
+
+	files, err := getStringListArgument(request, "files")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := s.gitOps.AddFiles(ctx, repoPath, files, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to add files: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitRestoreHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	paths, err := getStringListArgument(request, "paths")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(paths) == 0 {
+		return mcp.NewToolResultError("paths must not be empty"), nil
+	}
+
+	result, err := s.gitOps.Restore(ctx, repoPath, paths, gitops.RestoreOptions{
+		Source:   getStringArgument(request, "source"),
+		Staged:   getBoolArgument(request, "staged"),
+		Worktree: getBoolArgument(request, "worktree"),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitStageHunksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	unstage := getBoolArgument(request, "unstage")
+	message := ""
+	ids, err := getStringListArgument(request, "hunk_ids")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(ids) > 0 {
+		message, err = s.gitOps.StageHunks(ctx, repoPath, ids, unstage)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to stage hunks: %v", err)), nil
+		}
+	}
+
+	var files []gitops.DiffFile
+	if unstage {
+		files, err = s.gitOps.GetDiffStaged(ctx, repoPath)
+	} else {
+		files, err = s.gitOps.GetDiffUnstaged(ctx, repoPath)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list hunks: %v", err)), nil
+	}
+	hunks, err := gitops.ParseHunks(files)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list hunks: %v", err)), nil
+	}
+
+	text := formatHunks(repoPath, hunks, unstage)
+	if message != "" {
+		text = message + "\n\n" + text
+	}
+	return toolResult(request, text, HunksResult{RepoPath: repoPath, Message: message, Staged: unstage, Hunks: nonNil(hunks)}), nil
+}
+
+func (s *GitServer) gitResetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	result, err := s.gitOps.ResetStaged(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to reset: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitLogHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	maxCount := 10
+	if maxCountInterface, ok := request.Params.Arguments["max_count"]; ok {
+		if maxCountFloat, ok := maxCountInterface.(float64); ok {
+			maxCount = int(maxCountFloat)
+		}
+	}
+
+	paths, err := getStringListArgument(request, "paths")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// max_count predates pagination and serves as the default page size
+	page, err := getPagination(request, maxCount)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Pagination error: %v", err)), nil
+	}
+
+	opts := gitops.LogOptions{
+		// Get one more commit than requested to know whether there is another page
+		MaxCount:      page.pageSize + 1,
+		Skip:          page.offset,
+		Revision:      getStringArgument(request, "revision"),
+		Author:        getStringArgument(request, "author"),
+		Committer:     getStringArgument(request, "committer"),
+		Since:         getStringArgument(request, "since"),
+		Until:         getStringArgument(request, "until"),
+		Paths:         paths,
+		Follow:        getBoolArgument(request, "follow"),
+		Grep:          getStringArgument(request, "grep"),
+		PickaxeString: getStringArgument(request, "pickaxe"),
+		PickaxeRegex:  getStringArgument(request, "pickaxe_regex"),
+		Merges:        gitops.LogMergeFilter(getStringArgument(request, "merges")),
+	}
+
+	logs, err := s.gitOps.GetLog(ctx, repoPath, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get log: %v", err)), nil
+	}
+
+	data := LogResult{RepoPath: repoPath, Commits: []gitops.CommitInfo{}}
+	if len(logs) == 0 {
+		if page.offset > 0 {
+			return toolResult(request, fmt.Sprintf("No more commits for %s", repoPath), data), nil
+		}
+		return toolResult(request, fmt.Sprintf("No matching commits for %s", repoPath), data), nil
+	}
+
+	hasMore := len(logs) > page.pageSize
+	if hasMore {
+		logs = logs[:page.pageSize]
+	}
+
+	data.Commits = logs
+	data.NextCursor = page.cursorIfMore(len(logs), hasMore)
+
+	return toolResult(request, fmt.Sprintf("Commit history for %s:\n%s%s", repoPath, formatCommits(logs), page.footer("commits", len(logs), hasMore)), data), nil
+}
+
+func (s *GitServer) gitCreateBranchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	branchName, ok := request.Params.Arguments["branch_name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("branch_name must be a string"), nil
+	}
+
+	baseBranch := ""
+	if baseBranchInterface, ok := request.Params.Arguments["base_branch"]; ok {
+		if baseBranchStr, ok := baseBranchInterface.(string); ok {
+			baseBranch = baseBranchStr
+		}
+	}
+
+	result, err := s.gitOps.CreateBranch(ctx, repoPath, branchName, baseBranch)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create branch: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitCheckoutHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	branchName, ok := request.Params.Arguments["branch_name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("branch_name must be a string"), nil
+	}
+
+	result, err := s.gitOps.CheckoutBranch(ctx, repoPath, branchName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to checkout branch: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitShowHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	revision, ok := request.Params.Arguments["revision"].(string)
+	if !ok {
+		return mcp.NewToolResultError("revision must be a string"), nil
+	}
+
+	page, err := getPagination(request, defaultLinePageSize)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Pagination error: %v", err)), nil
+	}
+
+	result, err := s.gitOps.ShowCommit(ctx, repoPath, revision)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to show commit: %v", err)), nil
+	}
+
+	if wantsJSON(request) {
+		output, count, hasMore := page.pageOfLines(result)
+		return jsonResult(request, ShowResult{RepoPath: repoPath, Revision: revision, Output: output, NextCursor: page.cursorIfMore(count, hasMore)}), nil
+	}
+
+	return mcp.NewToolResultText(page.paginateLines(result)), nil
+}
+
+func (s *GitServer) gitInitHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+	
+	// For init, we don't validate through getRepoPathForOperation since we're creating a new repo
+	if requestedPath == "" {
+		return mcp.NewToolResultError("repo_path must be specified for initialization"), nil
+	}
+
+	// Ensure the path is absolute
+	absPath, err := filepath.Abs(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get absolute path: %v", err)), nil
+	}
+
+	result, err := s.gitOps.InitRepo(ctx, absPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize repository: %v", err)), nil
+	}
+
+	// Add the new repository to our list of managed repositories
+	s.addRepository(absPath)
+
+	return toolResult(request, result, MessageResult{RepoPath: absPath, Message: result}), nil
+}
+
+func (s *GitServer) gitPushHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Check if write access is enabled
+	if !s.writeAccess {
+		return mcp.NewToolResultError("Write access is disabled. Use --write-access flag to enable remote operations."), nil
+	}
+
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
 	
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	diff, err := s.gitOps.GetDiffStaged(repoPath)
+	remote := ""
+	if remoteInterface, ok := request.Params.Arguments["remote"]; ok {
+		if remoteStr, ok := remoteInterface.(string); ok {
+			remote = remoteStr
+		}
+	}
+
+	branch := ""
+	if branchInterface, ok := request.Params.Arguments["branch"]; ok {
+		if branchStr, ok := branchInterface.(string); ok {
+			branch = branchStr
+		}
+	}
+
+	result, err := s.gitOps.PushChanges(ctx, repoPath, remote, branch)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to push changes: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitStashPushHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	message := getStringArgument(request, "message")
+	includeUntracked := getBoolArgument(request, "include_untracked")
+
+	result, err := s.gitOps.StashPush(ctx, repoPath, message, includeUntracked)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to stash changes: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitStashListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	entries, err := s.gitOps.StashList(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list stashes: %v", err)), nil
+	}
+
+	return toolResult(request, formatStashList(repoPath, entries), StashListResult{RepoPath: repoPath, Entries: nonNil(entries)}), nil
+}
+
+func (s *GitServer) gitStashShowHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	index := getIntArgument(request, "index", 0)
+
+	files, err := s.gitOps.StashShow(ctx, repoPath, index)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to show stash: %v", err)), nil
+	}
+
+	diff := formatDiff(files)
+	return toolResult(request, fmt.Sprintf("Changes in %s for %s:\n%s", gitops.StashRef(index), repoPath, diff),
+		DiffResult{RepoPath: repoPath, Target: gitops.StashRef(index), Diff: diff, Files: nonNil(files)}), nil
+}
+
+func (s *GitServer) gitStashApplyHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	index := getIntArgument(request, "index", 0)
+
+	result, err := s.gitOps.StashApply(ctx, repoPath, index)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to apply stash: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitStashPopHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	index := getIntArgument(request, "index", 0)
+
+	result, err := s.gitOps.StashPop(ctx, repoPath, index)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to pop stash: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitStashDropHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	index := getIntArgument(request, "index", 0)
+
+	result, err := s.gitOps.StashDrop(ctx, repoPath, index)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to drop stash: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitMergeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	branch, ok := request.Params.Arguments["branch"].(string)
+	if !ok {
+		return mcp.NewToolResultError("branch must be a string"), nil
+	}
+
+	mode := gitops.MergeMode(getStringArgument(request, "mode"))
+	message := getStringArgument(request, "message")
+
+	result, err := s.gitOps.MergeBranch(ctx, repoPath, branch, mode, message)
+	if err != nil {
+		var conflictErr *gitops.ConflictError
+		if errors.As(err, &conflictErr) {
+			hint := "Resolve the conflicts, stage the files with git_add and commit with git_commit, or use git_merge_abort to back out."
+			return toolResult(request, formatConflicts(conflictErr, hint), conflictResult(repoPath, conflictErr, hint)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to merge: %v", err)), nil
+	}
+
+	return toolResult(request, result, OperationResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitMergeAbortHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	result, err := s.gitOps.MergeAbort(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to abort merge: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitRebaseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	upstream, ok := request.Params.Arguments["upstream"].(string)
+	if !ok {
+		return mcp.NewToolResultError("upstream must be a string"), nil
+	}
+
+	onto := getStringArgument(request, "onto")
+	autosquash := getBoolArgument(request, "autosquash")
+
+	state, err := s.gitOps.Rebase(ctx, repoPath, upstream, onto, autosquash)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to rebase: %v", err)), nil
+	}
+
+	return toolResult(request, formatRebaseState(repoPath, state), RebaseResult{RepoPath: repoPath, RebaseState: *state}), nil
+}
+
+func (s *GitServer) gitRebaseContinueHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	state, err := s.gitOps.RebaseContinue(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to continue rebase: %v", err)), nil
+	}
+
+	return toolResult(request, formatRebaseState(repoPath, state), RebaseResult{RepoPath: repoPath, RebaseState: *state}), nil
+}
+
+func (s *GitServer) gitRebaseSkipHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	state, err := s.gitOps.RebaseSkip(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to skip commit: %v", err)), nil
+	}
+
+	return toolResult(request, formatRebaseState(repoPath, state), RebaseResult{RepoPath: repoPath, RebaseState: *state}), nil
+}
+
+func (s *GitServer) gitRebaseAbortHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	result, err := s.gitOps.RebaseAbort(ctx, repoPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to abort rebase: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitCherryPickHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	revisions, err := getStringListArgument(request, "revisions")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get staged diff: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(revisions) == 0 {
+		return mcp.NewToolResultError("revisions must contain at least one revision"), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Staged changes for %s:\n%s", repoPath, diff)), nil
+	recordOrigin := getBoolArgument(request, "record_origin")
+	noCommit := getBoolArgument(request, "no_commit")
+
+	result, err := s.gitOps.CherryPick(ctx, repoPath, revisions, recordOrigin, noCommit)
+	if err != nil {
+		var conflictErr *gitops.ConflictError
+		if errors.As(err, &conflictErr) {
+			hint := "Resolve the conflicts and stage the files with git_add, then commit with git_commit. Revisions after the conflicted one have not been applied."
+			return toolResult(request, formatConflicts(conflictErr, hint), conflictResult(repoPath, conflictErr, hint)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to cherry-pick: %v", err)), nil
+	}
+
+	return toolResult(request, result, OperationResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitDiffHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitRevertHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	target, ok := request.Params.Arguments["target"].(string)
-	if !ok {
-		return mcp.NewToolResultError("target must be a string"), nil
+	revisions, err := getStringListArgument(request, "revisions")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if len(revisions) == 0 {
+		return mcp.NewToolResultError("revisions must contain at least one revision"), nil
+	}
+
+	noCommit := getBoolArgument(request, "no_commit")
 
-	diff, err := s.gitOps.GetDiff(repoPath, target)
+	result, err := s.gitOps.Revert(ctx, repoPath, revisions, noCommit)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get diff: %v", err)), nil
+		var conflictErr *gitops.ConflictError
+		if errors.As(err, &conflictErr) {
+			hint := "Resolve the conflicts and stage the files with git_add, then commit with git_commit. Revisions after the conflicted one have not been reverted."
+			return toolResult(request, formatConflicts(conflictErr, hint), conflictResult(repoPath, conflictErr, hint)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to revert: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Diff with %s for %s:\n%s", target, repoPath, diff)), nil
+	return toolResult(request, result, OperationResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitCommitHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitApplyHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	message, ok := request.Params.Arguments["message"].(string)
-	if !ok {
-		return mcp.NewToolResultError("message must be a string"), nil
+	patch := getStringArgument(request, "patch")
+	if err := validatePatchPaths(repoPath, patch); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Patch path error: %v", err)), nil
 	}
 
-	result, err := s.gitOps.CommitChanges(repoPath, message)
+	opts := gitops.ApplyOptions{
+		Check:    getBoolArgument(request, "check"),
+		ThreeWay: getBoolArgument(request, "three_way"),
+		Cached:   getBoolArgument(request, "cached"),
+	}
+	result, err := s.gitOps.ApplyPatch(ctx, repoPath, patch, opts)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to commit: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to apply patch: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return toolResult(request, formatApplyResult(repoPath, result, opts.Check), PatchResult{RepoPath: repoPath, ApplyResult: *result}), nil
 }
 
-func (s *GitServer) gitAddHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitAmHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	filesStr, ok := request.Params.Arguments["files"].(string)
-	if !ok {
-		return mcp.NewToolResultError("files must be a string"), nil
+	mailbox := getStringArgument(request, "mailbox")
+	if err := validatePatchPaths(repoPath, mailbox); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Patch path error: %v", err)), nil
+	}
+
+	result, err := s.gitOps.ApplyMailbox(ctx, repoPath, mailbox, gitops.AmOptions{
+		ThreeWay:  getBoolArgument(request, "three_way"),
+		Committer: s.commitAuthor,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to apply patches: %v", err)), nil
 	}
 
-	// Split the comma-separated list of files
-	files := strings.Split(filesStr, ",")
-	// Trim spaces from each file path
-	for i, file := range files {
-		files[i] = strings.TrimSpace(file)
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitTagListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	result, err := s.gitOps.AddFiles(repoPath, files)
+	pattern := getStringArgument(request, "pattern")
+	sortBy := gitops.TagSort(getStringArgument(request, "sort"))
+
+	tags, err := s.gitOps.ListTags(ctx, repoPath, pattern, sortBy)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to add files: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list tags: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return toolResult(request, formatTags(repoPath, tags), TagListResult{RepoPath: repoPath, Tags: nonNil(tags)}), nil
 }
 
-func (s *GitServer) gitResetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitTagCreateHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	result, err := s.gitOps.ResetStaged(repoPath)
+	name, ok := request.Params.Arguments["name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("name must be a string"), nil
+	}
+
+	revision := getStringArgument(request, "revision")
+	message := getStringArgument(request, "message")
+
+	result, err := s.gitOps.CreateTag(ctx, repoPath, name, revision, message)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to reset: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create tag: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitLogHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitTagDeleteHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	maxCount := 10
-	if maxCountInterface, ok := request.Params.Arguments["max_count"]; ok {
-		if maxCountFloat, ok := maxCountInterface.(float64); ok {
-			maxCount = int(maxCountFloat)
-		}
+	name, ok := request.Params.Arguments["name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("name must be a string"), nil
 	}
 
-	logs, err := s.gitOps.GetLog(repoPath, maxCount)
+	result, err := s.gitOps.DeleteTag(ctx, repoPath, name)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get log: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete tag: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Commit history for %s:\n%s", repoPath, strings.Join(logs, "\n"))), nil
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitCreateBranchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitPushTagsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Check if write access is enabled
+	if !s.writeAccess {
+		return mcp.NewToolResultError("Write access is disabled. Use --write-access flag to enable remote operations."), nil
+	}
+
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	remote := getStringArgument(request, "remote")
+	tags, err := getStringListArgument(request, "tags")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := s.gitOps.PushTags(ctx, repoPath, remote, tags)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to push tags: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitBranchListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	includeRemote := getBoolArgument(request, "include_remote")
+
+	branches, err := s.gitOps.ListBranches(ctx, repoPath, includeRemote)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list branches: %v", err)), nil
+	}
+
+	return toolResult(request, formatBranches(repoPath, branches), BranchListResult{RepoPath: repoPath, Branches: nonNil(branches)}), nil
+}
+
+func (s *GitServer) gitBranchDeleteHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
@@ -528,137 +2306,313 @@ func (s *GitServer) gitCreateBranchHandler(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError("branch_name must be a string"), nil
 	}
 
-	baseBranch := ""
-	if baseBranchInterface, ok := request.Params.Arguments["base_branch"]; ok {
-		if baseBranchStr, ok := baseBranchInterface.(string); ok {
-			baseBranch = baseBranchStr
-		}
+	force := getBoolArgument(request, "force")
+
+	result, err := s.gitOps.DeleteBranch(ctx, repoPath, branchName, force)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete branch: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitBranchRenameHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	oldName, ok := request.Params.Arguments["old_name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("old_name must be a string"), nil
+	}
+
+	newName, ok := request.Params.Arguments["new_name"].(string)
+	if !ok {
+		return mcp.NewToolResultError("new_name must be a string"), nil
 	}
 
-	result, err := s.gitOps.CreateBranch(repoPath, branchName, baseBranch)
+	result, err := s.gitOps.RenameBranch(ctx, repoPath, oldName, newName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create branch: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to rename branch: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitCheckoutHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitBranchSetUpstreamHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	branchName, ok := request.Params.Arguments["branch_name"].(string)
+	upstream, ok := request.Params.Arguments["upstream"].(string)
 	if !ok {
-		return mcp.NewToolResultError("branch_name must be a string"), nil
+		return mcp.NewToolResultError("upstream must be a string"), nil
 	}
 
-	result, err := s.gitOps.CheckoutBranch(repoPath, branchName)
+	branchName := getStringArgument(request, "branch_name")
+
+	result, err := s.gitOps.SetUpstream(ctx, repoPath, branchName, upstream)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to checkout branch: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to set upstream: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
 }
 
-func (s *GitServer) gitShowHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitBlameHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	revision, ok := request.Params.Arguments["revision"].(string)
+	filePath, ok := request.Params.Arguments["file_path"].(string)
 	if !ok {
-		return mcp.NewToolResultError("revision must be a string"), nil
+		return mcp.NewToolResultError("file_path must be a string"), nil
 	}
 
-	result, err := s.gitOps.ShowCommit(repoPath, revision)
+	revision := getStringArgument(request, "revision")
+	startLine := getIntArgument(request, "start_line", 0)
+	endLine := getIntArgument(request, "end_line", 0)
+
+	lines, err := s.gitOps.Blame(ctx, repoPath, filePath, revision, startLine, endLine)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to show commit: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to blame file: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	if revision == "" {
+		revision = "HEAD"
+	}
+	return toolResult(request, formatBlame(filePath, revision, lines), BlameResult{RepoPath: repoPath, FilePath: filePath, Revision: revision, Lines: nonNil(lines)}), nil
 }
 
-func (s *GitServer) gitInitHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *GitServer) gitReadFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
-	// For init, we don't validate through getRepoPathForOperation since we're creating a new repo
-	if requestedPath == "" {
-		return mcp.NewToolResultError("repo_path must be specified for initialization"), nil
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	// Ensure the path is absolute
-	absPath, err := filepath.Abs(requestedPath)
+	filePath, ok := request.Params.Arguments["file_path"].(string)
+	if !ok {
+		return mcp.NewToolResultError("file_path must be a string"), nil
+	}
+
+	revision := getStringArgument(request, "revision")
+	startLine := getIntArgument(request, "start_line", 0)
+	endLine := getIntArgument(request, "end_line", 0)
+
+	file, err := s.gitOps.ReadFileAtRevision(ctx, repoPath, revision, filePath, startLine, endLine)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get absolute path: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read file: %v", err)), nil
+	}
+
+	if revision == "" {
+		revision = "HEAD"
 	}
+	return toolResult(request, formatFileContent(file, revision), ReadFileResult{RepoPath: repoPath, FileContent: *file}), nil
+}
+
+func (s *GitServer) gitLsTreeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
 
-	result, err := s.gitOps.InitRepo(absPath)
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize repository: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	// Add the new repository to our list of managed repositories
-	s.repoPaths = append(s.repoPaths, absPath)
+	revision := getStringArgument(request, "revision")
+	pathPrefix := getStringArgument(request, "path")
+	recursive := getBoolArgument(request, "recursive")
+	pattern := getStringArgument(request, "pattern")
+	long := getBoolArgument(request, "long")
+
+	entries, err := s.gitOps.ListTree(ctx, repoPath, revision, pathPrefix, recursive, pattern)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list tree: %v", err)), nil
+	}
 
-	return mcp.NewToolResultText(result), nil
+	if revision == "" {
+		revision = "HEAD"
+	}
+	location := revision
+	if pathPrefix = gitops.NormalizeRevisionPath(pathPrefix); pathPrefix != "" {
+		location += ":" + pathPrefix
+	}
+	return toolResult(request, formatTree(location, entries, long), TreeResult{RepoPath: repoPath, Revision: revision, Path: pathPrefix, Entries: nonNil(entries)}), nil
 }
 
-func (s *GitServer) gitPushHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Check if write access is enabled
-	if !s.writeAccess {
-		return mcp.NewToolResultError("Write access is disabled. Use --write-access flag to enable remote operations."), nil
+func (s *GitServer) gitLsFilesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	pattern := getStringArgument(request, "pattern")
+	includeUntracked := getBoolArgument(request, "include_untracked")
+	includeIgnored := getBoolArgument(request, "include_ignored")
+
+	files, err := s.gitOps.ListFiles(ctx, repoPath, pattern, includeUntracked, includeIgnored)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list files: %v", err)), nil
 	}
 
+	return toolResult(request, formatFiles(repoPath, files), FilesResult{RepoPath: repoPath, Files: nonNil(files)}), nil
+}
+
+func (s *GitServer) gitGrepHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
-	
+
 	repoPath, err := s.getRepoPathForOperation(requestedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
 	}
 
-	remote := ""
-	if remoteInterface, ok := request.Params.Arguments["remote"]; ok {
-		if remoteStr, ok := remoteInterface.(string); ok {
-			remote = remoteStr
-		}
+	pattern, ok := request.Params.Arguments["pattern"].(string)
+	if !ok || pattern == "" {
+		return mcp.NewToolResultError("pattern must be a non-empty string"), nil
+	}
+	pathspecs, err := getStringListArgument(request, "pathspecs")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	branch := ""
-	if branchInterface, ok := request.Params.Arguments["branch"]; ok {
-		if branchStr, ok := branchInterface.(string); ok {
-			branch = branchStr
+	opts := gitops.GrepOptions{
+		Pattern:      pattern,
+		FixedStrings: getBoolArgument(request, "fixed_strings"),
+		IgnoreCase:   getBoolArgument(request, "ignore_case"),
+		Pathspecs:    pathspecs,
+		Revision:     getStringArgument(request, "revision"),
+		ContextLines: getIntArgument(request, "context_lines", 0),
+		MaxResults:   getIntArgument(request, "max_results", 100),
+	}
+
+	grepResult, err := s.gitOps.Grep(ctx, repoPath, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to search: %v", err)), nil
+	}
+
+	location := "working tree"
+	if opts.Revision != "" {
+		location = opts.Revision
+	}
+	data := SearchResult{
+		RepoPath:  repoPath,
+		Pattern:   pattern,
+		Revision:  opts.Revision,
+		Matches:   nonNil(grepResult.Matches),
+		Truncated: grepResult.Truncated,
+	}
+
+	return toolResult(request, formatGrep(opts, location, grepResult), data), nil
+}
+
+func (s *GitServer) gitFetchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	remote := getStringArgument(request, "remote")
+	refspec := getStringArgument(request, "refspec")
+	prune := getBoolArgument(request, "prune")
+	tags := getBoolArgument(request, "tags")
+
+	result, err := s.gitOps.Fetch(ctx, repoPath, remote, refspec, prune, tags)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to fetch: %v", err)), nil
+	}
+
+	return toolResult(request, result, MessageResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitPullHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedPath, _ := request.Params.Arguments["repo_path"].(string)
+
+	repoPath, err := s.getRepoPathForOperation(requestedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	remote := getStringArgument(request, "remote")
+	branch := getStringArgument(request, "branch")
+	strategy := gitops.PullStrategy(getStringArgument(request, "strategy"))
+	ffOnly := getBoolArgument(request, "ff_only")
+
+	result, err := s.gitOps.Pull(ctx, repoPath, remote, branch, strategy, ffOnly)
+	if err != nil {
+		var conflictErr *gitops.ConflictError
+		if errors.As(err, &conflictErr) {
+			hint := "Resolve the conflicts, stage the files with git_add and commit with git_commit, or use git_merge_abort to back out."
+			if strategy == gitops.PullStrategyRebase {
+				hint = "Resolve the conflicts, stage the files with git_add and use git_rebase_continue, or use git_rebase_abort to back out."
+			}
+			return toolResult(request, formatConflicts(conflictErr, hint), conflictResult(repoPath, conflictErr, hint)), nil
 		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to pull: %v", err)), nil
+	}
+
+	return toolResult(request, result, OperationResult{RepoPath: repoPath, Message: result}), nil
+}
+
+func (s *GitServer) gitCloneHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Check if cloning is enabled
+	if len(s.cloneDirs) == 0 {
+		return mcp.NewToolResultError("Cloning is disabled. Use --clone-dir flag to allow cloning into a directory."), nil
 	}
 
-	result, err := s.gitOps.PushChanges(repoPath, remote, branch)
+	url, ok := request.Params.Arguments["url"].(string)
+	if !ok {
+		return mcp.NewToolResultError("url must be a string"), nil
+	}
+
+	targetPath, ok := request.Params.Arguments["target_path"].(string)
+	if !ok {
+		return mcp.NewToolResultError("target_path must be a string"), nil
+	}
+
+	// For clone, we don't validate through getRepoPathForOperation since we're creating a new repo
+	absPath, err := filepath.Abs(targetPath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to push changes: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get absolute path: %v", err)), nil
+	}
+	if err := s.validateCloneTarget(absPath); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository path error: %v", err)), nil
+	}
+
+	if err := s.validateCloneURL(url); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Clone URL error: %v", err)), nil
+	}
+
+	branch := getStringArgument(request, "branch")
+	depth := getIntArgument(request, "depth", 0)
+	filter := getStringArgument(request, "filter")
+
+	result, err := s.gitOps.CloneRepo(ctx, url, absPath, branch, depth, filter)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to clone repository: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	// Add the new repository to our list of managed repositories
+	s.addRepository(absPath)
+
+	return toolResult(request, result, MessageResult{RepoPath: absPath, Message: result}), nil
 }
 
 // gitListRepositoriesHandler lists all available repositories
 func (s *GitServer) gitListRepositoriesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if len(s.repoPaths) == 0 {
-		return mcp.NewToolResultText("No repositories configured"), nil
-	}
-	
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Available repositories (%d):\n\n", len(s.repoPaths)))
-	
-	for i, repoPath := range s.repoPaths {
-		// Get the repository name (last part of the path)
-		repoName := filepath.Base(repoPath)
-		result.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, repoName, repoPath))
-	}
-	
-	return mcp.NewToolResultText(result.String()), nil
+	repoPaths := s.repositories()
+	return toolResult(request, formatRepositories(repoPaths), RepositoriesResult{Repositories: nonNil(repoPaths)}), nil
 }
@@ -2,12 +2,15 @@ package pkg
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/geropl/git-mcp-go/pkg/gitops"
 	"github.com/geropl/git-mcp-go/pkg/gitops/gogit"
@@ -51,7 +54,19 @@ func createCommit(t *testing.T, repoDir, filename, content, message string) {
 	require.NoError(t, cmd.Run())
 }
 
+// runGit runs a git command in repoDir and returns its output
+func runGit(t *testing.T, repoDir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), output)
+	return string(output)
+}
+
 func TestGitOperations(t *testing.T) {
+	// Local repository of the fetch_prune case, to check its refs after fetching
+	var pruneRepo string
+
 	// Test cases table
 	testCases := []struct {
 		name           string
@@ -186,6 +201,644 @@ func TestGitOperations(t *testing.T) {
 				require.Contains(t, result, "up-to-date")
 			},
 		},
+		{
+			name: "stash_list",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+
+				// Modify the file and stash the change
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "test.txt"), []byte("changed content"), 0644))
+				cmd := exec.Command("git", "stash", "push", "-m", "work in progress")
+				cmd.Dir = localRepo
+				require.NoError(t, cmd.Run())
+			},
+			action: "git_stash_list",
+			params: map[string]interface{}{},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Stash entries")
+				require.Contains(t, result, "stash@{0}")
+				require.Contains(t, result, "work in progress")
+			},
+		},
+
+		{
+			name: "merge_fast_forward_only",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "feature.txt", "feature content", "Feature commit")
+				runGit(t, localRepo, "checkout", "-")
+			},
+			action: "git_merge",
+			params: map[string]interface{}{
+				"branch": "feature",
+				"mode":   "ff-only",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Fast-forward")
+			},
+		},
+		{
+			name: "merge_conflict",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "line 1\nline 2\nline 3\n", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "test.txt", "line 1\nfeature line\nline 3\n", "Feature commit")
+				runGit(t, localRepo, "checkout", "-")
+				createCommit(t, localRepo, "test.txt", "line 1\nmain line\nline 3\n", "Main commit")
+			},
+			action: "git_merge",
+			params: map[string]interface{}{
+				"branch": "feature",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Merge stopped with conflicts in 1 file(s)")
+				require.Contains(t, result, "test.txt (1 hunk(s))")
+				require.Contains(t, result, "Hunk 1 (lines 2-6)")
+				require.Contains(t, result, "main line")
+				require.Contains(t, result, "feature line")
+			},
+		},
+		{
+			name: "rebase_conflict",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "line 1\nline 2\nline 3\n", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "base")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "test.txt", "line 1\nfeature line\nline 3\n", "Feature commit")
+				createCommit(t, localRepo, "other.txt", "other content", "Second feature commit")
+				runGit(t, localRepo, "checkout", "base")
+				createCommit(t, localRepo, "test.txt", "line 1\nbase line\nline 3\n", "Base commit")
+				runGit(t, localRepo, "checkout", "feature")
+			},
+			action: "git_rebase",
+			params: map[string]interface{}{
+				"upstream": "base",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Rebase in progress")
+				require.Contains(t, result, "Branch: feature")
+				require.Contains(t, result, "Feature commit")
+				require.Contains(t, result, "Progress: 1 done, 1 remaining")
+				require.Contains(t, result, "Conflicted files (1):\n  test.txt")
+			},
+		},
+		{
+			name: "cherry_pick_multiple",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "base")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "fix1.txt", "fix 1", "First fix")
+				createCommit(t, localRepo, "fix2.txt", "fix 2", "Second fix")
+				runGit(t, localRepo, "checkout", "base")
+			},
+			action: "git_cherry_pick",
+			params: map[string]interface{}{
+				"revisions":     []interface{}{"feature~1", "feature"},
+				"record_origin": true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "First fix")
+				require.Contains(t, result, "Second fix")
+			},
+		},
+		{
+			name: "revert_conflict",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "version 1\n", "Initial commit")
+				createCommit(t, localRepo, "test.txt", "version 2\n", "Second version")
+				createCommit(t, localRepo, "test.txt", "version 3\n", "Third version")
+			},
+			action: "git_revert",
+			params: map[string]interface{}{
+				"revisions": "HEAD~1",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Revert stopped with conflicts in 1 file(s)")
+				require.Contains(t, result, "test.txt (1 hunk(s))")
+				require.Contains(t, result, "version 3")
+			},
+		},
+		{
+			name: "tag_list_version_sort",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "tag", "v1.10.0")
+				runGit(t, localRepo, "tag", "v1.9.0")
+				runGit(t, localRepo, "tag", "-a", "v1.2.0", "-m", "Release 1.2.0")
+				runGit(t, localRepo, "tag", "other")
+			},
+			action: "git_tag_list",
+			params: map[string]interface{}{
+				"pattern": "v1.*",
+				"sort":    "version",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Tags for")
+				require.Contains(t, result, "(3):")
+				require.NotContains(t, result, "other")
+				require.Contains(t, result, "annotated by Test User <test@example.com>")
+				require.Contains(t, result, "Release 1.2.0")
+
+				v12 := strings.Index(result, "v1.2.0")
+				v19 := strings.Index(result, "v1.9.0")
+				v110 := strings.Index(result, "v1.10.0")
+				require.True(t, v12 < v19 && v19 < v110, "tags should be sorted by version: %s", result)
+			},
+		},
+		{
+			name: "push_tags",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "tag", "-a", "v1.0.0", "-m", "Release 1.0.0")
+				runGit(t, localRepo, "tag", "unpushed")
+			},
+			action: "git_push_tags",
+			params: map[string]interface{}{
+				"tags": "v1.0.0",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Successfully pushed tags")
+
+				// Verify only the requested tag exists in the remote repository
+				output := runGit(t, remoteDir, "tag", "--list")
+				require.Contains(t, output, "v1.0.0")
+				require.NotContains(t, output, "unpushed")
+			},
+		},
+		{
+			name: "branch_list_with_upstream",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "push", "-u", "origin", "HEAD")
+				createCommit(t, localRepo, "local.txt", "local content", "Local commit")
+				runGit(t, localRepo, "branch", "feature")
+			},
+			action: "git_branch_list",
+			params: map[string]interface{}{
+				"include_remote": true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Branches for")
+				require.Contains(t, result, "(3):")
+				require.Contains(t, result, ": ahead 1, behind 0]")
+				require.Contains(t, result, "  feature ")
+				require.Contains(t, result, "  remotes/origin/")
+				require.Contains(t, result, "Local commit")
+			},
+		},
+		{
+			name: "branch_delete_unmerged",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "feature.txt", "feature content", "Feature commit")
+				runGit(t, localRepo, "checkout", "-")
+			},
+			action: "git_branch_delete",
+			params: map[string]interface{}{
+				"branch_name": "feature",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Failed to delete branch")
+				require.Contains(t, result, "not fully merged")
+			},
+		},
+		{
+			name: "fetch_prune",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				runGit(t, localRepo, "push", "origin", "HEAD", "HEAD:refs/heads/feature")
+				runGit(t, remoteRepo, "branch", "-D", "feature")
+				pruneRepo = localRepo
+			},
+			action: "git_fetch",
+			params: map[string]interface{}{
+				"prune": true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.NotContains(t, result, "Failed to fetch")
+				require.NotContains(t, runGit(t, pruneRepo, "branch", "--remotes"), "origin/feature")
+			},
+		},
+		{
+			name: "pull_fast_forward_only",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "test content", "Initial commit")
+				createCommit(t, localRepo, "remote.txt", "remote content", "Remote commit")
+				runGit(t, localRepo, "push", "-u", "origin", "HEAD")
+				// Rewind the local branch so the pushed commit only exists on the remote
+				runGit(t, localRepo, "reset", "--hard", "HEAD~1")
+				runGit(t, localRepo, "update-ref", "refs/remotes/origin/"+strings.TrimSpace(runGit(t, localRepo, "branch", "--show-current")), "HEAD")
+			},
+			action: "git_pull",
+			params: map[string]interface{}{
+				"ff_only": true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Fast-forward")
+			},
+		},
+		{
+			name: "pull_rebase_conflict",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "base\n", "Initial commit")
+				createCommit(t, localRepo, "test.txt", "remote\n", "Remote commit")
+				runGit(t, localRepo, "push", "-u", "origin", "HEAD")
+				runGit(t, localRepo, "reset", "--hard", "HEAD~1")
+				createCommit(t, localRepo, "test.txt", "local\n", "Local commit")
+			},
+			action: "git_pull",
+			params: map[string]interface{}{
+				"strategy": "rebase",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Pull stopped with conflicts")
+				require.Contains(t, result, "test.txt")
+				require.Contains(t, result, "git_rebase_continue")
+			},
+		},
+		{
+			name: "blame_line_range",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "one\ntwo\nthree\n", "Initial commit")
+				createCommit(t, localRepo, "test.txt", "one\n2\nthree\n", "Change second line")
+			},
+			action: "git_blame",
+			params: map[string]interface{}{
+				"file_path":  "test.txt",
+				"start_line": float64(2),
+				"end_line":   float64(3),
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Blame for test.txt at HEAD (2 lines):")
+				require.Contains(t, result, ") 2\n")
+				require.Contains(t, result, ") three\n")
+				require.NotContains(t, result, ") one\n")
+				require.Contains(t, result, "Test User <test@example.com>: Change second line")
+				require.Contains(t, result, "Test User <test@example.com>: Initial commit")
+			},
+		},
+		{
+			name: "read_file_at_tag",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "one\ntwo\nthree\n", "Initial commit")
+				runGit(t, localRepo, "tag", "v1.0")
+				createCommit(t, localRepo, "test.txt", "changed\n", "Change file")
+			},
+			action: "git_read_file",
+			params: map[string]interface{}{
+				"file_path":  "test.txt",
+				"revision":   "v1.0",
+				"start_line": float64(2),
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "File test.txt at v1.0")
+				require.Contains(t, result, "14 bytes, lines 2-3 of 3\ntwo\nthree\n")
+				require.NotContains(t, result, "changed")
+			},
+		},
+		{
+			name: "read_file_binary",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "image.bin", "GIF89a\x00\x01\x02", "Add binary file")
+			},
+			action: "git_read_file",
+			params: map[string]interface{}{
+				"file_path": "image.bin",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Binary file image.bin at HEAD")
+				require.Contains(t, result, "9 bytes")
+				require.NotContains(t, result, "GIF89a")
+			},
+		},
+		{
+			name: "ls_tree_recursive_pattern",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				require.NoError(t, os.MkdirAll(filepath.Join(localRepo, "src", "util"), 0755))
+				createCommit(t, localRepo, "README.md", "readme", "Add readme")
+				createCommit(t, localRepo, "src/main.go", "package main", "Add main")
+				createCommit(t, localRepo, "src/util/util.go", "package util", "Add util")
+				createCommit(t, localRepo, "src/util/notes.txt", "notes", "Add notes")
+			},
+			action: "git_ls_tree",
+			params: map[string]interface{}{
+				"path":      "src",
+				"recursive": true,
+				"pattern":   "*.go",
+				"long":      true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Tree HEAD:src (2 entries):")
+				require.Contains(t, result, "100644 blob ")
+				require.Contains(t, result, "      12 src/main.go\n")
+				require.Contains(t, result, "src/util/util.go")
+				require.NotContains(t, result, "notes.txt")
+				require.NotContains(t, result, "README.md")
+			},
+		},
+		{
+			name: "ls_files_untracked_ignored",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, ".gitignore", "build/\n", "Add gitignore")
+				require.NoError(t, os.MkdirAll(filepath.Join(localRepo, "build"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "build", "out.bin"), []byte("out"), 0644))
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "new.txt"), []byte("new"), 0644))
+			},
+			action: "git_ls_files",
+			params: map[string]interface{}{
+				"include_untracked": true,
+				"include_ignored":   true,
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "(3):\n.gitignore\nnew.txt (untracked)\nbuild/ (ignored)\n")
+			},
+		},
+		{
+			name: "grep_revision_context",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "one\nTODO first\ntwo\nthree\nfour\nTODO second\nfive\n", "Add a")
+				createCommit(t, localRepo, "b.txt", "TODO third\n", "Add b")
+				runGit(t, localRepo, "tag", "v1.0")
+				createCommit(t, localRepo, "a.txt", "nothing left\n", "Clean up a")
+			},
+			action: "git_grep",
+			params: map[string]interface{}{
+				"pattern":       "todo",
+				"ignore_case":   true,
+				"revision":      "v1.0",
+				"context_lines": float64(1),
+				"max_results":   float64(2),
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Matches for \"todo\" in v1.0 (2):\n"+
+					"a.txt-1-one\na.txt:2:TODO first\na.txt-3-two\n--\n"+
+					"a.txt-5-four\na.txt:6:TODO second\na.txt-7-five\n")
+				require.Contains(t, result, "Results truncated after 2 matches")
+				require.NotContains(t, result, "b.txt")
+			},
+		},
+		{
+			name: "grep_working_tree_pathspec",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				require.NoError(t, os.MkdirAll(filepath.Join(localRepo, "src"), 0755))
+				createCommit(t, localRepo, "src/main.go", "func main() {}\n", "Add main")
+				createCommit(t, localRepo, "notes.txt", "func main() {}\n", "Add notes")
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "src", "main.go"), []byte("func main() { run() }\n"), 0644))
+			},
+			action: "git_grep",
+			params: map[string]interface{}{
+				"pattern":       "main() {",
+				"fixed_strings": true,
+				"pathspecs":     []interface{}{"src/"},
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "in working tree (1):\nsrc/main.go:1:func main() { run() }\n")
+				require.NotContains(t, result, "notes.txt")
+			},
+		},
+		{
+			name: "grep_pathspec_with_comma",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a,b.txt", "match\n", "Add a,b")
+				createCommit(t, localRepo, "a", "match\n", "Add a")
+			},
+			action: "git_grep",
+			params: map[string]interface{}{
+				"pattern":   "match",
+				"pathspecs": []interface{}{"a,b.txt"},
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "in working tree (1):\na,b.txt:1:match\n")
+			},
+		},
+		{
+			name: "grep_pathspec_not_a_string",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "match\n", "Add a")
+			},
+			action: "git_grep",
+			params: map[string]interface{}{
+				"pattern":   "match",
+				"pathspecs": []interface{}{"a.txt", float64(1)},
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "pathspecs must be an array of strings")
+			},
+		},
+		{
+			name: "log_author_path_range",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "a", "Initial commit")
+				runGit(t, localRepo, "tag", "v1.0")
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "a.txt"), []byte("a2"), 0644))
+				runGit(t, localRepo, "commit", "-am", "Alice changes a", "--author=Alice <alice@example.com>")
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "b.txt"), []byte("b"), 0644))
+				runGit(t, localRepo, "add", "b.txt")
+				runGit(t, localRepo, "commit", "-m", "Alice adds b", "--author=Alice <alice@example.com>")
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "a.txt"), []byte("a3"), 0644))
+				runGit(t, localRepo, "commit", "-am", "Bob changes a", "--author=Bob <bob@example.com>")
+			},
+			action: "git_log",
+			params: map[string]interface{}{
+				"revision": "v1.0..HEAD",
+				"author":   "^Alice",
+				"paths":    []interface{}{"a.txt"},
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Alice changes a")
+				require.NotContains(t, result, "Alice adds b")
+				require.NotContains(t, result, "Bob changes a")
+				require.NotContains(t, result, "Initial commit")
+			},
+		},
+		{
+			name: "log_pickaxe",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "main.go", "package main\n", "Initial commit")
+				createCommit(t, localRepo, "main.go", "package main\n\nfunc parseConfig() {}\n", "Add config parsing")
+				createCommit(t, localRepo, "other.go", "package main\n", "Add other file")
+			},
+			action: "git_log",
+			params: map[string]interface{}{
+				"pickaxe": "func parseConfig",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "Add config parsing")
+				require.NotContains(t, result, "Initial commit")
+				require.NotContains(t, result, "Add other file")
+			},
+		},		{
+			name: "status_json",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "tracked.txt", "content", "Initial commit")
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "tracked.txt"), []byte("changed"), 0644))
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "staged.txt"), []byte("new"), 0644))
+				runGit(t, localRepo, "add", "staged.txt")
+				require.NoError(t, os.MkdirAll(filepath.Join(localRepo, "dir"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(localRepo, "dir", "untracked.txt"), []byte("new"), 0644))
+			},
+			action: "git_status",
+			params: map[string]interface{}{
+				"output_format": "json",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+
+				var output struct {
+					Schema string       `json:"schema"`
+					Tool   string       `json:"tool"`
+					Result StatusResult `json:"result"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(result), &output), result)
+				require.Equal(t, "git-mcp://schemas/git_status/v1", output.Schema)
+				require.Equal(t, "git_status", output.Tool)
+				require.False(t, output.Result.Clean)
+				require.Equal(t, []gitops.FileStatus{
+					{Path: "dir/untracked.txt", Index: "?", Worktree: "?"},
+					{Path: "staged.txt", Index: "A", Worktree: " "},
+					{Path: "tracked.txt", Index: " ", Worktree: "M"},
+				}, output.Result.Files)
+			},
+		},
+		{
+			name: "merge_conflict_json",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "test.txt", "line 1\nline 2\nline 3\n", "Initial commit")
+				runGit(t, localRepo, "checkout", "-b", "feature")
+				createCommit(t, localRepo, "test.txt", "line 1\nfeature line\nline 3\n", "Feature commit")
+				runGit(t, localRepo, "checkout", "-")
+				createCommit(t, localRepo, "test.txt", "line 1\nmain line\nline 3\n", "Main commit")
+			},
+			action: "git_merge",
+			params: map[string]interface{}{
+				"branch":        "feature",
+				"output_format": "json",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+
+				var output struct {
+					Result OperationResult `json:"result"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(result), &output), result)
+				require.Len(t, output.Result.Conflicts, 1)
+				conflict := output.Result.Conflicts[0]
+				require.Equal(t, "test.txt", conflict.Path)
+				require.Len(t, conflict.Hunks, 1)
+				require.Equal(t, "main line", conflict.Hunks[0].Ours)
+				require.Equal(t, "feature line", conflict.Hunks[0].Theirs)
+				require.Contains(t, output.Result.Hint, "git_merge_abort")
+			},
+		},
+		{
+			name: "apply_rejected_json",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "one\n", "Add a")
+				createCommit(t, localRepo, "b.txt", "changed\n", "Add b")
+			},
+			action: "git_apply",
+			params: map[string]interface{}{
+				"patch":         "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n+two\ndiff --git a/b.txt b/b.txt\n--- a/b.txt\n+++ b/b.txt\n@@ -1 +1 @@\n-bee\n+buzz\n",
+				"output_format": "json",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+
+				var output struct {
+					Result PatchResult `json:"result"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(result), &output), result)
+				require.False(t, output.Result.Applied)
+				require.Equal(t, []gitops.ApplyFile{
+					{PatchedFile: gitops.PatchedFile{Path: "a.txt"}, Status: gitops.ApplyClean},
+					{PatchedFile: gitops.PatchedFile{Path: "b.txt"}, Status: gitops.ApplyRejected, Error: "patch does not apply"},
+				}, output.Result.Files)
+			},
+		},
+		{
+			name: "apply_path_outside_repository",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "one\n", "Add a")
+			},
+			action: "git_apply",
+			params: map[string]interface{}{
+				"patch": "--- a/a.txt\n+++ b/../outside.txt\n@@ -1 +1 @@\n-one\n+two\n",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "access denied - path outside repository: ../outside.txt")
+			},
+		},
+		{
+			name: "am_git_directory",
+			setupFunc: func(t *testing.T, remoteRepo, localRepo string) {
+				initRepos(t, remoteRepo, localRepo)
+				createCommit(t, localRepo, "a.txt", "one\n", "Add a")
+			},
+			action: "git_am",
+			params: map[string]interface{}{
+				"mailbox": "From: Test <test@example.com>\nSubject: [PATCH] Hooks\n\n---\ndiff --git a/.git/hooks/post-commit b/.git/hooks/post-commit\nnew file mode 100755\n--- /dev/null\n+++ b/.git/hooks/post-commit\n@@ -0,0 +1 @@\n+true\n",
+			},
+			expectedResult: func(t *testing.T, result string, remoteDir string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, result, "access denied - path inside the git directory: .git/hooks/post-commit")
+			},
+		},
 	}
 
 	// Run each test case in both modes
@@ -227,11 +880,111 @@ func TestGitOperations(t *testing.T) {
 				}
 
 				switch tc.action {
+				case "git_status":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_status"
+					request.Params.Arguments = params
+					result, err = server.gitStatusHandler(context.Background(), request)
 				case "git_push":
 					request := mcp.CallToolRequest{}
 					request.Params.Name = "git_push"
 					request.Params.Arguments = params
 					result, err = server.gitPushHandler(context.Background(), request)
+				case "git_stash_list":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_stash_list"
+					request.Params.Arguments = params
+					result, err = server.gitStashListHandler(context.Background(), request)
+				case "git_merge":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_merge"
+					request.Params.Arguments = params
+					result, err = server.gitMergeHandler(context.Background(), request)
+				case "git_rebase":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_rebase"
+					request.Params.Arguments = params
+					result, err = server.gitRebaseHandler(context.Background(), request)
+				case "git_cherry_pick":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_cherry_pick"
+					request.Params.Arguments = params
+					result, err = server.gitCherryPickHandler(context.Background(), request)
+				case "git_revert":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_revert"
+					request.Params.Arguments = params
+					result, err = server.gitRevertHandler(context.Background(), request)
+				case "git_apply":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_apply"
+					request.Params.Arguments = params
+					result, err = server.gitApplyHandler(context.Background(), request)
+				case "git_am":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_am"
+					request.Params.Arguments = params
+					result, err = server.gitAmHandler(context.Background(), request)
+				case "git_tag_list":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_tag_list"
+					request.Params.Arguments = params
+					result, err = server.gitTagListHandler(context.Background(), request)
+				case "git_push_tags":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_push_tags"
+					request.Params.Arguments = params
+					result, err = server.gitPushTagsHandler(context.Background(), request)
+				case "git_branch_list":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_branch_list"
+					request.Params.Arguments = params
+					result, err = server.gitBranchListHandler(context.Background(), request)
+				case "git_branch_delete":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_branch_delete"
+					request.Params.Arguments = params
+					result, err = server.gitBranchDeleteHandler(context.Background(), request)
+				case "git_fetch":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_fetch"
+					request.Params.Arguments = params
+					result, err = server.gitFetchHandler(context.Background(), request)
+				case "git_pull":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_pull"
+					request.Params.Arguments = params
+					result, err = server.gitPullHandler(context.Background(), request)
+				case "git_blame":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_blame"
+					request.Params.Arguments = params
+					result, err = server.gitBlameHandler(context.Background(), request)
+				case "git_read_file":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_read_file"
+					request.Params.Arguments = params
+					result, err = server.gitReadFileHandler(context.Background(), request)
+				case "git_ls_tree":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_ls_tree"
+					request.Params.Arguments = params
+					result, err = server.gitLsTreeHandler(context.Background(), request)
+				case "git_ls_files":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_ls_files"
+					request.Params.Arguments = params
+					result, err = server.gitLsFilesHandler(context.Background(), request)
+				case "git_grep":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_grep"
+					request.Params.Arguments = params
+					result, err = server.gitGrepHandler(context.Background(), request)
+				case "git_log":
+					request := mcp.CallToolRequest{}
+					request.Params.Name = "git_log"
+					request.Params.Arguments = params
+					result, err = server.gitLogHandler(context.Background(), request)
 				// Add other actions as needed
 				default:
 					t.Fatalf("Unknown action: %s", tc.action)
@@ -251,3 +1004,115 @@ func TestGitOperations(t *testing.T) {
 		}
 	}
 }
+
+func TestLogPagination(t *testing.T) {
+	modes := []string{"shell", "go-git"}
+
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			remoteDir := t.TempDir()
+			localDir := t.TempDir()
+			initRepos(t, remoteDir, localDir)
+			for i := 1; i <= 5; i++ {
+				createCommit(t, localDir, "test.txt", fmt.Sprintf("content %d", i), fmt.Sprintf("Commit number %d", i))
+			}
+
+			var gitOps gitops.GitOperations
+			if mode == "shell" {
+				gitOps = shell.NewShellGitOperations()
+			} else {
+				gitOps = gogit.NewGoGitOperations()
+			}
+			server := NewGitServer([]string{localDir}, gitOps, false)
+
+			callLog := func(arguments map[string]interface{}) string {
+				request := mcp.CallToolRequest{}
+				request.Params.Name = "git_log"
+				request.Params.Arguments = arguments
+
+				result, err := server.gitLogHandler(context.Background(), request)
+				require.NoError(t, err)
+				textContent, ok := mcp.AsTextContent(result.Content[0])
+				require.True(t, ok)
+				return textContent.Text
+			}
+			cursorPattern := regexp.MustCompile(`cursor="([^"]+)"`)
+
+			// Walk the history two commits at a time
+			var messages []string
+			cursor := ""
+			for page := 0; page < 3; page++ {
+				arguments := map[string]interface{}{
+					"repo_path": localDir,
+					"page_size": float64(2),
+				}
+				if cursor != "" {
+					arguments["cursor"] = cursor
+				}
+				text := callLog(arguments)
+				messages = append(messages, regexp.MustCompile(`Commit number \d`).FindAllString(text, -1)...)
+
+				match := cursorPattern.FindStringSubmatch(text)
+				if page < 2 {
+					require.NotNil(t, match, "page %d should have a cursor: %s", page, text)
+					cursor = match[1]
+				} else {
+					require.Nil(t, match, "last page should not have a cursor: %s", text)
+				}
+			}
+			require.Equal(t, []string{
+				"Commit number 5", "Commit number 4", "Commit number 3", "Commit number 2", "Commit number 1",
+			}, messages)
+
+			// A cursor can't be used with different arguments
+			text := callLog(map[string]interface{}{
+				"repo_path": localDir,
+				"author":    "Someone",
+				"cursor":    cursor,
+			})
+			require.Contains(t, text, "cursor doesn't belong to a call with these arguments")
+		})
+	}
+}
+
+func TestToolTimeout(t *testing.T) {
+	modes := []string{"shell", "go-git"}
+
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			remoteDir := t.TempDir()
+			localDir := t.TempDir()
+			initRepos(t, remoteDir, localDir)
+			createCommit(t, localDir, "test.txt", "content", "Initial commit")
+
+			var gitOps gitops.GitOperations
+			if mode == "shell" {
+				gitOps = shell.NewShellGitOperations()
+			} else {
+				gitOps = gogit.NewGoGitOperations()
+			}
+			server := NewGitServer([]string{localDir}, gitOps, false)
+			require.Error(t, server.SetTimeouts(time.Minute, map[string]time.Duration{"git_unknown": time.Second}))
+
+			// Every call runs out of time before git gets to do anything, except for git_log
+			require.NoError(t, server.SetTimeouts(time.Nanosecond, map[string]time.Duration{"git_log": 0}))
+			server.RegisterTools()
+
+			callTool := func(name string) (bool, string) {
+				result := callServer(t, server, "tools/call", map[string]interface{}{
+					"name":      name,
+					"arguments": map[string]interface{}{"repo_path": localDir},
+				})
+				return result["isError"] == true, fmt.Sprint(result["content"])
+			}
+
+			isError, text := callTool("git_show")
+			require.True(t, isError, text)
+			require.Contains(t, text, "git_show timed out after 1ns")
+
+			isError, text = callTool("git_log")
+			require.False(t, isError, text)
+			require.Contains(t, text, "Initial commit")
+		})
+	}
+}